@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Batch normalization. MulScalar and Add leave points in Jacobian coordinates (X, Y, Z) standing for
+	(X / Z^2, Y / Z^3), and every serialization or pairing of such a point first pays a field inversion
+	to bring it to affine form. Normalizing a whole set at once takes a single inversion and three
+	multiplications per point (Montgomery's trick). go-ethereum does not export its field arithmetic,
+	so the coordinates are worked on in place with the arithmetic of fp.go, which shares their
+	representation.
+*/
+
+// fpModulus is the base field modulus p of BLS12-381
+var fpModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+
+// batchAffineG1 brings all points to affine form in place using a single field inversion
+func batchAffineG1(points []*bls.PointG1) {
+	var idx []int
+	var zs []fp
+	for i, p := range points {
+		// points at infinity and points already in affine form are left alone
+		z := *(*fp)(&p[2])
+		if !z.isZero() && z != fpOne {
+			idx = append(idx, i)
+			zs = append(zs, z)
+		}
+	}
+	if len(zs) == 0 {
+		return
+	}
+	// prefix[i] = z_0 * ... * z_i
+	prefix := make([]fp, len(zs))
+	acc := fpOne
+	for i, z := range zs {
+		acc.mul(acc, z)
+		prefix[i] = acc
+	}
+	var inv, zinv, t fp
+	inv.inverse(acc)
+	for k := len(zs) - 1; k >= 0; k-- {
+		if k > 0 {
+			zinv.mul(inv, prefix[k-1])
+			inv.mul(inv, zs[k])
+		} else {
+			zinv = inv
+		}
+		p := points[idx[k]]
+		x, y := (*fp)(&p[0]), (*fp)(&p[1])
+		// x = X / Z^2, y = Y / Z^3
+		t.mul(zinv, zinv)
+		x.mul(*x, t)
+		t.mul(t, zinv)
+		y.mul(*y, t)
+		*(*fp)(&p[2]) = fpOne
+	}
+}
+
+// g2Coordinate returns coordinate i of p, 0 for X, 1 for Y and 2 for Z
+func g2Coordinate(p *bls.PointG2, i int) fp2 {
+	return fp2{*(*fp)(&p[i][0]), *(*fp)(&p[i][1])}
+}
+
+// setG2Coordinate sets coordinate i of p to x
+func setG2Coordinate(p *bls.PointG2, i int, x fp2) {
+	*(*fp)(&p[i][0]) = x[0]
+	*(*fp)(&p[i][1]) = x[1]
+}
+
+// batchAffineG2 brings all points to affine form in place using a single field inversion
+func batchAffineG2(points []*bls.PointG2) {
+	var idx []int
+	var zs []fp2
+	for i, p := range points {
+		z := g2Coordinate(p, 2)
+		if !z.isZero() && z != (fp2{fpOne, fp{}}) {
+			idx = append(idx, i)
+			zs = append(zs, z)
+		}
+	}
+	if len(zs) == 0 {
+		return
+	}
+	prefix := make([]fp2, len(zs))
+	acc := fp2{fpOne, fp{}}
+	for i, z := range zs {
+		acc.mul(acc, z)
+		prefix[i] = acc
+	}
+	var inv, zinv, t fp2
+	inv.inverse(acc)
+	for k := len(zs) - 1; k >= 0; k-- {
+		if k > 0 {
+			zinv.mul(inv, prefix[k-1])
+			inv.mul(inv, zs[k])
+		} else {
+			zinv = inv
+		}
+		p := points[idx[k]]
+		x, y := g2Coordinate(p, 0), g2Coordinate(p, 1)
+		t.mul(zinv, zinv)
+		x.mul(x, t)
+		t.mul(t, zinv)
+		y.mul(y, t)
+		setG2Coordinate(p, 0, x)
+		setG2Coordinate(p, 1, y)
+		setG2Coordinate(p, 2, fp2{fpOne, fp{}})
+	}
+}
+
+// normalize stores every point of the parameters in affine form
+func (pp *PublicParams) normalize() {
+	// lazy powers are decoded in affine form
+	if pp.lazyPP1 == nil {
+		batchAffineG1(pp.PP1[:])
+	}
+	batchAffineG2(pp.PP2[:])
+	batchAffineG2(pp.PP2Ext)
+}
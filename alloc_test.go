@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Allocation benchmarks. BenchmarkAllocationsBefore and BenchmarkAllocationsAfter run the patterns
+	pooling and scratch values replaced side by side: a fresh group instance per encoded or decoded
+	point against one from the pools of pool.go, and a fresh point or big.Int per term against one
+	reused for the whole loop. BenchmarkAllocations reports the hot paths as they are.
+*/
+
+// allocBench is one measured operation
+type allocBench struct {
+	name string
+	run  func()
+}
+
+// allocPatterns returns the per element patterns, allocating per element unless pooled is set
+func allocPatterns(tb testing.TB, pooled bool) []allocBench {
+	f := benchSetup(tb)
+	var file bytes.Buffer
+	for _, p := range pp1 {
+		_ = writeG1(&file, p)
+	}
+	encoded := file.Bytes()
+	encode := func() {
+		for _, p := range pp1 {
+			if pooled {
+				_ = writeG1(io.Discard, p)
+			} else {
+				_, _ = io.Discard.Write(bls.NewG1().ToBytes(p))
+			}
+		}
+	}
+	decode := func() {
+		r := bytes.NewReader(encoded)
+		buf := make([]byte, g1Size)
+		for range pp1 {
+			if pooled {
+				_, _ = readG1(r)
+			} else {
+				_, _ = io.ReadFull(r, buf)
+				_, _ = bls.NewG1().FromBytes(buf)
+			}
+		}
+	}
+	proofTerms := func() {
+		g := getG1()
+		defer putG1(g)
+		proof, temp := g.Zero(), g.New()
+		for j := 0; j < 64; j++ {
+			if !pooled {
+				temp = g.New()
+			}
+			g.Add(proof, proof, g.MulScalar(temp, pp1[n+j+1], f.message[j]))
+		}
+	}
+	sums := func() {
+		sum, product := new(big.Int), new(big.Int)
+		for k, m := range f.message {
+			if !pooled {
+				product = new(big.Int)
+			}
+			product.Mul(m, f.message[n-1-k])
+			sum.Add(sum, product)
+		}
+	}
+	return []allocBench{{"encodeG1", encode}, {"decodeG1", decode}, {"proofTerms", proofTerms}, {"sums", sums}}
+}
+
+func runAllocBenches(b *testing.B, benches []allocBench) {
+	for _, a := range benches {
+		b.Run(a.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				a.run()
+			}
+		})
+	}
+}
+
+// BenchmarkAllocationsBefore allocates per point or term as the code did before pooling
+func BenchmarkAllocationsBefore(b *testing.B) {
+	runAllocBenches(b, allocPatterns(b, false))
+}
+
+// BenchmarkAllocationsAfter borrows group instances and reuses scratch values
+func BenchmarkAllocationsAfter(b *testing.B) {
+	runAllocBenches(b, allocPatterns(b, true))
+}
+
+// BenchmarkAllocations reports the allocations of the hot paths at n = 1024
+func BenchmarkAllocations(b *testing.B) {
+	f := benchSetup(b)
+	indices := make([]int, 100)
+	values := make([]*big.Int, len(indices))
+	for k := range indices {
+		indices[k] = 5 * k
+		values[k] = f.message[indices[k]]
+	}
+	proofs := ProveSet(f.message, indices)
+	scalars := generateBigIntegerArray(len(indices), frModulus)
+	aggregated := aggregateProof(proofs, scalars, len(indices))
+	pp := &PublicParams{PP1: pp1, PP2: pp2}
+	file := pp.marshal()
+	runAllocBenches(b, []allocBench{
+		{"commit", func() { commit(f.message) }},
+		{"generateProofSingle", func() { generateProofSingle(f.message, 100) }},
+		{"verifySameCommitmentAggregation", func() {
+			verifySameCommitmentAggregation(f.com, aggregated, values, scalars, indices, len(indices))
+		}},
+		{"PublicParams.write", func() { _ = pp.write(io.Discard) }},
+		{"readPublicParams", func() { _, _ = readPublicParams(bytes.NewReader(file)) }},
+	})
+}
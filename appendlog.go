@@ -0,0 +1,132 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Append-only logs. A log of size k holds its entries at 0, ..., k - 1 and zeros after them, every
+	append updates the commitment with one scalar multiplication. C_new of size k' extends C_old of size k
+	exactly when D = C_new - C_old commits to a vector that is zero outside [k, k'): nothing before k
+	changed and nothing after k' was written. The consistency proof is the subvector opening of D to
+	zeros at all positions outside [k, k'), so it is a single G1 point and its verification is that of
+	VerifySameCommitment, without learning the appended entries. Starting from the empty log, whose
+	commitment is the point at infinity, every accepted commitment then is that of a log of its size.
+*/
+
+// Log is an append-only log of up to n entries, it is not safe for concurrent use
+type Log struct {
+	message []*big.Int
+	size    int
+	com     *Commitment
+}
+
+// NewLog returns the empty log
+func NewLog() *Log {
+	l := &Log{message: make([]*big.Int, n), com: (*Commitment)(bls.NewG1().Zero())}
+	for i := range l.message {
+		l.message[i] = new(big.Int)
+	}
+	return l
+}
+
+// Size returns the number of entries in the log
+func (l *Log) Size() int {
+	return l.size
+}
+
+// Commitment returns the commitment to the log
+func (l *Log) Commitment() *bls.PointG1 {
+	return new(bls.PointG1).Set(l.com.Point())
+}
+
+// Append adds the entry at the next free index and returns the index, it panics when the log is full
+func (l *Log) Append(entry *big.Int) int {
+	if l.size == n {
+		panic("the log is full")
+	}
+	i := l.size
+	l.com.Update(i, l.message[i], entry)
+	l.message[i] = new(big.Int).Set(entry)
+	l.size++
+	return i
+}
+
+// ProveEntry returns the proof of the entry at the index, verified by verifySingleProof
+func (l *Log) ProveEntry(index int) *bls.PointG1 {
+	if !(0 <= index && index < l.size) {
+		panic("out of range index")
+	}
+	return ProveSet(l.message, []int{index})[0]
+}
+
+// logUnchanged returns the positions outside [oldSize, newSize) and as many zeros
+func logUnchanged(oldSize, newSize int) ([]int, []*big.Int) {
+	if !(0 <= oldSize && oldSize <= newSize && newSize <= n) {
+		panic("invalid log sizes")
+	}
+	indices := make([]int, 0, n-newSize+oldSize)
+	for i := 0; i < n; i++ {
+		if i < oldSize || i >= newSize {
+			indices = append(indices, i)
+		}
+	}
+	zeros := make([]*big.Int, len(indices))
+	for k := range zeros {
+		zeros[k] = new(big.Int)
+	}
+	return indices, zeros
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the size the log had when it was committed to earlier
+	It returns the proof that the current commitment extends that of the log of the given size.
+*/
+func (l *Log) ProveConsistency(oldSize int) *bls.PointG1 {
+	return l.proveExtension(oldSize, l.size)
+}
+
+// proveExtension returns the proof that the log of size newSize extends that of size oldSize, both
+// at most the current size
+func (l *Log) proveExtension(oldSize, newSize int) *bls.PointG1 {
+	if newSize > l.size {
+		panic("invalid log sizes")
+	}
+	indices, _ := logUnchanged(oldSize, newSize)
+	if len(indices) == 0 {
+		// from the empty log to the full one there is nothing to prove
+		return bls.NewG1().Zero()
+	}
+	diff := make([]*big.Int, n)
+	for i := range diff {
+		diff[i] = new(big.Int)
+		if oldSize <= i && i < newSize {
+			diff[i].Set(l.message[i])
+		}
+	}
+	return OpenSubvector(diff, indices)
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the earlier commitment and the size of its log
+		4. the later commitment and the size of its log
+		5. the proof of Log.ProveConsistency
+	It reports whether the proof shows that the later log extends the earlier one by appending only.
+*/
+func VerifyLogConsistency(oldCom *bls.PointG1, oldSize int, newCom *bls.PointG1, newSize int, proof *bls.PointG1) bool {
+	indices, zeros := logUnchanged(oldSize, newSize)
+	if len(indices) == 0 {
+		return true
+	}
+	g := getG1()
+	defer putG1(g)
+	return VerifySubvector(g.Sub(g.New(), newCom, oldCom), indices, zeros, proof)
+}
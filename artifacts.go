@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Serialized artifacts. Every commitment, proof and aggregation transcript starts with a one byte
+	kind followed by the fingerprint of the parameters it was produced under. Decoding fails unless the
+	fingerprint matches the installed parameters, so artifacts from different SRS instances can never
+	be mixed silently, and the kind keeps e.g. a proof from being read as a commitment.
+*/
+const (
+	artifactCommitment      byte = 1
+	artifactProof           byte = 2
+	artifactAggregatedProof byte = 3
+	artifactCrossTranscript byte = 4
+	artifactEmptinessProof  byte = 5
+	artifactPartialProofs   byte = 6
+	artifactG2Commitment    byte = 7
+	artifactProofSet        byte = 8
+	artifactHistoryProof    byte = 9
+)
+
+// writeArtifactHeader writes the kind and the fingerprint of the installed parameters
+func writeArtifactHeader(w io.Writer, kind byte) error {
+	if _, err := w.Write([]byte{kind}); err != nil {
+		return err
+	}
+	_, err := w.Write(srsFingerprint[:])
+	return err
+}
+
+// readArtifactHeader checks the kind and that the fingerprint is the one of the installed parameters
+func readArtifactHeader(r io.Reader, kind byte) error {
+	header := make([]byte, 1+fingerprintSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return err
+	}
+	if header[0] != kind {
+		return fmt.Errorf("expected artifact of kind %d, got %d", kind, header[0])
+	}
+	if !bytes.Equal(header[1:], srsFingerprint[:]) {
+		return fmt.Errorf("artifact was produced under parameters %x, the installed ones are %x", header[1:], srsFingerprint)
+	}
+	return nil
+}
+
+// readCheckedG1 reads a G1 point and makes sure it lies in the correct subgroup
+func readCheckedG1(r io.Reader) (*bls.PointG1, error) {
+	p, err := readG1(r)
+	if err != nil {
+		return nil, err
+	}
+	g := getG1()
+	defer putG1(g)
+	if !g.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in the correct subgroup")
+	}
+	return p, nil
+}
+
+// encodePointArtifact returns kind || fingerprint || p
+func encodePointArtifact(kind byte, p *bls.PointG1) []byte {
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer cannot fail
+	_ = writeArtifactHeader(&buf, kind)
+	_ = writeG1(&buf, p)
+	return buf.Bytes()
+}
+
+// decodePointArtifact is the inverse of encodePointArtifact
+func decodePointArtifact(kind byte, data []byte) (*bls.PointG1, error) {
+	if len(data) != 1+fingerprintSize+g1Size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", 1+fingerprintSize+g1Size, len(data))
+	}
+	r := bytes.NewReader(data)
+	if err := readArtifactHeader(r, kind); err != nil {
+		return nil, err
+	}
+	return readCheckedG1(r)
+}
+
+// encodeCommitment serializes a commitment returned by commit
+func encodeCommitment(com *bls.PointG1) []byte {
+	return encodePointArtifact(artifactCommitment, com)
+}
+
+// decodeCommitment parses a commitment produced under the installed parameters
+func decodeCommitment(data []byte) (*bls.PointG1, error) {
+	return decodePointArtifact(artifactCommitment, data)
+}
+
+// encodeProof serializes a proof returned by generateProofSingle
+func encodeProof(proof *bls.PointG1) []byte {
+	return encodePointArtifact(artifactProof, proof)
+}
+
+// decodeProof parses a proof produced under the installed parameters
+func decodeProof(data []byte) (*bls.PointG1, error) {
+	return decodePointArtifact(artifactProof, data)
+}
+
+// encodeAggregatedProof serializes a proof returned by aggregateProof
+func encodeAggregatedProof(proof *bls.PointG1) []byte {
+	return encodePointArtifact(artifactAggregatedProof, proof)
+}
+
+// decodeAggregatedProof parses an aggregated proof produced under the installed parameters
+func decodeAggregatedProof(data []byte) (*bls.PointG1, error) {
+	return decodePointArtifact(artifactAggregatedProof, data)
+}
+
+// crossTranscript is the whole statement checked by verifyCrossCommitmentAggregation together with its proof
+type crossTranscript struct {
+	Commitments    []*bls.PointG1
+	Proof          *bls.PointG1
+	Indices        [][]int
+	Values         [][]*big.Int
+	MessageScalars [][]*big.Int
+	ComScalars     []*big.Int
+}
+
+// verify runs verifyCrossCommitmentAggregation on the transcript
+func (t *crossTranscript) verify() bool {
+	m := len(t.Commitments)
+	if !(len(t.Indices) == m && len(t.Values) == m && len(t.MessageScalars) == m && len(t.ComScalars) == m) {
+		panic("arrays with incorrect length")
+	}
+	messages := make([]*[]*big.Int, m)
+	messageScalars := make([]*[]*big.Int, m)
+	indices := make([]*[]int, m)
+	number := make([]int, m)
+	for j := 0; j < m; j++ {
+		messages[j] = &t.Values[j]
+		messageScalars[j] = &t.MessageScalars[j]
+		indices[j] = &t.Indices[j]
+		number[j] = len(t.Indices[j])
+	}
+	return verifyCrossCommitmentAggregation(t.Commitments, t.Proof, messages, messageScalars, t.ComScalars, indices, number, m)
+}
+
+/*
+	It serializes the transcript as
+		kind || fingerprint || m || proof || for each commitment j: C_j || t'_j || |S_j| || (i, m_i, t_i) for i in S_j
+	with m, |S_j| and the indices as big endian uint32
+*/
+func (t *crossTranscript) marshal() ([]byte, error) {
+	m := len(t.Commitments)
+	if !(len(t.Indices) == m && len(t.Values) == m && len(t.MessageScalars) == m && len(t.ComScalars) == m) {
+		return nil, errors.New("arrays with incorrect length")
+	}
+	var buf bytes.Buffer
+	_ = writeArtifactHeader(&buf, artifactCrossTranscript)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(m))
+	_ = writeG1(&buf, t.Proof)
+	for j := 0; j < m; j++ {
+		_ = writeG1(&buf, t.Commitments[j])
+		if err := writeScalar(&buf, t.ComScalars[j]); err != nil {
+			return nil, err
+		}
+		k := len(t.Indices[j])
+		if len(t.Values[j]) != k || len(t.MessageScalars[j]) != k {
+			return nil, errors.New("arrays with incorrect length")
+		}
+		_ = binary.Write(&buf, binary.BigEndian, uint32(k))
+		for i := 0; i < k; i++ {
+			if !(0 <= t.Indices[j][i] && t.Indices[j][i] < n) {
+				return nil, errors.New("out of range index")
+			}
+			_ = binary.Write(&buf, binary.BigEndian, uint32(t.Indices[j][i]))
+			if err := writeScalar(&buf, t.Values[j][i]); err != nil {
+				return nil, err
+			}
+			if err := writeScalar(&buf, t.MessageScalars[j][i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalCrossTranscript parses a transcript produced under the installed parameters
+func unmarshalCrossTranscript(data []byte) (*crossTranscript, error) {
+	r := bytes.NewReader(data)
+	if err := readArtifactHeader(r, artifactCrossTranscript); err != nil {
+		return nil, err
+	}
+	var m uint32
+	if err := binary.Read(r, binary.BigEndian, &m); err != nil {
+		return nil, err
+	}
+	// every commitment takes at least this many bytes, which bounds what we allocate
+	if int64(m) > int64(r.Len())/(g1Size+scalarSize+4) {
+		return nil, errors.New("truncated transcript")
+	}
+	t := &crossTranscript{}
+	var err error
+	if t.Proof, err = readCheckedG1(r); err != nil {
+		return nil, fmt.Errorf("proof: %w", err)
+	}
+	for j := 0; j < int(m); j++ {
+		com, err := readCheckedG1(r)
+		if err != nil {
+			return nil, fmt.Errorf("commitment %d: %w", j, err)
+		}
+		comScalar, err := readScalar(r)
+		if err != nil {
+			return nil, err
+		}
+		var k uint32
+		if err := binary.Read(r, binary.BigEndian, &k); err != nil {
+			return nil, err
+		}
+		if int64(k) > int64(r.Len())/(4+2*scalarSize) {
+			return nil, errors.New("truncated transcript")
+		}
+		indices := make([]int, k)
+		values := make([]*big.Int, k)
+		scalars := make([]*big.Int, k)
+		for i := 0; i < int(k); i++ {
+			var index uint32
+			if err := binary.Read(r, binary.BigEndian, &index); err != nil {
+				return nil, err
+			}
+			if index >= n {
+				return nil, errors.New("out of range index")
+			}
+			indices[i] = int(index)
+			if values[i], err = readScalar(r); err != nil {
+				return nil, err
+			}
+			if scalars[i], err = readScalar(r); err != nil {
+				return nil, err
+			}
+		}
+		t.Commitments = append(t.Commitments, com)
+		t.ComScalars = append(t.ComScalars, comScalar)
+		t.Indices = append(t.Indices, indices)
+		t.Values = append(t.Values, values)
+		t.MessageScalars = append(t.MessageScalars, scalars)
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("trailing bytes after transcript")
+	}
+	return t, nil
+}
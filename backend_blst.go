@@ -0,0 +1,137 @@
+//go:build blst && cgo
+
+package main
+
+import (
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+/*
+	The supranational/blst backend, compiled in only with the blst build tag (go build -tags blst) since
+	it needs cgo. Both libraries use the same uncompressed encoding except for the point at infinity, which
+	blst flags with the bit 0x40 of the first byte where go-ethereum writes all zeros.
+*/
+
+func init() {
+	RegisterBackend(blstBackend{})
+}
+
+// blstBackend uses supranational/blst through its Go bindings
+type blstBackend struct{}
+
+func (blstBackend) Name() string { return "blst" }
+
+func (blstBackend) MultiExpG1(points []*bls.PointG1, scalars []fr) *bls.PointG1 {
+	if len(points) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	if len(points) == 0 {
+		return bls.NewG1().Zero()
+	}
+	return fromBlstG1(blst.P1AffinesMult(toBlstG1s(points), toBlstScalars(scalars), frBitLen))
+}
+
+func (blstBackend) MultiExpG2(points []*bls.PointG2, scalars []fr) *bls.PointG2 {
+	if len(points) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	if len(points) == 0 {
+		return bls.NewG2().Zero()
+	}
+	return fromBlstG2(blst.P2AffinesMult(toBlstG2s(points), toBlstScalars(scalars), frBitLen))
+}
+
+func (blstBackend) PairingCheck(a []*bls.PointG1, b []*bls.PointG2) bool {
+	g1, g2 := getG1(), getG2()
+	defer putG1(g1)
+	defer putG2(g2)
+	// e(0, Q) = e(P, 0) = 1, such pairs are left out of the Miller loop
+	var ps []*bls.PointG1
+	var qs []*bls.PointG2
+	for i := range a {
+		if !g1.IsZero(a[i]) && !g2.IsZero(b[i]) {
+			ps = append(ps, a[i])
+			qs = append(qs, b[i])
+		}
+	}
+	if len(ps) == 0 {
+		return true
+	}
+	one := blst.Fp12One()
+	return blst.Fp12FinalVerify(blst.Fp12MillerLoopN(toBlstG2s(qs), toBlstG1s(ps)), &one)
+}
+
+// toBlstScalars writes the scalars one after the other as 32 little endian bytes
+func toBlstScalars(scalars []fr) []byte {
+	res := make([]byte, 32*len(scalars))
+	for i, s := range scalars {
+		// a multiplication by 1 leaves Montgomery form
+		var y fr
+		y.mul(s, fr{1})
+		for j := 0; j < 32; j++ {
+			res[32*i+j] = byte(y[j/8] >> (8 * (j % 8)))
+		}
+	}
+	return res
+}
+
+// toBlstG1s converts points to blst, where the point at infinity is the all zero affine point
+func toBlstG1s(points []*bls.PointG1) []blst.P1Affine {
+	g := getG1()
+	defer putG1(g)
+	res := make([]blst.P1Affine, len(points))
+	for i, p := range points {
+		if g.IsZero(p) {
+			continue
+		}
+		if res[i].Deserialize(g.ToBytes(p)) == nil {
+			panic("point not on the curve")
+		}
+	}
+	return res
+}
+
+// toBlstG2s converts points to blst, where the point at infinity is the all zero affine point
+func toBlstG2s(points []*bls.PointG2) []blst.P2Affine {
+	g := getG2()
+	defer putG2(g)
+	res := make([]blst.P2Affine, len(points))
+	for i, p := range points {
+		if g.IsZero(p) {
+			continue
+		}
+		if res[i].Deserialize(g.ToBytes(p)) == nil {
+			panic("point not on the curve")
+		}
+	}
+	return res
+}
+
+func fromBlstG1(p *blst.P1) *bls.PointG1 {
+	g := getG1()
+	defer putG1(g)
+	b := p.Serialize()
+	if b[0]&0x40 != 0 {
+		return g.Zero()
+	}
+	res, err := g.FromBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func fromBlstG2(p *blst.P2) *bls.PointG2 {
+	g := getG2()
+	defer putG2(g)
+	b := p.Serialize()
+	if b[0]&0x40 != 0 {
+		return g.Zero()
+	}
+	res, err := g.FromBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"runtime"
+	"unsafe"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	gnark "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	gnarkfr "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	The gnark-crypto backend. Points cross over through their big endian affine coordinates, which both
+	libraries read and write without any checks beyond what the scheme already did. Scalars need no
+	conversion at all: gnark-crypto stores Fr elements in the same Montgomery form as fr.
+*/
+
+// gnarkBackend uses consensys/gnark-crypto's bls12-381 package
+type gnarkBackend struct{}
+
+func (gnarkBackend) Name() string { return "gnark" }
+
+func (gnarkBackend) MultiExpG1(points []*bls.PointG1, scalars []fr) *bls.PointG1 {
+	if len(points) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	var res gnark.G1Affine
+	if _, err := res.MultiExp(toGnarkG1s(points), toGnarkScalars(scalars), ecc.MultiExpConfig{NbTasks: runtime.NumCPU()}); err != nil {
+		panic(err)
+	}
+	return fromGnarkG1(&res)
+}
+
+func (gnarkBackend) MultiExpG2(points []*bls.PointG2, scalars []fr) *bls.PointG2 {
+	if len(points) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	var res gnark.G2Affine
+	if _, err := res.MultiExp(toGnarkG2s(points), toGnarkScalars(scalars), ecc.MultiExpConfig{NbTasks: runtime.NumCPU()}); err != nil {
+		panic(err)
+	}
+	return fromGnarkG2(&res)
+}
+
+// gnarkFixedLines holds the Miller loop lines of fixed G2 points, keyed by the points themselves
+type gnarkFixedLines = [2][len(gnark.LoopCounter) - 1]gnark.LineEvaluationAff
+
+// gnarkLines are the lines precomputed by precomputeLines, replaced whenever the parameters change
+var gnarkLines map[*bls.PointG2]*gnarkFixedLines
+
+// precomputeLines computes the lines of the given points, about 24 KB each, dropping the earlier ones
+func (gnarkBackend) precomputeLines(points []*bls.PointG2) {
+	lines := make(map[*bls.PointG2]*gnarkFixedLines, len(points))
+	for i, q := range toGnarkG2s(points) {
+		l := gnark.PrecomputeLines(q)
+		lines[points[i]] = &l
+	}
+	gnarkLines = lines
+}
+
+func (gnarkBackend) lineSize() uintptr { return unsafe.Sizeof(gnarkFixedLines{}) }
+
+// PairingCheck runs the Miller loop of precomputed points on their lines and all others as usual
+func (gnarkBackend) PairingCheck(a []*bls.PointG1, b []*bls.PointG2) bool {
+	var fixedP, p []*bls.PointG1
+	var fixedLines []gnarkFixedLines
+	var q []*bls.PointG2
+	for i := range a {
+		if l, ok := gnarkLines[b[i]]; ok {
+			fixedP = append(fixedP, a[i])
+			// the Miller loop evaluates the lines in place, so it gets a copy
+			fixedLines = append(fixedLines, *l)
+		} else {
+			p = append(p, a[i])
+			q = append(q, b[i])
+		}
+	}
+	var res gnark.GT
+	res.SetOne()
+	if len(fixedP) > 0 {
+		f, err := gnark.MillerLoopFixedQ(toGnarkG1s(fixedP), fixedLines)
+		if err != nil {
+			panic(err)
+		}
+		res.Mul(&res, &f)
+	}
+	if len(p) > 0 {
+		f, err := gnark.MillerLoop(toGnarkG1s(p), toGnarkG2s(q))
+		if err != nil {
+			panic(err)
+		}
+		res.Mul(&res, &f)
+	}
+	res = gnark.FinalExponentiation(&res)
+	return res.IsOne()
+}
+
+func toGnarkScalars(scalars []fr) []gnarkfr.Element {
+	res := make([]gnarkfr.Element, len(scalars))
+	for i, s := range scalars {
+		res[i] = gnarkfr.Element(s)
+	}
+	return res
+}
+
+// toGnarkG1s converts points to gnark-crypto, the point at infinity is (0, 0) in both encodings
+func toGnarkG1s(points []*bls.PointG1) []gnark.G1Affine {
+	g := getG1()
+	defer putG1(g)
+	res := make([]gnark.G1Affine, len(points))
+	for i, p := range points {
+		b := g.ToBytes(p)
+		res[i].X.SetBytes(b[:48])
+		res[i].Y.SetBytes(b[48:])
+	}
+	return res
+}
+
+// toGnarkG2s converts points to gnark-crypto, go-ethereum writes c1 before c0
+func toGnarkG2s(points []*bls.PointG2) []gnark.G2Affine {
+	g := getG2()
+	defer putG2(g)
+	res := make([]gnark.G2Affine, len(points))
+	for i, p := range points {
+		b := g.ToBytes(p)
+		res[i].X.A1.SetBytes(b[:48])
+		res[i].X.A0.SetBytes(b[48:96])
+		res[i].Y.A1.SetBytes(b[96:144])
+		res[i].Y.A0.SetBytes(b[144:])
+	}
+	return res
+}
+
+func fromGnarkG1(p *gnark.G1Affine) *bls.PointG1 {
+	g := getG1()
+	defer putG1(g)
+	if p.IsInfinity() {
+		return g.Zero()
+	}
+	x, y := p.X.Bytes(), p.Y.Bytes()
+	res, err := g.FromBytes(append(x[:], y[:]...))
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func fromGnarkG2(p *gnark.G2Affine) *bls.PointG2 {
+	g := getG2()
+	defer putG2(g)
+	if p.IsInfinity() {
+		return g.Zero()
+	}
+	x1, x0, y1, y0 := p.X.A1.Bytes(), p.X.A0.Bytes(), p.Y.A1.Bytes(), p.Y.A0.Bytes()
+	b := make([]byte, 0, g2Size)
+	b = append(append(append(append(b, x1[:]...), x0[:]...), y1[:]...), y0[:]...)
+	res, err := g.FromBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
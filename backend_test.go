@@ -0,0 +1,34 @@
+package main
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// TestVerifyConcurrent verifies from many goroutines at once, the pairings of one verification must not
+// end up in the check of another
+func TestVerifyConcurrent(t *testing.T) {
+	f := benchSetup(t)
+	var wg sync.WaitGroup
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < 20; r++ {
+				k := (w + r) % len(f.indices)
+				if !verifySingleProof(f.com, f.values[k], f.proofs[k], f.indices[k]) {
+					t.Errorf("proof of index %d rejected", f.indices[k])
+				}
+				wrong := new(big.Int).Add(f.values[k], big.NewInt(1))
+				if verifySingleProof(f.com, wrong, f.proofs[k], f.indices[k]) {
+					t.Errorf("wrong value of index %d accepted", f.indices[k])
+				}
+				if !verifySameCommitmentAggregation(f.com, f.aggregated, f.values, f.scalars, f.indices, len(f.indices)) {
+					t.Error("aggregated proof rejected")
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
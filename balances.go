@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Token balances on top of KVMap: the account name is the key and its balance the value, a uint64
+	encoded in 8 big endian bytes. A transfer changes the entries of the sender and of the receiver, so
+	it moves the commitment by two scalar multiplications, and its receipt carries
+		- the new balances of both accounts with their proofs against the new commitment, for the owners,
+		- the old balances with one aggregated proof against the old commitment (AggregateSameCommitment),
+		  for auditors, a receiver without an account opening to zero at the position it is created at,
+		- for a receiver without an account, the proof that it had none anywhere in its probe sequence.
+	Accounts sit anywhere in the probe sequences of kvmap.go, so the map takes about half of n accounts
+	before one does not fit. An auditor holding the old commitment checks the old balances, recomputes
+	the new commitment from them with Commitment.Update and compares it to the one published, which
+	rules out any change to other accounts, see VerifyTransfer. This takes pp1 next to pp2.
+*/
+
+// balanceValue returns the value of a balance in the map
+func balanceValue(balance uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, balance)
+	return b
+}
+
+// BalanceMap is a committed map from accounts to balances, it is not safe for concurrent use
+type BalanceMap struct {
+	application string
+	kv          *KVMap
+}
+
+// NewBalanceMap returns the map of the application without any account, see NewEntryEncoder
+func NewBalanceMap(application string) *BalanceMap {
+	return &BalanceMap{application: application, kv: NewKVMap(application)}
+}
+
+// Commitment returns the commitment to the balances, it changes with every Mint and Transfer
+func (bm *BalanceMap) Commitment() *bls.PointG1 {
+	return bm.kv.Commitment()
+}
+
+// Balance returns the balance of the account and whether it exists
+func (bm *BalanceMap) Balance(account []byte) (uint64, bool) {
+	value, ok := bm.kv.Get(account)
+	if !ok {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(value), true
+}
+
+// Mint adds the amount to the account, creating it if needed
+func (bm *BalanceMap) Mint(account []byte, amount uint64) error {
+	balance, _ := bm.Balance(account)
+	if balance+amount < balance {
+		return errors.New("the balance overflows")
+	}
+	return bm.kv.Put(account, balanceValue(balance+amount))
+}
+
+// ProveBalance returns the balance of an existing account and the proof of it, it panics if the
+// account does not exist
+func (bm *BalanceMap) ProveBalance(account []byte) (uint64, *KVMembershipProof) {
+	value, proof := bm.kv.ProveMembership(account)
+	return binary.BigEndian.Uint64(value), proof
+}
+
+// VerifyBalance reports whether proof shows the account has the balance in the map of the application
+// with commitment com
+func VerifyBalance(com *bls.PointG1, application string, account []byte, balance uint64, proof *KVMembershipProof) bool {
+	return VerifyKVMembership(com, application, account, balanceValue(balance), proof)
+}
+
+// TransferReceipt is what a transfer hands to the owners of the accounts and to auditors
+type TransferReceipt struct {
+	From, To []byte
+	Amount   uint64
+	// NewCommitment is the commitment after the transfer
+	NewCommitment *bls.PointG1
+	// FromBalance and ToBalance are the balances after the transfer, opened by FromProof and ToProof
+	// against NewCommitment
+	FromBalance, ToBalance uint64
+	FromProof, ToProof     *KVMembershipProof
+	// ToCreated tells whether the transfer created the account of the receiver
+	ToCreated bool
+	// ToAbsent proves against the old commitment that the receiver had no account, it is nil unless
+	// ToCreated is set
+	ToAbsent *KVNonMembershipProof
+	// UpdateProof opens the old commitment to the entries of both accounts before the transfer
+	UpdateProof *bls.PointG1
+}
+
+// Transfer moves the amount from one existing account to another account, creating the latter if
+// needed, and returns the receipt. Nothing changes when it fails
+func (bm *BalanceMap) Transfer(from, to []byte, amount uint64) (*TransferReceipt, error) {
+	if bytes.Equal(from, to) {
+		return nil, errors.New("transfer to the sending account")
+	}
+	fromBalance, ok := bm.Balance(from)
+	if !ok {
+		return nil, fmt.Errorf("no account %x", from)
+	}
+	if fromBalance < amount {
+		return nil, fmt.Errorf("account %x cannot afford %d", from, amount)
+	}
+	toBalance, _ := bm.Balance(to)
+	toProbe, ok := bm.kv.position(to)
+	if toProbe < 0 {
+		return nil, fmt.Errorf("all %d positions of account %x are taken", kvMaxProbes, to)
+	}
+	if toBalance+amount < toBalance {
+		return nil, errors.New("the balance overflows")
+	}
+	fromProbe, _ := bm.kv.position(from)
+	slots := []int{kvSlot(from, fromProbe), kvSlot(to, toProbe)}
+	openings := make([]Opening, len(slots))
+	for k, proof := range ProveSet(bm.kv.message, slots) {
+		openings[k] = Opening{Index: slots[k], Value: bm.kv.message[slots[k]], Proof: proof}
+	}
+	receipt := &TransferReceipt{
+		From: append([]byte{}, from...), To: append([]byte{}, to...), Amount: amount, ToCreated: !ok,
+		UpdateProof: AggregateSameCommitment(bm.kv.Commitment(), openings),
+	}
+	if receipt.ToCreated {
+		receipt.ToAbsent = bm.kv.ProveNonMembership(to)
+	}
+	// neither Put can fail: from exists and a position for to was found above
+	_ = bm.kv.Put(from, balanceValue(fromBalance-amount))
+	_ = bm.kv.Put(to, balanceValue(toBalance+amount))
+	receipt.NewCommitment = bm.Commitment()
+	receipt.FromBalance, receipt.FromProof = bm.ProveBalance(from)
+	receipt.ToBalance, receipt.ToProof = bm.ProveBalance(to)
+	return receipt, nil
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 and pp2 (implicitly)
+		3. the commitment before the transfer
+		4. the application of the map
+		5. the receipt of the transfer
+	It reports whether the receipt shows the commitment of the receipt follows from the old one by the
+	transfer and nothing else. Of FromProof and ToProof it only takes the probes, see VerifyBalance.
+*/
+func VerifyTransfer(old *bls.PointG1, application string, receipt *TransferReceipt) bool {
+	if bytes.Equal(receipt.From, receipt.To) || receipt.FromBalance+receipt.Amount < receipt.FromBalance {
+		return false
+	}
+	if receipt.ToBalance < receipt.Amount || (receipt.ToCreated && receipt.ToBalance != receipt.Amount) {
+		return false
+	}
+	e := NewEntryEncoder(application)
+	// the accounts stay where they are, or in the case of a new receiver where it was created
+	if receipt.FromProof == nil || receipt.ToProof == nil {
+		return false
+	}
+	probes := []int{receipt.FromProof.Probe, receipt.ToProof.Probe}
+	if !(0 <= probes[0] && probes[0] < kvMaxProbes && 0 <= probes[1] && probes[1] < kvMaxProbes) {
+		return false
+	}
+	slots := []int{kvSlot(receipt.From, probes[0]), kvSlot(receipt.To, probes[1])}
+	if slots[0] == slots[1] {
+		return false
+	}
+	oldEntries := []*big.Int{kvEntry(e, receipt.From, probes[0], balanceValue(receipt.FromBalance+receipt.Amount)), new(big.Int)}
+	if receipt.ToCreated {
+		// without this the receiver could hold a second account further along its probe sequence
+		if receipt.ToAbsent == nil || !VerifyKVNonMembership(old, application, receipt.To, receipt.ToAbsent) {
+			return false
+		}
+	} else {
+		oldEntries[1] = kvEntry(e, receipt.To, probes[1], balanceValue(receipt.ToBalance-receipt.Amount))
+	}
+	newEntries := []*big.Int{kvEntry(e, receipt.From, probes[0], balanceValue(receipt.FromBalance)), kvEntry(e, receipt.To, probes[1], balanceValue(receipt.ToBalance))}
+	if !VerifySameCommitment(old, slots, oldEntries, receipt.UpdateProof) {
+		return false
+	}
+	com := (*Commitment)(new(bls.PointG1).Set(old))
+	for k, slot := range slots {
+		com.Update(slot, oldEntries[k], newEntries[k])
+	}
+	g := getG1()
+	defer putG1(g)
+	return g.Equal(com.Point(), receipt.NewCommitment)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestBalanceMap opens far more accounts than one position per account allows and checks transfers
+// between them and to new accounts
+func TestBalanceMap(t *testing.T) {
+	benchSetup(t)
+	const app, accounts = "balances-test", 200
+	bm := NewBalanceMap(app)
+	account := func(k int) []byte { return []byte(fmt.Sprintf("account-%d", k)) }
+	for k := 0; k < accounts; k++ {
+		if err := bm.Mint(account(k), 100); err != nil {
+			t.Fatalf("account %d: %v", k, err)
+		}
+	}
+	if _, err := bm.Transfer(account(0), account(1), 101); err == nil {
+		t.Fatal("overspend accepted")
+	}
+
+	for _, to := range [][]byte{account(accounts - 1), []byte("new account")} {
+		old := bm.Commitment()
+		receipt, err := bm.Transfer(account(0), to, 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyTransfer(old, app, receipt) {
+			t.Fatalf("transfer to %s rejected", to)
+		}
+		if !VerifyBalance(receipt.NewCommitment, app, receipt.From, receipt.FromBalance, receipt.FromProof) ||
+			!VerifyBalance(receipt.NewCommitment, app, receipt.To, receipt.ToBalance, receipt.ToProof) {
+			t.Fatalf("balances after the transfer to %s rejected", to)
+		}
+		if balance, _ := bm.Balance(to); balance != receipt.ToBalance {
+			t.Fatalf("receiver holds %d, the receipt says %d", balance, receipt.ToBalance)
+		}
+
+		inflated := *receipt
+		inflated.Amount, inflated.ToBalance = receipt.Amount+1, receipt.ToBalance+1
+		if VerifyTransfer(old, app, &inflated) {
+			t.Fatalf("inflated transfer to %s accepted", to)
+		}
+		if receipt.ToCreated {
+			unproven := *receipt
+			unproven.ToAbsent = nil
+			if VerifyTransfer(old, app, &unproven) {
+				t.Fatal("new account without a proof of absence accepted")
+			}
+		}
+	}
+	if balance, _ := bm.Balance(account(0)); balance != 40 {
+		t.Fatalf("sender holds %d", balance)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// Opening is the claim that entry Index of a committed message is Value, together with its proof
+type Opening struct {
+	Index int
+	Value *big.Int
+	Proof *bls.PointG1
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the openings to check
+		4. the commitment they all refer to
+	It checks all openings at once. With random coefficients r_k chosen here, the single proof equations
+		e(C, g_2^{alpha^{n-i_k}}) = e(pi_k, g_2) * g_T^{alpha^{n+1} * m_k}
+	are folded into
+		e(C, \sum r_k g_2^{alpha^{n-i_k}}) * e(\sum r_k pi_k, g_2)^{-1} = g_T^{alpha^{n+1} \sum r_k m_k}
+	which holds for invalid openings with negligible probability. That is two pairings and one MSM in
+	each group instead of a multi-pairing per opening. An empty batch is valid.
+*/
+func VerifyBatch(openings []Opening, com *bls.PointG1) bool {
+	observeBatch("verify_batch", len(openings))
+	return profiled("verify_batch", func() bool {
+		if len(openings) == 0 {
+			return true
+		}
+		r := frVector(generateBigIntegerArray(len(openings), engine.G1.Q()))
+		bases := make([]*bls.PointG2, len(openings))
+		proofs := make([]*bls.PointG1, len(openings))
+		var sum, product fr
+		for k, o := range openings {
+			// Making sure in index lies in the boundaries
+			if !(0 <= o.Index && o.Index < n) {
+				panic("out of range index")
+			}
+			bases[k] = pp2[n-o.Index-1]
+			proofs[k] = o.Proof
+			product.mul(frFromBig(o.Value), r[k])
+			sum.add(sum, product)
+		}
+		g1s := []*bls.PointG1{com, negG1(backend.MultiExpG1(proofs, r))}
+		g2s := []*bls.PointG2{backend.MultiExpG2(bases, r), g2Generator}
+		return verifyPairings(g1s, g2s, sum)
+	})
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. independent cross commitment transcripts, e.g. one per block or shard
+	It checks all of them at once. Transcript b is valid iff
+		\prod_j e(C_{b,j}^{t_{b,j}}, P_{b,j}) * e(pi_b, g_2)^{-1} = g_T^{alpha^{n+1} * s_b}
+	with P_{b,j} = \prod_i g_2^{alpha^{n-i} t_{b,j,i}} and s_b = \sum m_{b,j,i} t_{b,j,i} t_{b,j} as in
+	verifyCrossCommitmentAggregation. Raising equation b to a random rho_b and multiplying them merges the
+	proofs into one pairing e(\sum rho_b pi_b, g_2) and all of them share a single final exponentiation.
+	An empty batch is valid.
+*/
+func verifyCrossBatch(transcripts []*crossTranscript) bool {
+	return profiled("verify_cross_batch", func() bool {
+		if len(transcripts) == 0 {
+			return true
+		}
+		rho := frVector(generateBigIntegerArray(len(transcripts), engine.G1.Q()))
+		proofs := make([]*bls.PointG1, len(transcripts))
+		for b, t := range transcripts {
+			proofs[b] = t.Proof
+		}
+		return verifyCrossCombination(transcripts, rho, backend.MultiExpG1(proofs, rho))
+	})
+}
+
+// verifyCrossCombination checks the product of the equations of the transcripts, equation b raised to
+// rho_b, for the proof \sum rho_b pi_b. The proofs of the transcripts are not used
+func verifyCrossCombination(transcripts []*crossTranscript, rho []fr, proof *bls.PointG1) bool {
+	if len(rho) != len(transcripts) {
+		panic("arrays with incorrect length")
+	}
+	g := getG1()
+	defer putG1(g)
+	var g1s []*bls.PointG1
+	var g2s []*bls.PointG2
+	var sum, product, weight fr
+	for b, t := range transcripts {
+		r := rho[b]
+		m := len(t.Commitments)
+		if !(len(t.Indices) == m && len(t.Values) == m && len(t.MessageScalars) == m && len(t.ComScalars) == m) {
+			panic("arrays with incorrect length")
+		}
+		for j := 0; j < m; j++ {
+			// rho_b t_{b,j}
+			weight.mul(r, frFromBig(t.ComScalars[j]))
+			if !(len(t.Values[j]) == len(t.Indices[j]) && len(t.MessageScalars[j]) == len(t.Indices[j])) {
+				panic("arrays with incorrect length")
+			}
+			bases := make([]*bls.PointG2, len(t.Indices[j]))
+			scalars := make([]fr, len(t.Indices[j]))
+			for i, index := range t.Indices[j] {
+				if !(0 <= index && index < n) {
+					panic("out of range index")
+				}
+				bases[i] = pp2[n-index-1]
+				scalars[i] = frFromBig(t.MessageScalars[j][i])
+				// s_b = \sum m_{b,j,i} t_{b,j,i} t_{b,j}, weighted by rho_b
+				product.mul(frFromBig(t.Values[j][i]), scalars[i])
+				product.mul(product, weight)
+				sum.add(sum, product)
+			}
+			// C_{b,j}^{rho_b t_{b,j}}
+			c := new(bls.PointG1)
+			mulG1(g, c, t.Commitments[j], weight)
+			g1s = append(g1s, c)
+			g2s = append(g2s, backend.MultiExpG2(bases, scalars))
+		}
+	}
+	g1s = append(g1s, negG1(proof))
+	g2s = append(g2s, g2Generator)
+	return verifyPairings(g1s, g2s, sum)
+}
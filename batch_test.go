@@ -0,0 +1,34 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestVerifyBatch(t *testing.T) {
+	f := benchSetup(t)
+	openings := make([]Opening, len(f.indices))
+	for k, i := range f.indices {
+		openings[k] = Opening{Index: i, Value: f.values[k], Proof: f.proofs[k]}
+	}
+	if !VerifyBatch(openings, f.com) {
+		t.Fatal("valid openings rejected")
+	}
+	if !VerifyBatch(nil, f.com) {
+		t.Fatal("empty batch rejected")
+	}
+
+	wrong := append([]Opening(nil), openings...)
+	wrong[3].Value = new(big.Int).Add(wrong[3].Value, big.NewInt(1))
+	if VerifyBatch(wrong, f.com) {
+		t.Fatal("batch with one wrong value accepted")
+	}
+	swapped := append([]Opening(nil), openings...)
+	swapped[0].Proof, swapped[1].Proof = openings[1].Proof, openings[0].Proof
+	if VerifyBatch(swapped, f.com) {
+		t.Fatal("batch with swapped proofs accepted")
+	}
+	if VerifyBatch(openings, f.aggregated) {
+		t.Fatal("batch accepted against another commitment")
+	}
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Benchmarks. n is a compile time constant, so the scheme itself is measured at n = 1024 and the
+	multi-scalar multiplications underneath at several sizes. Everything that goes through a Backend
+	runs once per registered backend (go test -bench . -tags blst includes blst).
+*/
+
+// benchFixture is shared by all benchmarks and tests, setting up parameters takes a few seconds
+type benchFixture struct {
+	message []*big.Int
+	com     *bls.PointG1
+	// k = 8 openings of message with their proofs and aggregation scalars
+	indices []int
+	values  []*big.Int
+	proofs  []*bls.PointG1
+	scalars []*big.Int
+	// the aggregation of proofs with scalars
+	aggregated *bls.PointG1
+	// m = 4 commitments, each opened at the first two indices and aggregated across
+	cross *crossTranscript
+}
+
+var (
+	benchOnce sync.Once
+	bench     benchFixture
+)
+
+func benchSetup(tb testing.TB) *benchFixture {
+	tb.Helper()
+	benchOnce.Do(func() {
+		eng, arr1, arr2, _ := setup()
+		engine = eng
+		(&PublicParams{PP1: arr1, PP2: arr2}).install()
+		f := &bench
+		f.message = generateBigIntegerArray(n, engine.G1.Q())
+		f.com = commit(f.message)
+		f.indices = []int{0, 1, 2, 100, 200, 500, 900, n - 1}
+		for _, i := range f.indices {
+			f.values = append(f.values, f.message[i])
+		}
+		f.proofs = ProveSet(f.message, f.indices)
+		f.scalars = generateBigIntegerArray(len(f.indices), engine.G1.Q())
+		f.aggregated = aggregateProof(f.proofs, f.scalars, len(f.indices))
+		t := &crossTranscript{}
+		var partial []*bls.PointG1
+		for j := 0; j < 4; j++ {
+			m := generateBigIntegerArray(n, engine.G1.Q())
+			idx := f.indices[:2]
+			s := generateBigIntegerArray(len(idx), engine.G1.Q())
+			t.Commitments = append(t.Commitments, commit(m))
+			t.Indices = append(t.Indices, idx)
+			t.Values = append(t.Values, []*big.Int{m[idx[0]], m[idx[1]]})
+			t.MessageScalars = append(t.MessageScalars, s)
+			partial = append(partial, aggregateProof(ProveSet(m, idx), s, len(idx)))
+		}
+		t.ComScalars = generateBigIntegerArray(len(partial), engine.G1.Q())
+		t.Proof = aggregateProof(partial, t.ComScalars, len(partial))
+		f.cross = t
+	})
+	return &bench
+}
+
+// forEachBackend runs the benchmark once per registered backend
+func forEachBackend(b *testing.B, run func(b *testing.B)) {
+	for _, name := range Backends() {
+		b.Run(name, func(b *testing.B) {
+			if err := SelectBackend(name); err != nil {
+				b.Fatal(err)
+			}
+			defer SelectBackend("geth")
+			b.ResetTimer()
+			run(b)
+		})
+	}
+}
+
+func BenchmarkSetup(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		setup()
+	}
+}
+
+func BenchmarkMultiExpG1(b *testing.B) {
+	benchSetup(b)
+	for _, size := range []int{16, 64, 256, n} {
+		scalars := frVector(generateBigIntegerArray(size, engine.G1.Q()))
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			forEachBackend(b, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					backend.MultiExpG1(pp1[:size], scalars)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkMultiExpG2(b *testing.B) {
+	benchSetup(b)
+	for _, size := range []int{2, 8, 64} {
+		scalars := frVector(generateBigIntegerArray(size, engine.G1.Q()))
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			forEachBackend(b, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					backend.MultiExpG2(pp2[:size], scalars)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkCommit(b *testing.B) {
+	f := benchSetup(b)
+	forEachBackend(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			commit(f.message)
+		}
+	})
+}
+
+func BenchmarkProveSingle(b *testing.B) {
+	f := benchSetup(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		generateProofSingle(f.message, 100)
+	}
+}
+
+func BenchmarkProveSet(b *testing.B) {
+	f := benchSetup(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ProveSet(f.message, f.indices)
+	}
+}
+
+// BenchmarkProverProve measures cache misses of a Prover, which reuse its scratch space
+func BenchmarkProverProve(b *testing.B) {
+	f := benchSetup(b)
+	p := NewProver(f.message, 0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Prove(100)
+	}
+}
+
+func BenchmarkProveAll(b *testing.B) {
+	f := benchSetup(b)
+	// the first call computes the cached spectrum of pp1
+	ProveAll(f.message)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ProveAll(f.message)
+	}
+}
+
+func BenchmarkAggregate(b *testing.B) {
+	f := benchSetup(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		aggregateProof(f.proofs, f.scalars, len(f.proofs))
+	}
+}
+
+func BenchmarkVerifySingle(b *testing.B) {
+	f := benchSetup(b)
+	forEachBackend(b, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if !verifySingleProof(f.com, f.values[3], f.proofs[3], f.indices[3]) {
+				b.Fatal("valid proof rejected")
+			}
+		}
+	})
+}
+
+// BenchmarkVerifier is BenchmarkVerifySingle on a Verifier, which owns its temporaries
+func BenchmarkVerifier(b *testing.B) {
+	f := benchSetup(b)
+	v := NewVerifier()
+	forEachBackend(b, func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if !v.Verify(f.com, f.values[3], f.proofs[3], f.indices[3]) {
+				b.Fatal("valid proof rejected")
+			}
+		}
+	})
+}
+
+func BenchmarkVerifySameCommitment(b *testing.B) {
+	f := benchSetup(b)
+	forEachBackend(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if !verifySameCommitmentAggregation(f.com, f.aggregated, f.values, f.scalars, f.indices, len(f.indices)) {
+				b.Fatal("valid proof rejected")
+			}
+		}
+	})
+}
+
+func BenchmarkVerifyCrossCommitment(b *testing.B) {
+	f := benchSetup(b)
+	forEachBackend(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if !f.cross.verify() {
+				b.Fatal("valid proof rejected")
+			}
+		}
+	})
+}
+
+// BenchmarkCrossCommitmentScaling compares the two ways verifyCrossCommitmentAggregation can apply the
+// commitment scalars t_j to the m = 4 commitments of the fixture: raising com_j to t_j in G1 next to
+// the G2 multi-exponentiation over the t_{j,i}, or folding t_j into the G2 scalars as t_j * t_{j,i}.
+// Folding saves the G1 multiplications but makes the G2 scalars full width even when the t_{j,i} are
+// short, as with 128 bit challenges
+func BenchmarkCrossCommitmentScaling(b *testing.B) {
+	f := benchSetup(b)
+	c := f.cross
+	q := engine.G1.Q()
+	bases := make([][]*bls.PointG2, len(c.Commitments))
+	for j, idx := range c.Indices {
+		for _, i := range idx {
+			bases[j] = append(bases[j], pp2[n-i-1])
+		}
+	}
+	short := make([][]*big.Int, len(c.Commitments))
+	for j := range short {
+		short[j] = generateBigIntegerArray(len(c.Indices[j]), new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+	for _, width := range []struct {
+		name    string
+		scalars [][]*big.Int
+	}{{"short", short}, {"full", c.MessageScalars}} {
+		b.Run(width.name+"/g1", func(b *testing.B) {
+			g := getG1()
+			defer putG1(g)
+			for k := 0; k < b.N; k++ {
+				for j, com := range c.Commitments {
+					mulG1(g, g.New(), com, frFromBig(c.ComScalars[j]))
+					backend.MultiExpG2(bases[j], frVector(width.scalars[j]))
+				}
+			}
+		})
+		b.Run(width.name+"/g2-folded", func(b *testing.B) {
+			for k := 0; k < b.N; k++ {
+				for j := range c.Commitments {
+					folded := make([]*big.Int, len(width.scalars[j]))
+					for i, s := range width.scalars[j] {
+						folded[i] = new(big.Int).Mul(s, c.ComScalars[j])
+						folded[i].Mod(folded[i], q)
+					}
+					backend.MultiExpG2(bases[j], frVector(folded))
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	f := benchSetup(b)
+	openings := make([]Opening, len(f.indices))
+	for k, i := range f.indices {
+		openings[k] = Opening{Index: i, Value: f.values[k], Proof: f.proofs[k]}
+	}
+	forEachBackend(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if !VerifyBatch(openings, f.com) {
+				b.Fatal("valid openings rejected")
+			}
+		}
+	})
+}
+
+func BenchmarkVerifyCrossBatch(b *testing.B) {
+	f := benchSetup(b)
+	transcripts := []*crossTranscript{f.cross, f.cross, f.cross, f.cross}
+	forEachBackend(b, func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if !verifyCrossBatch(transcripts) {
+				b.Fatal("valid transcripts rejected")
+			}
+		}
+	})
+}
+
+// BenchmarkSchemes compares the vector commitment schemes of scheme.go on the same message
+func BenchmarkSchemes(b *testing.B) {
+	f := benchSetup(b)
+	for _, scheme := range []VectorCommitment{PointProofsScheme, MerkleScheme} {
+		com := scheme.Commit(f.message)
+		opening := scheme.Open(f.message, 100)
+		b.Run(scheme.Name()+"/commit", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				scheme.Commit(f.message)
+			}
+		})
+		b.Run(scheme.Name()+"/open", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				scheme.Open(f.message, 100)
+			}
+		})
+		b.Run(scheme.Name()+"/verify", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !scheme.Verify(com, opening) {
+					b.Fatal("valid proof rejected")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkStatelessBlock produces and validates a block of transfers between accounts of two shards,
+// see stateless.go
+func BenchmarkStatelessBlock(b *testing.B) {
+	benchSetup(b)
+	balances := make([]*big.Int, 2*n)
+	for a := range balances {
+		balances[a] = big.NewInt(1000)
+	}
+	coms, wallets := GenesisAccounts(balances)
+	txs := make([]Transaction, 16)
+	for k := range txs {
+		txs[k] = wallets[k*n/8+1].Pay(k*n/8, big.NewInt(int64(k+1)))
+	}
+	block, err := ProduceBlock(coms, txs)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("produce", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := ProduceBlock(coms, txs); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("validate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := NewValidator(coms).ApplyBlock(block); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkUTXO compares the UTXO sets of utxo.go on a transaction spending four outputs
+func BenchmarkUTXO(b *testing.B) {
+	benchSetup(b)
+	const application = "bench-utxo"
+	u, m := NewUTXOSet(application), NewMerkleUTXOSet(application)
+	for k := 0; k < 2*n; k++ {
+		output := []byte(fmt.Sprintf("output %d", k))
+		if _, err := u.Insert(output); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := m.Insert(output); err != nil {
+			b.Fatal(err)
+		}
+	}
+	slots := []int{1, 100, n + 3, 2*n - 1}
+	outputs, proof := u.ProveUnspent(slots)
+	_, paths := m.ProveUnspent(slots)
+	b.Run("pointproofs/prove", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			u.ProveUnspent(slots)
+		}
+	})
+	b.Run("pointproofs/verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if !VerifyUnspent(u.Root(), application, slots, outputs, proof) {
+				b.Fatal("valid proof rejected")
+			}
+		}
+	})
+	b.Run("pointproofs/spend-insert", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = u.Spend(slots[0])
+			_, _ = u.Insert(outputs[0])
+		}
+	})
+	b.Run("merkle/prove", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.ProveUnspent(slots)
+		}
+	})
+	b.Run("merkle/verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if !VerifyMerkleUnspent(m.Root(), application, slots, outputs, paths) {
+				b.Fatal("valid proof rejected")
+			}
+		}
+	})
+	b.Run("merkle/spend-insert", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = m.Spend(slots[0])
+			_, _ = m.Insert(outputs[0])
+		}
+	})
+}
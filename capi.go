@@ -0,0 +1,323 @@
+//go:build capi && cgo
+
+package main
+
+/*
+#include <stddef.h>
+#include <stdint.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"unsafe"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	C API, for applications in C, C++, Python (ctypes, cffi), Rust and everything else linking C:
+		go build -tags capi -buildmode=c-shared -o capi/libpointproofs.so .
+	capi/pointproofs.h declares the functions below with their documentation and capi/harness.c runs
+	through all of them. Memory stays with the caller: the library reads the input buffers during the
+	call only and writes outputs into buffers of the sizes the header gives, it never keeps a pointer.
+	The exception are error messages, allocated with malloc and handed over through the err argument
+	when it is not NULL, which the caller releases with pp_free. Entries are 32 byte big endian integers
+	below the group order, commitments and proofs the artifacts of artifacts.go, PP_ARTIFACT_SIZE bytes
+	each. The library serializes the calls, so they may come from any thread.
+	Mobile light clients bind the verifier-only package PointProofs/mobile with gomobile instead, see
+	mobile/mobile.go. Applications that link C anyway can load a verifier key with pp_load_verifier_key,
+	which leaves pp_verify and pp_verify_aggregated, built with the toolchains of the platforms (NDK,
+	Xcode) as CC:
+		GOOS=android GOARCH=arm64 CGO_ENABLED=1 go build -tags capi -buildmode=c-shared -o libpointproofs.so .
+		GOOS=ios GOARCH=arm64 CGO_ENABLED=1 go build -tags capi -buildmode=c-archive -o libpointproofs.a .
+	the former for JNI on Android, the latter for a Swift bridging header including pointproofs.h.
+*/
+
+// capiArtifactSize is the size of the commitments and proofs crossing the API, PP_ARTIFACT_SIZE
+const capiArtifactSize = 1 + fingerprintSize + g1Size
+
+var (
+	// capiMu serializes the calls, the scheme uses the installed parameters and the global engine
+	capiMu sync.Mutex
+	// capiConfigured is set once configure ran, main does not run in a shared library
+	capiConfigured bool
+	// capiLoaded is set once parameters or a verifier key are installed, capiVerifierOnly while it is a key
+	capiLoaded, capiVerifierOnly bool
+)
+
+// capiCall runs f under capiMu and turns an error or a panic into -1 and the message in *errOut
+func capiCall(errOut **C.char, f func() (C.int, error)) (res C.int) {
+	capiMu.Lock()
+	defer capiMu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			res = capiFail(errOut, fmt.Errorf("%v", r))
+		}
+	}()
+	if !capiConfigured {
+		if err := configure(); err != nil {
+			return capiFail(errOut, err)
+		}
+		capiConfigured = true
+	}
+	res, err := f()
+	if err != nil {
+		return capiFail(errOut, err)
+	}
+	return res
+}
+
+// capiFail hands the message of err to the caller and returns -1
+func capiFail(errOut **C.char, err error) C.int {
+	if errOut != nil {
+		*errOut = C.CString(err.Error())
+	}
+	return -1
+}
+
+// capiRequire fails unless parameters, or a verifier key if verifying, are installed
+func capiRequire(verifying bool) error {
+	switch {
+	case !capiLoaded:
+		return errors.New("load the parameters or a verifier key first")
+	case capiVerifierOnly && !verifying:
+		return errors.New("only verification is possible with a verifier key")
+	}
+	return nil
+}
+
+// capiBytes copies size bytes from p
+func capiBytes(p *C.uint8_t, size int) ([]byte, error) {
+	if p == nil && size > 0 {
+		return nil, errors.New("NULL buffer")
+	}
+	return C.GoBytes(unsafe.Pointer(p), C.int(size)), nil
+}
+
+// capiWrite copies data to the buffer at out
+func capiWrite(out *C.uint8_t, data []byte) error {
+	if out == nil {
+		return errors.New("NULL output buffer")
+	}
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(out)), len(data)), data)
+	return nil
+}
+
+// capiEntries reads count entries from p
+func capiEntries(p *C.uint8_t, count int) ([]*big.Int, error) {
+	data, err := capiBytes(p, count*scalarSize)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*big.Int, count)
+	for k := range entries {
+		entries[k] = new(big.Int).SetBytes(data[k*scalarSize : (k+1)*scalarSize])
+		if entries[k].Cmp(frModulus) >= 0 {
+			return nil, fmt.Errorf("entry %d does not lie in the field", k)
+		}
+	}
+	return entries, nil
+}
+
+// capiIndices reads count distinct indices from p
+func capiIndices(p *C.uint32_t, count int) ([]int, error) {
+	if p == nil && count > 0 {
+		return nil, errors.New("NULL buffer")
+	}
+	raw := unsafe.Slice((*uint32)(unsafe.Pointer(p)), count)
+	indices := make([]int, count)
+	seen := make(map[int]bool, count)
+	for k, i := range raw {
+		if i >= n {
+			return nil, fmt.Errorf("index %d out of range", i)
+		}
+		if seen[int(i)] {
+			return nil, fmt.Errorf("index %d given twice", i)
+		}
+		indices[k] = int(i)
+		seen[int(i)] = true
+	}
+	return indices, nil
+}
+
+// capiPoint decodes the artifact at p with decode
+func capiPoint(p *C.uint8_t, decode func([]byte) (*bls.PointG1, error)) (*bls.PointG1, error) {
+	data, err := capiBytes(p, capiArtifactSize)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+//export pp_n
+func pp_n() C.size_t {
+	return n
+}
+
+//export pp_free
+func pp_free(p unsafe.Pointer) {
+	C.free(p)
+}
+
+//export pp_load_params
+func pp_load_params(data *C.uint8_t, size C.size_t, errOut **C.char) C.int {
+	return capiCall(errOut, func() (C.int, error) {
+		raw, err := capiBytes(data, int(size))
+		if err != nil {
+			return 0, err
+		}
+		pp, err := readPublicParams(bytes.NewReader(raw))
+		if err != nil {
+			return 0, err
+		}
+		pp.install()
+		capiLoaded, capiVerifierOnly = true, false
+		return 0, nil
+	})
+}
+
+//export pp_load_verifier_key
+func pp_load_verifier_key(data *C.uint8_t, size C.size_t, errOut **C.char) C.int {
+	return capiCall(errOut, func() (C.int, error) {
+		raw, err := capiBytes(data, int(size))
+		if err != nil {
+			return 0, err
+		}
+		vk, err := readVerifierKey(bytes.NewReader(raw))
+		if err != nil {
+			return 0, err
+		}
+		vk.install()
+		capiLoaded, capiVerifierOnly = true, true
+		return 0, nil
+	})
+}
+
+//export pp_commit
+func pp_commit(entries *C.uint8_t, commitment *C.uint8_t, errOut **C.char) C.int {
+	return capiCall(errOut, func() (C.int, error) {
+		if err := capiRequire(false); err != nil {
+			return 0, err
+		}
+		vector, err := capiEntries(entries, n)
+		if err != nil {
+			return 0, err
+		}
+		return 0, capiWrite(commitment, encodeCommitment(commit(vector)))
+	})
+}
+
+//export pp_prove
+func pp_prove(entries *C.uint8_t, index C.uint32_t, proof *C.uint8_t, errOut **C.char) C.int {
+	return capiCall(errOut, func() (C.int, error) {
+		if err := capiRequire(false); err != nil {
+			return 0, err
+		}
+		vector, err := capiEntries(entries, n)
+		if err != nil {
+			return 0, err
+		}
+		if index >= n {
+			return 0, fmt.Errorf("index %d out of range", index)
+		}
+		return 0, capiWrite(proof, encodeProof(ProveSet(vector, []int{int(index)})[0]))
+	})
+}
+
+//export pp_aggregate
+func pp_aggregate(commitment *C.uint8_t, indices *C.uint32_t, values *C.uint8_t, proofs *C.uint8_t, count C.size_t, aggregated *C.uint8_t, errOut **C.char) C.int {
+	return capiCall(errOut, func() (C.int, error) {
+		if err := capiRequire(false); err != nil {
+			return 0, err
+		}
+		com, err := capiPoint(commitment, decodeCommitment)
+		if err != nil {
+			return 0, err
+		}
+		is, err := capiIndices(indices, int(count))
+		if err != nil {
+			return 0, err
+		}
+		vs, err := capiEntries(values, int(count))
+		if err != nil {
+			return 0, err
+		}
+		raw, err := capiBytes(proofs, int(count)*capiArtifactSize)
+		if err != nil {
+			return 0, err
+		}
+		openings := make([]Opening, count)
+		for k := range openings {
+			proof, err := decodeProof(raw[k*capiArtifactSize : (k+1)*capiArtifactSize])
+			if err != nil {
+				return 0, fmt.Errorf("proof %d: %w", k, err)
+			}
+			openings[k] = Opening{Index: is[k], Value: vs[k], Proof: proof}
+		}
+		return 0, capiWrite(aggregated, encodeAggregatedProof(AggregateSameCommitment(com, openings)))
+	})
+}
+
+//export pp_verify
+func pp_verify(commitment *C.uint8_t, index C.uint32_t, value *C.uint8_t, proof *C.uint8_t, errOut **C.char) C.int {
+	return capiCall(errOut, func() (C.int, error) {
+		if err := capiRequire(true); err != nil {
+			return 0, err
+		}
+		com, err := capiPoint(commitment, decodeCommitment)
+		if err != nil {
+			return 0, err
+		}
+		is, err := capiIndices(&index, 1)
+		if err != nil {
+			return 0, err
+		}
+		vs, err := capiEntries(value, 1)
+		if err != nil {
+			return 0, err
+		}
+		p, err := capiPoint(proof, decodeProof)
+		if err != nil {
+			return 0, err
+		}
+		if verifySingleProof(com, vs[0], p, is[0]) {
+			return 1, nil
+		}
+		return 0, nil
+	})
+}
+
+//export pp_verify_aggregated
+func pp_verify_aggregated(commitment *C.uint8_t, indices *C.uint32_t, values *C.uint8_t, count C.size_t, aggregated *C.uint8_t, errOut **C.char) C.int {
+	return capiCall(errOut, func() (C.int, error) {
+		if err := capiRequire(true); err != nil {
+			return 0, err
+		}
+		com, err := capiPoint(commitment, decodeCommitment)
+		if err != nil {
+			return 0, err
+		}
+		is, err := capiIndices(indices, int(count))
+		if err != nil {
+			return 0, err
+		}
+		vs, err := capiEntries(values, int(count))
+		if err != nil {
+			return 0, err
+		}
+		p, err := capiPoint(aggregated, decodeAggregatedProof)
+		if err != nil {
+			return 0, err
+		}
+		if VerifySameCommitment(com, is, vs, p) {
+			return 1, nil
+		}
+		return 0, nil
+	})
+}
@@ -0,0 +1,111 @@
+/*
+ * Test harness of the C API, run against the parameters of "PointProofs setup <params>":
+ *     go build -tags capi -buildmode=c-shared -o capi/libpointproofs.so .
+ *     cc -o capi/harness capi/harness.c -Icapi -Lcapi -lpointproofs
+ *     LD_LIBRARY_PATH=capi capi/harness <params>
+ * It exits with 0 when every check passes.
+ */
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include "pointproofs.h"
+
+static int failures = 0;
+
+static void check(int ok, const char *what) {
+    printf("%s %s\n", ok ? "ok  " : "FAIL", what);
+    if (!ok) {
+        failures++;
+    }
+}
+
+/* checkError expects a failed call and releases its message, resetting *err */
+static void checkError(int res, char **err, const char *what) {
+    check(res == -1 && *err != NULL, what);
+    if (*err != NULL) {
+        printf("     %s\n", *err);
+        pp_free(*err);
+        *err = NULL;
+    }
+}
+
+static uint8_t *readFile(const char *path, size_t *size) {
+    FILE *f = fopen(path, "rb");
+    if (f == NULL) {
+        return NULL;
+    }
+    fseek(f, 0, SEEK_END);
+    *size = (size_t)ftell(f);
+    fseek(f, 0, SEEK_SET);
+    uint8_t *data = malloc(*size);
+    if (data != NULL && fread(data, 1, *size, f) != *size) {
+        free(data);
+        data = NULL;
+    }
+    fclose(f);
+    return data;
+}
+
+/* setEntry writes value as a big endian entry */
+static void setEntry(uint8_t *entry, uint32_t value) {
+    memset(entry, 0, PP_ENTRY_SIZE);
+    for (int k = 0; k < 4; k++) {
+        entry[PP_ENTRY_SIZE - 1 - k] = (uint8_t)(value >> (8 * k));
+    }
+}
+
+int main(int argc, char **argv) {
+    if (argc != 2) {
+        fprintf(stderr, "usage: %s <params>\n", argv[0]);
+        return 2;
+    }
+    size_t size;
+    uint8_t *params = readFile(argv[1], &size);
+    if (params == NULL) {
+        fprintf(stderr, "cannot read %s\n", argv[1]);
+        return 2;
+    }
+    char *err = NULL;
+    size_t n = pp_n();
+    uint8_t *entries = malloc(n * PP_ENTRY_SIZE);
+    for (size_t i = 0; i < n; i++) {
+        setEntry(entries + i * PP_ENTRY_SIZE, (uint32_t)(i * i + 1));
+    }
+    uint8_t commitment[PP_ARTIFACT_SIZE], proofs[2 * PP_ARTIFACT_SIZE], aggregated[PP_ARTIFACT_SIZE];
+    uint32_t indices[2] = {3, (uint32_t)(n - 1)};
+    uint8_t values[2 * PP_ENTRY_SIZE], wrong[PP_ENTRY_SIZE];
+    for (int k = 0; k < 2; k++) {
+        memcpy(values + k * PP_ENTRY_SIZE, entries + indices[k] * PP_ENTRY_SIZE, PP_ENTRY_SIZE);
+    }
+    setEntry(wrong, 12345);
+
+    checkError(pp_commit(entries, commitment, &err), &err, "commit before loading parameters");
+    check(pp_load_params(params, size, &err) == 0, "load parameters");
+    check(pp_commit(entries, commitment, &err) == 0, "commit");
+    for (int k = 0; k < 2; k++) {
+        check(pp_prove(entries, indices[k], proofs + k * PP_ARTIFACT_SIZE, &err) == 0, "prove");
+        check(pp_verify(commitment, indices[k], values + k * PP_ENTRY_SIZE, proofs + k * PP_ARTIFACT_SIZE, &err) == 1,
+              "verify");
+    }
+    check(pp_verify(commitment, indices[0], wrong, proofs, &err) == 0, "reject a wrong value");
+    check(pp_verify(commitment, indices[1], values, proofs, &err) == 0, "reject a wrong index");
+    check(pp_aggregate(commitment, indices, values, proofs, 2, aggregated, &err) == 0, "aggregate");
+    check(pp_verify_aggregated(commitment, indices, values, 2, aggregated, &err) == 1, "verify aggregated");
+    check(pp_verify_aggregated(commitment, indices, values, 1, aggregated, &err) == 0, "reject a partial aggregate");
+    checkError(pp_prove(entries, (uint32_t)n, proofs, &err), &err, "reject an index out of range");
+    uint8_t corrupt[PP_ARTIFACT_SIZE];
+    memcpy(corrupt, proofs, PP_ARTIFACT_SIZE);
+    corrupt[0] ^= 0xff;
+    checkError(pp_verify(commitment, indices[0], values, corrupt, &err), &err, "reject a malformed proof");
+    checkError(pp_load_params(params, size / 2, &err), &err, "reject truncated parameters");
+
+    free(entries);
+    free(params);
+    if (failures > 0) {
+        printf("%d checks failed\n", failures);
+        return 1;
+    }
+    printf("all checks passed\n");
+    return 0;
+}
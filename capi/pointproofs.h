@@ -0,0 +1,68 @@
+/*
+ * C API of PointProofs, implemented by capi.go. Build the library with
+ *     go build -tags capi -buildmode=c-shared -o capi/libpointproofs.so .
+ * and link against it, e.g. cc app.c -Icapi -Lcapi -lpointproofs.
+ *
+ * Memory: every buffer belongs to the caller. Inputs are only read during the call, outputs are written
+ * into buffers of the sizes given below and the library keeps no pointer after returning. Error messages
+ * are the one exception: when err is not NULL and a call fails, *err is set to a NUL terminated string
+ * allocated by the library, which the caller releases with pp_free.
+ *
+ * Encodings: an entry is PP_ENTRY_SIZE bytes, a big endian integer below the group order of BLS12-381,
+ * a vector is pp_n() entries back to back. Commitments, proofs and aggregated proofs are the artifacts of
+ * artifacts.go, PP_ARTIFACT_SIZE bytes each, bound to the parameters that produced them.
+ *
+ * Results: 0 on success and -1 on error, the verifications return 1 for a valid proof and 0 for an invalid
+ * one. Calls are serialized by the library and may come from any thread. Loading parameters or a verifier
+ * key replaces what was loaded before, with a verifier key only the verifications are possible.
+ */
+#ifndef POINTPROOFS_H
+#define POINTPROOFS_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+#define PP_ENTRY_SIZE 32
+#define PP_ARTIFACT_SIZE 105
+
+/* pp_n returns the length of the vectors */
+size_t pp_n(void);
+
+/* pp_free releases an error message */
+void pp_free(void *p);
+
+/* pp_load_params installs the public parameters, the bytes of a file written by "PointProofs setup" */
+int pp_load_params(const uint8_t *data, size_t size, char **err);
+
+/* pp_load_verifier_key installs a verifier key, the bytes of a file written by "PointProofs params verifier-key" */
+int pp_load_verifier_key(const uint8_t *data, size_t size, char **err);
+
+/* pp_commit writes the commitment to the vector entries into commitment[PP_ARTIFACT_SIZE] */
+int pp_commit(const uint8_t *entries, uint8_t *commitment, char **err);
+
+/* pp_prove writes the proof of the entry at index of the vector entries into proof[PP_ARTIFACT_SIZE] */
+int pp_prove(const uint8_t *entries, uint32_t index, uint8_t *proof, char **err);
+
+/*
+ * pp_aggregate aggregates the proofs of count distinct entries under the commitment, indices[count],
+ * values[count * PP_ENTRY_SIZE] and proofs[count * PP_ARTIFACT_SIZE], into aggregated[PP_ARTIFACT_SIZE]
+ */
+int pp_aggregate(const uint8_t *commitment, const uint32_t *indices, const uint8_t *values, const uint8_t *proofs,
+                 size_t count, uint8_t *aggregated, char **err);
+
+/* pp_verify checks the proof that the entry at index of the committed vector is value[PP_ENTRY_SIZE] */
+int pp_verify(const uint8_t *commitment, uint32_t index, const uint8_t *value, const uint8_t *proof, char **err);
+
+/* pp_verify_aggregated checks an aggregated proof of count entries, laid out as for pp_aggregate */
+int pp_verify_aggregated(const uint8_t *commitment, const uint32_t *indices, const uint8_t *values, size_t count,
+                         const uint8_t *aggregated, char **err);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+/*
+	Content-addressed export of large artifacts, for distribution over IPFS and similar networks. A file,
+	here the parameters or a proof set, is cut into chunks of carChunkSize bytes stored as raw blocks,
+	and a DAG-CBOR manifest {"size": file size, "chunks": [CID, ...]} lists them in order. Every block is
+	named by its CIDv1 with a SHA-256 multihash, so the CID of the manifest, the root, pins the whole
+	file: whoever holds the root accepts the blocks from anybody and gets the file or an error. The
+	blocks are written as a CARv1 file, root first, which "ipfs dag import" takes as it is and IPFS
+	gateways serve back at /ipfs/<root>?format=car. CIDs are written in base32, the default of CIDv1.
+	carFetcher loads a file from such gateways, tried in order, and checks it against the root.
+	Only CIDs of this form are accepted when reading, which covers every CAR this file writes and
+	those gateways return for its roots.
+*/
+
+const (
+	// carChunkSize is the size of the chunks of a file, the default of the IPFS chunker
+	carChunkSize = 256 << 10
+	// carMaxBlock bounds the blocks read, the limit IPFS puts on blocks
+	carMaxBlock = 1 << 20
+	// carMaxHeader bounds the CAR header, which holds a single root
+	carMaxHeader = 1 << 10
+
+	cidCodecRaw     = 0x55
+	cidCodecDagCBOR = 0x71
+	multihashSHA256 = 0x12
+	// cidSize is the size of the CIDs above, version, codec, hash function and digest length take a byte each
+	cidSize = 4 + sha256.Size
+	// cborTagCID is the tag of CIDs in DAG-CBOR
+	cborTagCID = 42
+)
+
+// cidEncoding is the lower case base32 of multibase prefix "b"
+var cidEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// carEncMode encodes maps with their keys sorted as DAG-CBOR requires
+var carEncMode, _ = cbor.CoreDetEncOptions().EncMode()
+
+// carManifest is the root block of a file
+type carManifest struct {
+	Size   uint64     `cbor:"size"`
+	Chunks []cbor.Tag `cbor:"chunks"`
+}
+
+// carHeader is the header of a CARv1 file
+type carHeader struct {
+	Roots   []cbor.Tag `cbor:"roots"`
+	Version uint64     `cbor:"version"`
+}
+
+// newCID returns the CID of data under the codec
+func newCID(codec byte, data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return append([]byte{1, codec, multihashSHA256, sha256.Size}, sum[:]...)
+}
+
+// cidString returns the base32 form of a CID
+func cidString(cid []byte) string {
+	return "b" + cidEncoding.EncodeToString(cid)
+}
+
+// checkCID fails unless cid is a CID of the form above
+func checkCID(cid []byte) error {
+	if len(cid) != cidSize || cid[0] != 1 || cid[2] != multihashSHA256 || cid[3] != sha256.Size {
+		return errors.New("unsupported CID, expected a CIDv1 with a SHA-256 multihash")
+	}
+	if cid[1] != cidCodecRaw && cid[1] != cidCodecDagCBOR {
+		return fmt.Errorf("unsupported codec 0x%x", cid[1])
+	}
+	return nil
+}
+
+// parseCID parses the base32 form of a CID
+func parseCID(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "b") {
+		return nil, errors.New("expected a base32 CID starting with b")
+	}
+	cid, err := cidEncoding.DecodeString(s[1:])
+	if err != nil {
+		return nil, fmt.Errorf("malformed CID: %w", err)
+	}
+	return cid, checkCID(cid)
+}
+
+// cidTag returns a CID as DAG-CBOR encodes it, the tag over the identity multibase prefix and the CID
+func cidTag(cid []byte) cbor.Tag {
+	return cbor.Tag{Number: cborTagCID, Content: append([]byte{0}, cid...)}
+}
+
+// tagCID returns the CID of a tag written by cidTag
+func tagCID(t cbor.Tag) ([]byte, error) {
+	content, ok := t.Content.([]byte)
+	if t.Number != cborTagCID || !ok || len(content) == 0 || content[0] != 0 {
+		return nil, errors.New("malformed CID link")
+	}
+	return content[1:], checkCID(content[1:])
+}
+
+// writeCARSection writes a block
+func writeCARSection(w io.Writer, data ...[]byte) error {
+	size := 0
+	for _, d := range data {
+		size += len(d)
+	}
+	var prefix [binary.MaxVarintLen64]byte
+	if _, err := w.Write(prefix[:binary.PutUvarint(prefix[:], uint64(size))]); err != nil {
+		return err
+	}
+	for _, d := range data {
+		if _, err := w.Write(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCAR writes data as a CARv1 file of the blocks described above and returns the root
+func WriteCAR(w io.Writer, data []byte) (string, error) {
+	manifest := carManifest{Size: uint64(len(data))}
+	var chunks [][]byte
+	for start := 0; start < len(data); start += carChunkSize {
+		end := start + carChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunks = append(chunks, data[start:end])
+		manifest.Chunks = append(manifest.Chunks, cidTag(newCID(cidCodecRaw, data[start:end])))
+	}
+	if manifest.Chunks == nil {
+		manifest.Chunks = []cbor.Tag{}
+	}
+	root, err := carEncMode.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	rootCID := newCID(cidCodecDagCBOR, root)
+	header, err := carEncMode.Marshal(carHeader{Roots: []cbor.Tag{cidTag(rootCID)}, Version: 1})
+	if err != nil {
+		return "", err
+	}
+	bw := bufio.NewWriter(w)
+	if err := writeCARSection(bw, header); err != nil {
+		return "", err
+	}
+	if err := writeCARSection(bw, rootCID, root); err != nil {
+		return "", err
+	}
+	for k, chunk := range chunks {
+		cid, _ := tagCID(manifest.Chunks[k])
+		if err := writeCARSection(bw, cid, chunk); err != nil {
+			return "", err
+		}
+	}
+	return cidString(rootCID), bw.Flush()
+}
+
+// carMaxSize bounds the size of the CAR file of a file of maxSize bytes, whose blocks come with a CID
+// and its manifest takes less than 64 bytes per chunk
+func carMaxSize(maxSize int) int {
+	return maxSize + (maxSize/carChunkSize+2)*(cidSize+64)*2 + carMaxHeader
+}
+
+// readCARSection reads a block of at most maxSize bytes, returning io.EOF at the end of the file
+func readCARSection(br *bufio.Reader, maxSize int) ([]byte, error) {
+	size, err := binary.ReadUvarint(br)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(maxSize) {
+		return nil, fmt.Errorf("section of %d bytes exceeds %d", size, maxSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return nil, fmt.Errorf("truncated section: %w", err)
+	}
+	return data, nil
+}
+
+// ReadCAR reads a CARv1 file written by WriteCAR, checks every block against its CID and returns the
+// file of the root, which may be at most maxSize bytes
+func ReadCAR(r io.Reader, root string, maxSize int) ([]byte, error) {
+	rootCID, err := parseCID(root)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(io.LimitReader(r, int64(carMaxSize(maxSize))))
+	raw, err := readCARSection(br, carMaxHeader)
+	if err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	var header carHeader
+	if err := cbor.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("header: %w", err)
+	}
+	if header.Version != 1 {
+		return nil, fmt.Errorf("unsupported CAR version %d", header.Version)
+	}
+	blocks := make(map[string][]byte)
+	for {
+		section, err := readCARSection(br, cidSize+carMaxBlock)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(section) < cidSize {
+			return nil, errors.New("truncated block")
+		}
+		cid, data := section[:cidSize], section[cidSize:]
+		if err := checkCID(cid); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(newCID(cid[1], data), cid) {
+			return nil, fmt.Errorf("block %s does not match its CID", cidString(cid))
+		}
+		blocks[string(cid)] = data
+	}
+	raw, ok := blocks[string(rootCID)]
+	if !ok || rootCID[1] != cidCodecDagCBOR {
+		return nil, fmt.Errorf("no manifest %s in the file", root)
+	}
+	var manifest carManifest
+	if err := cbor.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	if manifest.Size > uint64(maxSize) {
+		return nil, fmt.Errorf("file of %d bytes exceeds %d", manifest.Size, maxSize)
+	}
+	data := make([]byte, 0, manifest.Size)
+	for k, link := range manifest.Chunks {
+		cid, err := tagCID(link)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d: %w", k, err)
+		}
+		chunk, ok := blocks[string(cid)]
+		if !ok || cid[1] != cidCodecRaw {
+			return nil, fmt.Errorf("chunk %d: no raw block %s in the file", k, cidString(cid))
+		}
+		if uint64(len(data)+len(chunk)) > manifest.Size {
+			return nil, errors.New("chunks exceed the size of the file")
+		}
+		data = append(data, chunk...)
+	}
+	if uint64(len(data)) != manifest.Size {
+		return nil, errors.New("chunks fall short of the size of the file")
+	}
+	return data, nil
+}
+
+// WriteCAR writes the parameters as a CAR file, see WriteCAR, and returns the root
+func (pp *PublicParams) WriteCAR(w io.Writer) (string, error) {
+	return WriteCAR(w, pp.marshal())
+}
+
+// WriteCAR writes the proof set as a CAR file, see WriteCAR, and returns the root
+func (s ProofSet) WriteCAR(w io.Writer) (string, error) {
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		return "", err
+	}
+	return WriteCAR(w, buf.Bytes())
+}
+
+// proofSetSize is the size of a proof set written by ProofSet.Write
+const proofSetSize = 1 + fingerprintSize + n*g1CompressedSize
+
+// readParamsCAR reads the parameters of the root from a CAR file and checks they are well-formed
+func readParamsCAR(r io.Reader, root string) (*PublicParams, error) {
+	data, err := ReadCAR(r, root, paramsMaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+	return parseFetchedParams(data)
+}
+
+// ReadProofSetCAR reads the proof set of the root from a CAR file, see ReadProofSet
+func ReadProofSetCAR(r io.Reader, root string) (ProofSet, error) {
+	data, err := ReadCAR(r, root, proofSetSize)
+	if err != nil {
+		return nil, err
+	}
+	return ReadProofSet(bytes.NewReader(data))
+}
+
+// carFetcher loads files from IPFS gateways, checking them against their roots
+type carFetcher struct {
+	// gateways tried in order until one serves the file, e.g. https://ipfs.io
+	Gateways []string
+	// defaults to a client with a one minute timeout
+	Client *http.Client
+}
+
+// fetch returns the file of the root, of at most maxSize bytes, from the first gateway serving it
+func (f *carFetcher) fetch(root string, maxSize int, parse func([]byte) error) error {
+	if _, err := parseCID(root); err != nil {
+		return err
+	}
+	if len(f.Gateways) == 0 {
+		return errors.New("no gateways configured")
+	}
+	var errs []string
+	for _, gateway := range f.Gateways {
+		u := strings.TrimSuffix(gateway, "/") + "/ipfs/" + url.PathEscape(root) + "?format=car"
+		car, err := httpDownload(f.Client, u, carMaxSize(maxSize))
+		if err == nil {
+			var data []byte
+			if data, err = ReadCAR(bytes.NewReader(car), root, maxSize); err == nil {
+				if err = parse(data); err == nil {
+					return nil
+				}
+			}
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", gateway, err))
+	}
+	return fmt.Errorf("no gateway served %s:\n\t%s", root, strings.Join(errs, "\n\t"))
+}
+
+// fetchParams loads the parameters of the root and checks they are well-formed
+func (f *carFetcher) fetchParams(root string) (*PublicParams, error) {
+	var pp *PublicParams
+	err := f.fetch(root, paramsMaxFileSize, func(data []byte) (err error) {
+		pp, err = parseFetchedParams(data)
+		return err
+	})
+	return pp, err
+}
+
+// fetchProofSet loads the proof set of the root, which has to be bound to the installed parameters
+func (f *carFetcher) fetchProofSet(root string) (ProofSet, error) {
+	var s ProofSet
+	err := f.fetch(root, proofSetSize, func(data []byte) (err error) {
+		s, err = ReadProofSet(bytes.NewReader(data))
+		return err
+	})
+	return s, err
+}
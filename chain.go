@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Hash-linked chains of commitments. Block h holds the commitment C_h to the message at height h and
+	the hash of block h - 1 (all zeros for h = 0), and its hash is
+		SHA-256("PointProofs-chain-link-v1" || h as uint64 || prev || C_h)
+	with C_h in the uncompressed encoding of writeG1. The head, the hash of the last block, then fixes
+	every commitment of the chain. That entry i was v at height h is proven by the links from h to the
+	head, which rebuild the head from C_h, and the proof of the opening of C_h. The proof grows with the
+	distance to the head, one link of 8 + 32 + 48 bytes serialized per block.
+	A history proof is serialized as an artifact of kind 9: the header of artifacts.go, the number of
+	links as uint32, every link as height || prev || compressed C_h, and the compressed opening proof.
+*/
+
+// chainLinkDomain prefixes the hash of every link
+const chainLinkDomain = "PointProofs-chain-link-v1"
+
+// ChainLink is a block of a chain
+type ChainLink struct {
+	Height     int
+	Prev       [sha256.Size]byte
+	Commitment *bls.PointG1
+}
+
+// Hash returns the hash of the link, which the next link refers to
+func (l *ChainLink) Hash() [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(chainLinkDomain))
+	// writing into a hash cannot fail
+	_ = binary.Write(h, binary.BigEndian, uint64(l.Height))
+	h.Write(l.Prev[:])
+	_ = writeG1(h, l.Commitment)
+	var res [sha256.Size]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
+// Chain is a hash-linked chain of commitments with the messages behind them, it is not safe for
+// concurrent use
+type Chain struct {
+	links    []ChainLink
+	messages [][]*big.Int
+}
+
+// NewChain returns the empty chain
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+// Height returns the number of blocks of the chain
+func (c *Chain) Height() int {
+	return len(c.links)
+}
+
+// Head returns the hash of the last block, all zeros for the empty chain
+func (c *Chain) Head() [sha256.Size]byte {
+	if len(c.links) == 0 {
+		return [sha256.Size]byte{}
+	}
+	return c.links[len(c.links)-1].Hash()
+}
+
+// Append commits to the message as the next block and returns the new head
+func (c *Chain) Append(message []*big.Int) [sha256.Size]byte {
+	checkVector(message)
+	link := ChainLink{Height: len(c.links), Prev: c.Head(), Commitment: commit(message)}
+	c.links = append(c.links, link)
+	c.messages = append(c.messages, append([]*big.Int{}, message...))
+	return link.Hash()
+}
+
+// HistoryProof proves an entry of the message at some height of a chain
+type HistoryProof struct {
+	// Links are the blocks from the height to the head
+	Links []ChainLink
+	Proof *bls.PointG1
+}
+
+// ProveHistory returns the entry at the index of the message at the height and the proof of it, checked
+// by VerifyHistory against the current head
+func (c *Chain) ProveHistory(height, index int) (*big.Int, *HistoryProof) {
+	if !(0 <= height && height < len(c.links)) {
+		panic("height out of range")
+	}
+	proof := &HistoryProof{Links: make([]ChainLink, 0, len(c.links)-height)}
+	for _, l := range c.links[height:] {
+		proof.Links = append(proof.Links, ChainLink{Height: l.Height, Prev: l.Prev, Commitment: new(bls.PointG1).Set(l.Commitment)})
+	}
+	proof.Proof = ProveSet(c.messages[height], []int{index})[0]
+	return new(big.Int).Set(c.messages[height][index]), proof
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the head of the chain
+		4. the height
+		5. the index
+		6. the value claimed for the index at the height
+		7. the proof of ProveHistory
+	It reports whether the proof shows the message at the height had the value at the index.
+*/
+func VerifyHistory(head [sha256.Size]byte, height, index int, value *big.Int, proof *HistoryProof) bool {
+	if len(proof.Links) == 0 || proof.Links[0].Height != height {
+		return false
+	}
+	for k := 1; k < len(proof.Links); k++ {
+		if proof.Links[k].Height != proof.Links[k-1].Height+1 || proof.Links[k].Prev != proof.Links[k-1].Hash() {
+			return false
+		}
+	}
+	if proof.Links[len(proof.Links)-1].Hash() != head {
+		return false
+	}
+	return verifySingleProof(proof.Links[0].Commitment, value, proof.Proof, index)
+}
+
+// encodeHistoryProof serializes a history proof
+func encodeHistoryProof(proof *HistoryProof) []byte {
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer cannot fail
+	_ = writeArtifactHeader(&buf, artifactHistoryProof)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(proof.Links)))
+	for _, l := range proof.Links {
+		if l.Height < 0 {
+			panic("negative height")
+		}
+		_ = binary.Write(&buf, binary.BigEndian, uint64(l.Height))
+		buf.Write(l.Prev[:])
+		_ = writeCompressedG1(&buf, l.Commitment)
+	}
+	_ = writeCompressedG1(&buf, proof.Proof)
+	return buf.Bytes()
+}
+
+// decodeHistoryProof parses a history proof produced under the installed parameters
+func decodeHistoryProof(data []byte) (*HistoryProof, error) {
+	r := bytes.NewReader(data)
+	if err := readArtifactHeader(r, artifactHistoryProof); err != nil {
+		return nil, err
+	}
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	linkSize := int64(8 + sha256.Size + g1CompressedSize)
+	if int64(count)*linkSize+g1CompressedSize != int64(r.Len()) {
+		return nil, fmt.Errorf("expected %d links, got %d bytes", count, r.Len())
+	}
+	proof := &HistoryProof{Links: make([]ChainLink, count)}
+	for k := range proof.Links {
+		var height uint64
+		// the length was checked above
+		_ = binary.Read(r, binary.BigEndian, &height)
+		if height > 1<<62 {
+			return nil, fmt.Errorf("link %d: height %d out of range", k, height)
+		}
+		proof.Links[k].Height = int(height)
+		_, _ = r.Read(proof.Links[k].Prev[:])
+		com, err := readCompressedG1(r)
+		if err != nil {
+			return nil, fmt.Errorf("link %d: %w", k, err)
+		}
+		proof.Links[k].Commitment = com
+	}
+	p, err := readCompressedG1(r)
+	if err != nil {
+		return nil, fmt.Errorf("proof: %w", err)
+	}
+	proof.Proof = p
+	return proof, nil
+}
@@ -0,0 +1,96 @@
+//go:build gnark
+
+package main
+
+import (
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std/algebra/algopts"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_emulated"
+	"github.com/consensys/gnark/std/math/emulated"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	A gnark gadget verifying an opening inside a circuit, compiled in only with the gnark build tag (go
+	build -tags gnark) as it pulls in gnark. BLS12-381 arithmetic is emulated, so the gadget works in a
+	circuit over any curve, e.g. BN254 for proofs checked on Ethereum. It enforces the equation of
+	verifySingleProof with the target moved into a third pairing,
+		e(C, g_2^{alpha^{n+1-i}}) * e(-pi, g_2) * e(-m * g_1^{alpha}, g_2^{alpha^n}) = 1
+	The index and the parameters are constants of the circuit, the commitment, the entry and the proof
+	are variables, so a circuit can prove statements about an entry while keeping it private. Neither the
+	commitment nor the proof may be the point at infinity, which the emulated pairing cannot handle.
+*/
+
+// OpeningGadget holds the constants of the circuits verifying openings of one index
+type OpeningGadget struct {
+	index int
+	base  sw_bls12381.G2Affine
+	g2    sw_bls12381.G2Affine
+	g1    sw_bls12381.G1Affine
+	top   sw_bls12381.G2Affine
+}
+
+// NewOpeningGadget returns the gadget for openings of the index under the installed parameters
+func NewOpeningGadget(index int) *OpeningGadget {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	return &OpeningGadget{
+		index: index,
+		base:  sw_bls12381.NewG2Affine(toGnarkG2s([]*bls.PointG2{pp2[n-index-1]})[0]),
+		g2:    sw_bls12381.NewG2Affine(toGnarkG2s([]*bls.PointG2{g2Generator})[0]),
+		g1:    sw_bls12381.NewG1Affine(toGnarkG1s([]*bls.PointG1{pp1Point(0)})[0]),
+		top:   sw_bls12381.NewG2Affine(toGnarkG2s([]*bls.PointG2{pp2[n-1]})[0]),
+	}
+}
+
+/*
+	It takes the following arguments:
+		1. the API of the circuit being defined
+		2. the commitment as a circuit variable
+		3. the entry m_i as a circuit variable
+		4. the proof as a circuit variable
+	It adds the constraints of the verification of the opening of the gadget's index to the circuit.
+*/
+func (og *OpeningGadget) AssertOpening(api frontend.API, com *sw_bls12381.G1Affine, value *sw_bls12381.Scalar, proof *sw_bls12381.G1Affine) error {
+	curve, err := sw_emulated.New[emulated.BLS12381Fp, emulated.BLS12381Fr](api, sw_emulated.GetBLS12381Params())
+	if err != nil {
+		return err
+	}
+	pairing, err := sw_bls12381.NewPairing(api)
+	if err != nil {
+		return err
+	}
+	// m may be zero, which the incomplete formulas of ScalarMul do not allow for
+	target := curve.Neg(curve.ScalarMul(&og.g1, value, algopts.WithCompleteArithmetic()))
+	return pairing.PairingCheck(
+		[]*sw_bls12381.G1Affine{com, curve.Neg(proof), target},
+		[]*sw_bls12381.G2Affine{&og.base, &og.g2, &og.top},
+	)
+}
+
+// OpeningCircuit is the circuit verifying a single opening with public commitment and entry, as the
+// smallest use of OpeningGadget. Its Gadget has to be set when compiling it
+type OpeningCircuit struct {
+	Commitment sw_bls12381.G1Affine `gnark:",public"`
+	Value      sw_bls12381.Scalar   `gnark:",public"`
+	Proof      sw_bls12381.G1Affine
+	Gadget     *OpeningGadget `gnark:"-"`
+}
+
+// Define implements frontend.Circuit
+func (c *OpeningCircuit) Define(api frontend.API) error {
+	return c.Gadget.AssertOpening(api, &c.Commitment, &c.Value, &c.Proof)
+}
+
+// NewOpeningAssignment returns the witness of OpeningCircuit for an opening
+func NewOpeningAssignment(com *bls.PointG1, value fr, proof *bls.PointG1) *OpeningCircuit {
+	points := toGnarkG1s([]*bls.PointG1{com, proof})
+	return &OpeningCircuit{
+		Commitment: sw_bls12381.NewG1Affine(points[0]),
+		Value:      sw_bls12381.NewScalar(toGnarkScalars([]fr{value})[0]),
+		Proof:      sw_bls12381.NewG1Affine(points[1]),
+	}
+}
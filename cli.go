@@ -0,0 +1,533 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+const cliUsage = `usage:
+	PointProofs                        run the demo
+	PointProofs backends               list the curve backends compiled in
+	PointProofs params verify <file>   check a parameter file and report every problem found
+	PointProofs params verifier-key <params> <key>
+	                                   write the verifier key of the parameters, see verifierkey.go
+	PointProofs setup [-curve <curve>] <params>
+	                                   run a trusted setup on bls12-381 (the default) or bls12-377 and
+	                                   write the parameters
+	PointProofs commit <params> <vector> <commitment>
+	                                   commit to a vector and write the commitment
+	PointProofs prove <params> <vector> <index> <proof>
+	                                   write the proof of an entry and print the entry
+	PointProofs aggregate <params> <commitment> <aggregated> <index>:<value>:<proof>...
+	                                   aggregate proofs of entries of one commitment
+	PointProofs verify <params> <commitment> <proof> <index>:<value>...
+	                                   check a proof or an aggregated proof of the entries
+	PointProofs serve <params> <address>
+	                                   serve the HTTP/JSON API of server.go and its metrics at the address
+	PointProofs grpc <params> <address>
+	                                   serve the gRPC service of grpcserver.go at the address
+	PointProofs solidity <params> <contract>
+	                                   write a Solidity verifier for the parameters, see solidity.go
+	PointProofs car params <params> <car>
+	                                   export the parameters as a CAR file and print its root, see car.go
+	PointProofs car proofs <params> <vector> <car>
+	                                   export all proofs of a vector as a CAR file and print its root
+	PointProofs car extract <car> <root> <out>
+	                                   check a CAR file against its root and write the file it holds
+
+vectors hold n entries, as decimal or 0x prefixed hexadecimal numbers separated by commas or
+newlines in .csv files, as a JSON array of such strings or numbers in .json files, and as n big
+endian 32 byte integers in any other file. Commitments and proofs are written as artifacts bound
+to the parameters, see artifacts.go. commit, prove, aggregate and verify also run on bls12-377
+parameters, the other commands only on bls12-381 ones, see clicurve.go.
+
+environment:
+	POINTPROOFS_BACKEND                curve backend, see "PointProofs backends"
+	POINTPROOFS_PROFILE_DIR            write CPU and heap profiles of every heavy operation there
+`
+
+// runCLI dispatches the command line arguments (without the program name) and returns the exit code
+func runCLI(args []string, stdout io.Writer, stderr io.Writer) int {
+	switch {
+	case len(args) == 3 && args[0] == "params" && args[1] == "verify":
+		return paramsVerifyCommand(args[2], stdout, stderr)
+	case len(args) == 4 && args[0] == "params" && args[1] == "verifier-key":
+		return verifierKeyCommand(args[2], args[3], stderr)
+	case len(args) == 2 && args[0] == "setup":
+		return setupCommand(args[1], stderr)
+	case len(args) == 4 && args[0] == "setup" && args[1] == "-curve":
+		c, err := parseCurve(args[2])
+		if err != nil {
+			return cliFail(stderr, err)
+		}
+		if c == curveBLS12377 {
+			return bls12377SetupCommand(args[3], stderr)
+		}
+		return setupCommand(args[3], stderr)
+	case len(args) == 4 && args[0] == "commit":
+		return commitCommand(args[1], args[2], args[3], stderr)
+	case len(args) == 5 && args[0] == "prove":
+		return proveCommand(args[1], args[2], args[3], args[4], stdout, stderr)
+	case len(args) >= 5 && args[0] == "aggregate":
+		return aggregateCommand(args[1], args[2], args[3], args[4:], stderr)
+	case len(args) >= 5 && args[0] == "verify":
+		return verifyCommand(args[1], args[2], args[3], args[4:], stdout, stderr)
+	case len(args) == 3 && args[0] == "serve":
+		if err := loadParams(args[1]); err != nil {
+			return cliFail(stderr, err)
+		}
+		return cliFail(stderr, newAPIServer(args[2]).ListenAndServe())
+	case len(args) == 3 && args[0] == "grpc":
+		if err := loadParams(args[1]); err != nil {
+			return cliFail(stderr, err)
+		}
+		lis, err := net.Listen("tcp", args[2])
+		if err != nil {
+			return cliFail(stderr, err)
+		}
+		return cliFail(stderr, newGRPCServer().Serve(lis))
+	case len(args) == 3 && args[0] == "solidity":
+		return solidityCommand(args[1], args[2], stderr)
+	case len(args) == 4 && args[0] == "car" && args[1] == "params":
+		return carParamsCommand(args[2], args[3], stdout, stderr)
+	case len(args) == 5 && args[0] == "car" && args[1] == "proofs":
+		return carProofsCommand(args[2], args[3], args[4], stdout, stderr)
+	case len(args) == 5 && args[0] == "car" && args[1] == "extract":
+		return carExtractCommand(args[2], args[3], args[4], stderr)
+	case len(args) == 1 && args[0] == "backends":
+		for _, name := range Backends() {
+			if name == backend.Name() {
+				fmt.Fprintln(stdout, name, "(selected)")
+			} else {
+				fmt.Fprintln(stdout, name)
+			}
+		}
+		return 0
+	default:
+		fmt.Fprint(stderr, cliUsage)
+		return 2
+	}
+}
+
+// paramsVerifyCommand implements "params verify", it exits with 1 when the file is not well-formed
+func paramsVerifyCommand(path string, stdout io.Writer, stderr io.Writer) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	issues := diagnoseParams(data)
+	if len(issues) == 0 {
+		fmt.Fprintf(stdout, "%s: well-formed parameters for n = %d\n", path, n)
+		return 0
+	}
+	for _, issue := range issues {
+		fmt.Fprintf(stdout, "%s: %s\n", path, issue)
+	}
+	fmt.Fprintf(stdout, "%s: %d problem(s) found\n", path, len(issues))
+	return 1
+}
+
+// cliFail reports err on stderr and returns the exit code of a failed command
+func cliFail(stderr io.Writer, err error) int {
+	fmt.Fprintln(stderr, err)
+	return 1
+}
+
+// loadParams reads the parameter file, checks it with checkParams and installs the parameters
+func loadParams(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	pp, err := readPublicParams(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := checkParams(pp); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	pp.install()
+	return nil
+}
+
+// parseEntry parses a decimal or 0x prefixed hexadecimal field element
+func parseEntry(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	v, ok := new(big.Int), false
+	if hex := strings.TrimPrefix(s, "0x"); hex != s {
+		_, ok = v.SetString(hex, 16)
+	} else {
+		_, ok = v.SetString(s, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid entry %q", s)
+	}
+	if v.Sign() < 0 || v.Cmp(frModulus) != -1 {
+		return nil, fmt.Errorf("entry %s does not lie in the field", s)
+	}
+	return v, nil
+}
+
+// readVector reads a vector of n entries in the format given by the extension of the path, see cliUsage
+func readVector(path string) ([]*big.Int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		fields = strings.FieldsFunc(string(data), func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	case ".json":
+		var entries []json.Number
+		if err := json.Unmarshal(data, &entries); err != nil {
+			var strs []string
+			if json.Unmarshal(data, &strs) != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			for _, s := range strs {
+				entries = append(entries, json.Number(s))
+			}
+		}
+		for _, e := range entries {
+			fields = append(fields, e.String())
+		}
+	default:
+		if len(data) != n*scalarSize {
+			return nil, fmt.Errorf("%s: expected %d bytes, got %d", path, n*scalarSize, len(data))
+		}
+		for i := 0; i < n; i++ {
+			fields = append(fields, "0x"+new(big.Int).SetBytes(data[i*scalarSize:(i+1)*scalarSize]).Text(16))
+		}
+	}
+	if len(fields) != n {
+		return nil, fmt.Errorf("%s: expected %d entries, got %d", path, n, len(fields))
+	}
+	vector := make([]*big.Int, n)
+	for i, field := range fields {
+		if vector[i], err = parseEntry(field); err != nil {
+			return nil, fmt.Errorf("%s: entry %d: %w", path, i, err)
+		}
+	}
+	return vector, nil
+}
+
+// parseIndex parses an index of a vector
+func parseIndex(s string) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil || !(0 <= i && i < n) {
+		return 0, fmt.Errorf("invalid index %q", s)
+	}
+	return i, nil
+}
+
+// parseClaim parses <index>:<value>, and with proof set <index>:<value>:<proof file>
+func parseClaim(s string, proof bool) (int, *big.Int, string, error) {
+	fields := 2
+	if proof {
+		fields = 3
+	}
+	parts := strings.SplitN(s, ":", fields)
+	if len(parts) != fields {
+		return 0, nil, "", fmt.Errorf("invalid argument %q", s)
+	}
+	i, err := parseIndex(parts[0])
+	if err != nil {
+		return 0, nil, "", err
+	}
+	v, err := parseEntry(parts[1])
+	if err != nil {
+		return 0, nil, "", err
+	}
+	if proof {
+		return i, v, parts[2], nil
+	}
+	return i, v, "", nil
+}
+
+// readArtifact reads a file and parses it with decode
+func readArtifact(path string, decode func([]byte) (*bls.PointG1, error)) (*bls.PointG1, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// setupCommand implements "setup"
+func setupCommand(path string, stderr io.Writer) int {
+	f, err := os.Create(path)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := setupStream(f, setupOptions{}); err != nil {
+		f.Close()
+		return cliFail(stderr, err)
+	}
+	if err := f.Close(); err != nil {
+		return cliFail(stderr, err)
+	}
+	return 0
+}
+
+// commitCommand implements "commit"
+func commitCommand(params, vectorPath, out string, stderr io.Writer) int {
+	if isBLS12377Params(params) {
+		pp, err := loadBLS12377Params(params)
+		if err != nil {
+			return cliFail(stderr, err)
+		}
+		return curveCommitCommand(pp, vectorPath, out, stderr)
+	}
+	if err := loadParams(params); err != nil {
+		return cliFail(stderr, err)
+	}
+	vector, err := readVector(vectorPath)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := os.WriteFile(out, encodeCommitment(commit(vector)), 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	return 0
+}
+
+// verifierKeyCommand implements "params verifier-key"
+func verifierKeyCommand(params, out string, stderr io.Writer) int {
+	f, err := os.Open(params)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	defer f.Close()
+	pp, err := readPublicParams(f)
+	if err != nil {
+		return cliFail(stderr, fmt.Errorf("%s: %w", params, err))
+	}
+	if pp.Swapped {
+		return cliFail(stderr, errors.New("verifier keys of the swapped variant are not supported"))
+	}
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer cannot fail
+	_ = pp.VerifierKey().write(&buf)
+	if err := os.WriteFile(out, buf.Bytes(), 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	return 0
+}
+
+// solidityCommand implements "solidity"
+func solidityCommand(params, out string, stderr io.Writer) int {
+	if err := loadParams(params); err != nil {
+		return cliFail(stderr, err)
+	}
+	var src strings.Builder
+	if err := WriteSolidityVerifier(&src, "PointProofsVerifier"); err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := os.WriteFile(out, []byte(src.String()), 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	return 0
+}
+
+// carParamsCommand implements "car params"
+func carParamsCommand(params, out string, stdout, stderr io.Writer) int {
+	data, err := os.ReadFile(params)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	// the file is exported as it is, once it parses
+	if _, err := readPublicParams(bytes.NewReader(data)); err != nil {
+		return cliFail(stderr, fmt.Errorf("%s: %w", params, err))
+	}
+	return writeCARFile(out, data, stdout, stderr)
+}
+
+// carProofsCommand implements "car proofs"
+func carProofsCommand(params, vectorPath, out string, stdout, stderr io.Writer) int {
+	if err := loadParams(params); err != nil {
+		return cliFail(stderr, err)
+	}
+	vector, err := readVector(vectorPath)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer cannot fail
+	_ = ProveAll(vector).Write(&buf)
+	return writeCARFile(out, buf.Bytes(), stdout, stderr)
+}
+
+// writeCARFile writes data as a CAR file and prints its root
+func writeCARFile(out string, data []byte, stdout, stderr io.Writer) int {
+	var buf bytes.Buffer
+	root, err := WriteCAR(&buf, data)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := os.WriteFile(out, buf.Bytes(), 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	fmt.Fprintln(stdout, root)
+	return 0
+}
+
+// carExtractCommand implements "car extract"
+func carExtractCommand(in, root, out string, stderr io.Writer) int {
+	f, err := os.Open(in)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	defer f.Close()
+	data, err := ReadCAR(f, root, paramsMaxFileSize)
+	if err != nil {
+		return cliFail(stderr, fmt.Errorf("%s: %w", in, err))
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	return 0
+}
+
+// proveCommand implements "prove"
+func proveCommand(params, vectorPath, index, out string, stdout, stderr io.Writer) int {
+	if isBLS12377Params(params) {
+		pp, err := loadBLS12377Params(params)
+		if err != nil {
+			return cliFail(stderr, err)
+		}
+		return curveProveCommand(pp, vectorPath, index, out, stdout, stderr)
+	}
+	if err := loadParams(params); err != nil {
+		return cliFail(stderr, err)
+	}
+	vector, err := readVector(vectorPath)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	i, err := parseIndex(index)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := os.WriteFile(out, encodeProof(ProveSet(vector, []int{i})[0]), 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	fmt.Fprintln(stdout, vector[i])
+	return 0
+}
+
+// aggregateCommand implements "aggregate", with the scalars of AggregateSameCommitment
+func aggregateCommand(params, comPath, out string, claims []string, stderr io.Writer) int {
+	if isBLS12377Params(params) {
+		pp, err := loadBLS12377Params(params)
+		if err != nil {
+			return cliFail(stderr, err)
+		}
+		return curveAggregateCommand(pp, comPath, out, claims, stderr)
+	}
+	if err := loadParams(params); err != nil {
+		return cliFail(stderr, err)
+	}
+	com, err := readArtifact(comPath, decodeCommitment)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	openings := make([]Opening, len(claims))
+	seen := make(map[int]bool, len(claims))
+	for k, claim := range claims {
+		i, v, proofPath, err := parseClaim(claim, true)
+		if err != nil {
+			return cliFail(stderr, err)
+		}
+		if seen[i] {
+			return cliFail(stderr, fmt.Errorf("index %d given twice", i))
+		}
+		seen[i] = true
+		proof, err := readArtifact(proofPath, decodeProof)
+		if err != nil {
+			return cliFail(stderr, err)
+		}
+		if !verifySingleProof(com, v, proof, i) {
+			return cliFail(stderr, fmt.Errorf("%s does not prove entry %d is %s", proofPath, i, v))
+		}
+		openings[k] = Opening{Index: i, Value: v, Proof: proof}
+	}
+	if err := os.WriteFile(out, encodeAggregatedProof(AggregateSameCommitment(com, openings)), 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	return 0
+}
+
+// verifyCommand implements "verify", it exits with 1 when the proof is rejected
+func verifyCommand(params, comPath, proofPath string, claims []string, stdout, stderr io.Writer) int {
+	if isBLS12377Params(params) {
+		pp, err := loadBLS12377Params(params)
+		if err != nil {
+			return cliFail(stderr, err)
+		}
+		return curveVerifyCommand(pp, comPath, proofPath, claims, stdout, stderr)
+	}
+	if err := loadParams(params); err != nil {
+		return cliFail(stderr, err)
+	}
+	com, err := readArtifact(comPath, decodeCommitment)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	indices := make([]int, len(claims))
+	values := make([]*big.Int, len(claims))
+	seen := make(map[int]bool, len(claims))
+	for k, claim := range claims {
+		if indices[k], values[k], _, err = parseClaim(claim, false); err != nil {
+			return cliFail(stderr, err)
+		}
+		if seen[indices[k]] {
+			return cliFail(stderr, fmt.Errorf("index %d given twice", indices[k]))
+		}
+		seen[indices[k]] = true
+	}
+	data, err := os.ReadFile(proofPath)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	var ok bool
+	if len(data) > 0 && data[0] == artifactProof {
+		if len(claims) != 1 {
+			return cliFail(stderr, errors.New("a proof of a single entry needs exactly one claim"))
+		}
+		proof, err := decodeProof(data)
+		if err != nil {
+			return cliFail(stderr, fmt.Errorf("%s: %w", proofPath, err))
+		}
+		ok = verifySingleProof(com, values[0], proof, indices[0])
+	} else {
+		proof, err := decodeAggregatedProof(data)
+		if err != nil {
+			return cliFail(stderr, fmt.Errorf("%s: %w", proofPath, err))
+		}
+		ok = VerifySameCommitment(com, indices, values, proof)
+	}
+	if !ok {
+		fmt.Fprintln(stdout, "invalid")
+		return 1
+	}
+	fmt.Fprintln(stdout, "valid")
+	return 0
+}
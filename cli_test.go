@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadParams checks the CLI installs well-formed parameters and refuses a file whose powers were
+// tampered with, leaving the installed parameters alone
+func TestLoadParams(t *testing.T) {
+	benchSetup(t)
+	installed := srsFingerprint
+	dir := t.TempDir()
+	valid := &PublicParams{PP1: pp1, PP2: pp2}
+	path := filepath.Join(dir, "params.bin")
+	if err := os.WriteFile(path, valid.marshal(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadParams(path); err != nil {
+		t.Fatal(err)
+	}
+	if srsFingerprint != installed {
+		t.Fatal("loading the installed parameters changed the fingerprint")
+	}
+
+	forged := &PublicParams{PP1: pp1, PP2: pp2}
+	forged.PP1[3], forged.PP1[4] = pp1[4], pp1[3]
+	path = filepath.Join(dir, "forged.bin")
+	if err := os.WriteFile(path, forged.marshal(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadParams(path); err == nil {
+		t.Fatal("parameters with swapped powers loaded")
+	}
+	if srsFingerprint != installed {
+		t.Fatal("refused parameters were installed")
+	}
+}
+
+// TestCLIBLS12377 runs setup -curve bls12-377, commit, prove, aggregate and verify on BLS12-377
+// parameters, and checks the BLS12-381 commands refuse them
+func TestCLIBLS12377(t *testing.T) {
+	if testing.Short() {
+		t.Skip("BLS12-377 setup of n powers")
+	}
+	dir := t.TempDir()
+	file := func(name string) string { return filepath.Join(dir, name) }
+	run := func(args ...string) (int, string) {
+		var stdout, stderr bytes.Buffer
+		code := runCLI(args, &stdout, &stderr)
+		return code, stdout.String() + stderr.String()
+	}
+	if code, out := run("setup", "-curve", "bls12-377", file("params")); code != 0 {
+		t.Fatal(out)
+	}
+	pp, err := loadBLS12377Params(file("params"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vector := generateBigIntegerArray(n, pp.Order())
+	entries := make([]string, n)
+	for i, v := range vector {
+		entries[i] = v.String()
+	}
+	data, _ := json.Marshal(entries)
+	if err := os.WriteFile(file("vector.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code, out := run("commit", file("params"), file("vector.json"), file("com")); code != 0 {
+		t.Fatal(out)
+	}
+	for _, i := range []string{"3", "700"} {
+		if code, out := run("prove", file("params"), file("vector.json"), i, file("proof"+i)); code != 0 {
+			t.Fatal(out)
+		}
+	}
+	claim3, claim700 := "3:"+entries[3], "700:"+entries[700]
+	if code, out := run("verify", file("params"), file("com"), file("proof3"), claim3); code != 0 {
+		t.Fatal(out)
+	}
+	if code, _ := run("verify", file("params"), file("com"), file("proof3"), "3:"+entries[4]); code != 1 {
+		t.Fatal("proof of a wrong value accepted")
+	}
+	if code, out := run("aggregate", file("params"), file("com"), file("agg"),
+		claim3+":"+file("proof3"), claim700+":"+file("proof700")); code != 0 {
+		t.Fatal(out)
+	}
+	if code, out := run("verify", file("params"), file("com"), file("agg"), claim3, claim700); code != 0 {
+		t.Fatal(out)
+	}
+	if code, _ := run("verify", file("params"), file("com"), file("agg"), claim3, "700:"+entries[3]); code != 1 {
+		t.Fatal("aggregated proof of a wrong value accepted")
+	}
+
+	if err := loadParams(file("params")); err == nil {
+		t.Fatal("BLS12-381 commands loaded BLS12-377 parameters")
+	}
+	if code, _ := run("params", "verify", file("params")); code == 0 {
+		t.Fatal("params verify accepted BLS12-377 parameters")
+	}
+	if _, err := parseCurve("bn254"); err == nil {
+		t.Fatal("unknown curve accepted")
+	}
+}
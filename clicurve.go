@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+)
+
+/*
+	The commands setup, commit, prove, aggregate and verify on BLS12-377. "setup -curve bls12-377" writes
+	parameters of curvescheme.go for vectors of n entries, and the other four recognize them by the
+	curve ID in their header and run on BLS12377Params instead of the installed parameters. Vectors,
+	claims and artifacts keep their formats, the artifacts being bound to the fingerprint of the
+	BLS12-377 parameters, and entries have to lie below the BLS12-377 group order. The remaining commands
+	are specific to BLS12-381 and refuse such parameters when they read them.
+*/
+
+// parseCurve parses the argument of -curve
+func parseCurve(name string) (curveID, error) {
+	switch strings.ToLower(name) {
+	case "bls12-381":
+		return curveBLS12381, nil
+	case "bls12-377":
+		return curveBLS12377, nil
+	default:
+		return 0, fmt.Errorf("unknown curve %q, expected bls12-381 or bls12-377", name)
+	}
+}
+
+// paramsCurve returns the curve recorded in the header of a parameter file
+func paramsCurve(path string) (curveID, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	c, _, _, err := readCurveFileHeader(f, paramsMagic)
+	return c, err
+}
+
+// isBLS12377Params reports whether the file holds BLS12-377 parameters, anything else is left to the
+// BLS12-381 commands and their errors
+func isBLS12377Params(path string) bool {
+	c, err := paramsCurve(path)
+	return err == nil && c == curveBLS12377
+}
+
+// bls12377SetupCommand implements "setup -curve bls12-377"
+func bls12377SetupCommand(path string, stderr io.Writer) int {
+	f, err := os.Create(path)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := NewBLS12377Params(n).Write(f); err != nil {
+		f.Close()
+		return cliFail(stderr, err)
+	}
+	if err := f.Close(); err != nil {
+		return cliFail(stderr, err)
+	}
+	return 0
+}
+
+// loadBLS12377Params reads BLS12-377 parameters for vectors of n entries and checks their powers
+func loadBLS12377Params(path string) (*BLS12377Params, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	pp, err := ReadBLS12377Params(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if pp.Size() != n {
+		return nil, fmt.Errorf("%s: parameters are for vectors of %d entries, the commands take %d", path, pp.Size(), n)
+	}
+	if err := pp.checkPowers(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pp, nil
+}
+
+// checkCurveEntries returns an error unless the entries lie below the order of the curve of pp
+func checkCurveEntries[G1, G2 any](pp *curveParams[G1, G2], entries []*big.Int) error {
+	q := pp.Order()
+	for _, v := range entries {
+		if v.Cmp(q) != -1 {
+			return fmt.Errorf("entry %s does not lie in the group of %s", v, pp.curve.id())
+		}
+	}
+	return nil
+}
+
+// readCurveArtifact reads a file and parses it as an artifact of the kind under pp
+func readCurveArtifact[G1, G2 any](pp *curveParams[G1, G2], path string, kind byte) (G1, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		var zero G1
+		return zero, err
+	}
+	p, err := pp.DecodeArtifact(kind, data)
+	if err != nil {
+		return p, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// curveCommitCommand is commitCommand on pp
+func curveCommitCommand[G1, G2 any](pp *curveParams[G1, G2], vectorPath, out string, stderr io.Writer) int {
+	vector, err := readVector(vectorPath)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := checkCurveEntries(pp, vector); err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := os.WriteFile(out, pp.EncodeArtifact(artifactCommitment, pp.Commit(vector)), 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	return 0
+}
+
+// curveProveCommand is proveCommand on pp
+func curveProveCommand[G1, G2 any](pp *curveParams[G1, G2], vectorPath, index, out string, stdout, stderr io.Writer) int {
+	vector, err := readVector(vectorPath)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := checkCurveEntries(pp, vector); err != nil {
+		return cliFail(stderr, err)
+	}
+	i, err := parseIndex(index)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	if err := os.WriteFile(out, pp.EncodeArtifact(artifactProof, pp.Prove(vector, i)), 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	fmt.Fprintln(stdout, vector[i])
+	return 0
+}
+
+// curveAggregateCommand is aggregateCommand on pp
+func curveAggregateCommand[G1, G2 any](pp *curveParams[G1, G2], comPath, out string, claims []string, stderr io.Writer) int {
+	com, err := readCurveArtifact(pp, comPath, artifactCommitment)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	indices := make([]int, len(claims))
+	values := make([]*big.Int, len(claims))
+	proofs := make([]G1, len(claims))
+	seen := make(map[int]bool, len(claims))
+	for k, claim := range claims {
+		i, v, proofPath, err := parseClaim(claim, true)
+		if err != nil {
+			return cliFail(stderr, err)
+		}
+		if seen[i] {
+			return cliFail(stderr, fmt.Errorf("index %d given twice", i))
+		}
+		seen[i] = true
+		if err := checkCurveEntries(pp, []*big.Int{v}); err != nil {
+			return cliFail(stderr, err)
+		}
+		if proofs[k], err = readCurveArtifact(pp, proofPath, artifactProof); err != nil {
+			return cliFail(stderr, err)
+		}
+		if !pp.Verify(com, v, proofs[k], i) {
+			return cliFail(stderr, fmt.Errorf("%s does not prove entry %d is %s", proofPath, i, v))
+		}
+		indices[k], values[k] = i, v
+	}
+	aggregated := pp.Aggregate(com, indices, values, proofs)
+	if err := os.WriteFile(out, pp.EncodeArtifact(artifactAggregatedProof, aggregated), 0o644); err != nil {
+		return cliFail(stderr, err)
+	}
+	return 0
+}
+
+// curveVerifyCommand is verifyCommand on pp, it exits with 1 when the proof is rejected
+func curveVerifyCommand[G1, G2 any](pp *curveParams[G1, G2], comPath, proofPath string, claims []string, stdout, stderr io.Writer) int {
+	com, err := readCurveArtifact(pp, comPath, artifactCommitment)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	indices := make([]int, len(claims))
+	values := make([]*big.Int, len(claims))
+	seen := make(map[int]bool, len(claims))
+	for k, claim := range claims {
+		if indices[k], values[k], _, err = parseClaim(claim, false); err != nil {
+			return cliFail(stderr, err)
+		}
+		if seen[indices[k]] {
+			return cliFail(stderr, fmt.Errorf("index %d given twice", indices[k]))
+		}
+		seen[indices[k]] = true
+	}
+	if err := checkCurveEntries(pp, values); err != nil {
+		return cliFail(stderr, err)
+	}
+	data, err := os.ReadFile(proofPath)
+	if err != nil {
+		return cliFail(stderr, err)
+	}
+	var ok bool
+	if len(data) > 0 && data[0] == artifactProof {
+		if len(claims) != 1 {
+			return cliFail(stderr, errors.New("a proof of a single entry needs exactly one claim"))
+		}
+		proof, err := pp.DecodeArtifact(artifactProof, data)
+		if err != nil {
+			return cliFail(stderr, fmt.Errorf("%s: %w", proofPath, err))
+		}
+		ok = pp.Verify(com, values[0], proof, indices[0])
+	} else {
+		proof, err := pp.DecodeArtifact(artifactAggregatedProof, data)
+		if err != nil {
+			return cliFail(stderr, fmt.Errorf("%s: %w", proofPath, err))
+		}
+		ok = pp.VerifyAggregated(com, indices, values, proof)
+	}
+	if !ok {
+		fmt.Fprintln(stdout, "invalid")
+		return 1
+	}
+	fmt.Fprintln(stdout, "valid")
+	return 0
+}
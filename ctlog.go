@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Certificate-transparency-style logs on top of Log. Submitted entries, e.g. certificates, are appended
+	as EntryEncoder.Encode(index, entry) and the commitment is republished once per epoch as an
+	EpochHead. A client learns that an entry is in the log from an inclusion proof against a head, and
+	that a new head only appends to the one it holds from a consistency proof, which together rule out a
+	log showing different histories to different clients, as long as they compare heads. Epoch 0 is the
+	empty log, whose head everybody can compute, so a client starts there and follows the log from head
+	to head. The log holds up to n entries.
+	Heads are not signed here, a deployment signs them with the key of the log.
+*/
+
+// EpochHead is the commitment a log publishes at an epoch, to its first Size entries
+type EpochHead struct {
+	Epoch      uint64
+	Size       int
+	Commitment *bls.PointG1
+}
+
+// emptyEpochHead returns the head of epoch 0
+func emptyEpochHead() EpochHead {
+	return EpochHead{Commitment: bls.NewG1().Zero()}
+}
+
+// CTLog is a transparency log, it is not safe for concurrent use
+type CTLog struct {
+	encoder *EntryEncoder
+	log     *Log
+	entries [][]byte
+	heads   []EpochHead
+}
+
+// NewCTLog returns the log of the application at epoch 0, see NewEntryEncoder
+func NewCTLog(application string) *CTLog {
+	return &CTLog{encoder: NewEntryEncoder(application), log: NewLog(), heads: []EpochHead{emptyEpochHead()}}
+}
+
+// Submit appends the entry, which the next head includes, and returns its index
+func (c *CTLog) Submit(entry []byte) (int, error) {
+	if c.log.Size() == n {
+		return 0, errors.New("the log is full")
+	}
+	index := c.log.Append(c.encoder.Encode(c.log.Size(), entry))
+	c.entries = append(c.entries, append([]byte{}, entry...))
+	return index, nil
+}
+
+// Publish starts the next epoch and returns its head, including every entry submitted so far
+func (c *CTLog) Publish() EpochHead {
+	head := EpochHead{Epoch: uint64(len(c.heads)), Size: c.log.Size(), Commitment: c.log.Commitment()}
+	c.heads = append(c.heads, head)
+	return head
+}
+
+// Head returns the head of the epoch
+func (c *CTLog) Head(epoch uint64) EpochHead {
+	if epoch >= uint64(len(c.heads)) {
+		panic(fmt.Sprintf("epoch %d not published", epoch))
+	}
+	head := c.heads[epoch]
+	head.Commitment = new(bls.PointG1).Set(head.Commitment)
+	return head
+}
+
+// Latest returns the head of the last epoch
+func (c *CTLog) Latest() EpochHead {
+	return c.Head(uint64(len(c.heads) - 1))
+}
+
+// ProveInclusion returns the entry at the index and the proof of it against the head of the epoch,
+// which has to include it
+func (c *CTLog) ProveInclusion(index int, epoch uint64) ([]byte, *bls.PointG1) {
+	size := c.Head(epoch).Size
+	if !(0 <= index && index < size) {
+		panic("the entry is not in the log at that epoch")
+	}
+	message := make([]*big.Int, n)
+	for i := range message {
+		message[i] = new(big.Int)
+		if i < size {
+			message[i] = c.log.message[i]
+		}
+	}
+	return c.entries[index], ProveSet(message, []int{index})[0]
+}
+
+// ProveEpochConsistency returns the proof that the head of epoch to extends the head of epoch from
+func (c *CTLog) ProveEpochConsistency(from, to uint64) *bls.PointG1 {
+	if from > to {
+		panic("epochs out of order")
+	}
+	return c.log.proveExtension(c.Head(from).Size, c.Head(to).Size)
+}
+
+// VerifyCTInclusion reports whether the proof shows the log of the application includes the entry at
+// the index under the head
+func VerifyCTInclusion(head EpochHead, application string, index int, entry []byte, proof *bls.PointG1) bool {
+	if !(0 <= index && index < head.Size) {
+		return false
+	}
+	return verifySingleProof(head.Commitment, NewEntryEncoder(application).Encode(index, entry), proof, index)
+}
+
+// VerifyEpochConsistency reports whether the proof shows the later head extends the earlier one
+func VerifyEpochConsistency(earlier, later EpochHead, proof *bls.PointG1) bool {
+	if earlier.Epoch > later.Epoch || !(0 <= earlier.Size && earlier.Size <= later.Size && later.Size <= n) {
+		return false
+	}
+	return VerifyLogConsistency(earlier.Commitment, earlier.Size, later.Commitment, later.Size, proof)
+}
+
+// CTClient follows the heads of a log, accepting only those consistent with the one it holds
+type CTClient struct {
+	application string
+	head        EpochHead
+}
+
+// NewCTClient returns a client of the log of the application holding the head of epoch 0
+func NewCTClient(application string) *CTClient {
+	return &CTClient{application: application, head: emptyEpochHead()}
+}
+
+// Head returns the head the client holds
+func (c *CTClient) Head() EpochHead {
+	return c.head
+}
+
+// Advance moves the client to a later head, given the proof that it extends the current one
+func (c *CTClient) Advance(head EpochHead, proof *bls.PointG1) error {
+	if head.Epoch <= c.head.Epoch {
+		return fmt.Errorf("head of epoch %d does not follow epoch %d", head.Epoch, c.head.Epoch)
+	}
+	if !VerifyEpochConsistency(c.head, head, proof) {
+		return fmt.Errorf("head of epoch %d is inconsistent with epoch %d", head.Epoch, c.head.Epoch)
+	}
+	c.head = EpochHead{Epoch: head.Epoch, Size: head.Size, Commitment: new(bls.PointG1).Set(head.Commitment)}
+	return nil
+}
+
+// CheckInclusion reports whether the proof shows the entry at the index under the head the client holds
+func (c *CTClient) CheckInclusion(index int, entry []byte, proof *bls.PointG1) bool {
+	return VerifyCTInclusion(c.head, c.application, index, entry, proof)
+}
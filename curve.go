@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+/*
+	Curves. Parameter files record the curve they were generated on, so files made for another curve are
+	rejected up front instead of failing somewhere inside the point decoding. The scheme as the rest of
+	the tree implements it runs on BLS12-381 in go-ethereum's types, and its parameter files are only
+	read for that curve. The group arithmetic of a curve is also available behind pairingCurve, which
+	the scheme of curvescheme.go runs on with parameters of its own: bls12381Curve (curve_bls12381.go)
+	goes through the Backend in use and bls12377Curve (curve_bls12377.go) through gnark-crypto, for
+	proof recursion ecosystems built on BLS12-377. Both curves encode points uncompressed in g1Size and
+	g2Size bytes, their base fields having the same size.
+*/
+
+// curveID identifies a pairing-friendly curve
+type curveID byte
+
+const (
+	curveBLS12381 curveID = 1
+	curveBLS12377 curveID = 2
+)
+
+// activeCurve is the curve all group operations of this build run on
+const activeCurve = curveBLS12381
+
+func (c curveID) String() string {
+	switch c {
+	case curveBLS12381:
+		return "BLS12-381"
+	case curveBLS12377:
+		return "BLS12-377"
+	default:
+		return fmt.Sprintf("unknown curve %d", byte(c))
+	}
+}
+
+// pairingCurve is the group arithmetic of a pairing-friendly curve with points of type G1 and G2. The
+// operations return new points and leave their arguments as they are, and they panic on malformed
+// input like the rest of the scheme
+type pairingCurve[G1, G2 any] interface {
+	id() curveID
+	// order is the group order r, scalars lie in [0, r)
+	order() *big.Int
+	g1Generator() G1
+	g2Generator() G2
+	g1Zero() G1
+	g1Add(a, b G1) G1
+	g1Neg(p G1) G1
+	g1Mul(p G1, s *big.Int) G1
+	g2Mul(p G2, s *big.Int) G2
+	// multiExpG1 returns \sum scalars[i] * points[i]
+	multiExpG1(points []G1, scalars []*big.Int) G1
+	// multiExpG2 returns \sum scalars[i] * points[i]
+	multiExpG2(points []G2, scalars []*big.Int) G2
+	// pairingCheck reports whether \prod e(a[i], b[i]) is the identity of GT
+	pairingCheck(a []G1, b []G2) bool
+	// encodeG1 returns the uncompressed encoding of p, g1Size bytes
+	encodeG1(p G1) []byte
+	// decodeG1 parses an encoding of encodeG1 and checks the point lies in the prime order subgroup
+	decodeG1(b []byte) (G1, error)
+	// encodeG2 returns the uncompressed encoding of p, g2Size bytes
+	encodeG2(p G2) []byte
+	// decodeG2 parses an encoding of encodeG2 and checks the point lies in the prime order subgroup
+	decodeG2(b []byte) (G2, error)
+}
+
+// checkCurve returns an error unless parameters for curve c can be installed as the scheme's parameters
+func checkCurve(c curveID) error {
+	if c != activeCurve {
+		return fmt.Errorf("parameters are for %s, this build only implements %s", c, activeCurve)
+	}
+	return nil
+}
+
+/*
+	Parameter and Lagrange files share a header:
+		1. a four byte magic identifying the file type
+		2. format version, one byte
+		3. the curve ID, one byte (absent in version 1 files, which are all BLS12-381)
+		4. flags, one byte whose meaning depends on the file type (absent before version 3, i.e. zero)
+		5. n as a big endian uint32
+*/
+const (
+	fileHeaderVersion = 3
+	fileHeaderSize    = 4 + 1 + 1 + 1 + 4
+)
+
+// writeFileHeader writes the header of a file of the given type for the compiled in n and the active curve
+func writeFileHeader(w io.Writer, magic string, flags byte) error {
+	return writeCurveFileHeader(w, magic, activeCurve, n, flags)
+}
+
+// writeCurveFileHeader is writeFileHeader for a file of curve c and the given size in place of n
+func writeCurveFileHeader(w io.Writer, magic string, c curveID, size int, flags byte) error {
+	header := make([]byte, fileHeaderSize)
+	copy(header, magic)
+	header[4] = fileHeaderVersion
+	header[5] = byte(c)
+	header[6] = flags
+	binary.BigEndian.PutUint32(header[7:], uint32(size))
+	_, err := w.Write(header)
+	return err
+}
+
+// readFileHeader reads and checks the header of a file of the given type and returns its flags,
+// it accepts older versions
+func readFileHeader(r io.Reader, magic string) (byte, error) {
+	curve, flags, size, err := readCurveFileHeader(r, magic)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkCurve(curve); err != nil {
+		return 0, err
+	}
+	if size != n {
+		return 0, fmt.Errorf("parameters are for n = %d, this build uses n = %d", size, n)
+	}
+	return flags, nil
+}
+
+// readCurveFileHeader is readFileHeader for files of any curve and size, it returns both next to the flags
+func readCurveFileHeader(r io.Reader, magic string) (curveID, byte, int, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, 0, 0, fmt.Errorf("reading header: %w", err)
+	}
+	if string(header[:4]) != magic {
+		return 0, 0, 0, errors.New("bad magic, not a " + magic + " file")
+	}
+	version := header[4]
+	if version < 1 || version > fileHeaderVersion {
+		return 0, 0, 0, fmt.Errorf("unsupported version %d", version)
+	}
+	// curve and flags, as far as the version has them
+	var extra [2]byte
+	if _, err := io.ReadFull(r, extra[:version-1]); err != nil {
+		return 0, 0, 0, fmt.Errorf("reading header: %w", err)
+	}
+	curve, flags := curveBLS12381, byte(0)
+	if version >= 2 {
+		curve = curveID(extra[0])
+	}
+	if version >= 3 {
+		flags = extra[1]
+	}
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return 0, 0, 0, fmt.Errorf("reading header: %w", err)
+	}
+	return curve, flags, int(size), nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	bls377fr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+)
+
+// BLS12377Params are parameters of the scheme of curvescheme.go on BLS12-377, for commitments and proofs
+// in gnark-crypto's types. Only this scheme runs on them, see curvescheme.go for what it covers
+type BLS12377Params = curveParams[bls377.G1Affine, bls377.G2Affine]
+
+// NewBLS12377Params runs a trusted setup on BLS12-377 for vectors of the given length
+func NewBLS12377Params(size int) *BLS12377Params {
+	return newCurveParams[bls377.G1Affine, bls377.G2Affine](bls12377Curve{}, size)
+}
+
+// ReadBLS12377Params parses BLS12-377 parameters of any size written by BLS12377Params.Write
+func ReadBLS12377Params(r io.Reader) (*BLS12377Params, error) {
+	return readCurveParams[bls377.G1Affine, bls377.G2Affine](r, bls12377Curve{})
+}
+
+// bls12377Curve is BLS12-377 in gnark-crypto's affine types. Its uncompressed encodings are the RawBytes
+// of gnark-crypto, which mark the point at infinity with the flag 0x40 in the first byte
+type bls12377Curve struct{}
+
+func (bls12377Curve) id() curveID { return curveBLS12377 }
+
+func (bls12377Curve) order() *big.Int { return bls377fr.Modulus() }
+
+func (bls12377Curve) g1Generator() bls377.G1Affine {
+	_, _, g1, _ := bls377.Generators()
+	return g1
+}
+
+func (bls12377Curve) g2Generator() bls377.G2Affine {
+	_, _, _, g2 := bls377.Generators()
+	return g2
+}
+
+func (bls12377Curve) g1Zero() bls377.G1Affine {
+	// the zero value is (0, 0), gnark-crypto's point at infinity
+	return bls377.G1Affine{}
+}
+
+func (bls12377Curve) g1Add(a, b bls377.G1Affine) bls377.G1Affine {
+	var res bls377.G1Affine
+	res.Add(&a, &b)
+	return res
+}
+
+func (bls12377Curve) g1Neg(p bls377.G1Affine) bls377.G1Affine {
+	var res bls377.G1Affine
+	res.Neg(&p)
+	return res
+}
+
+func (bls12377Curve) g1Mul(p bls377.G1Affine, s *big.Int) bls377.G1Affine {
+	var res bls377.G1Affine
+	res.ScalarMultiplication(&p, s)
+	return res
+}
+
+func (bls12377Curve) g2Mul(p bls377.G2Affine, s *big.Int) bls377.G2Affine {
+	var res bls377.G2Affine
+	res.ScalarMultiplication(&p, s)
+	return res
+}
+
+func (bls12377Curve) multiExpG1(points []bls377.G1Affine, scalars []*big.Int) bls377.G1Affine {
+	if len(points) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	var res bls377.G1Affine
+	if _, err := res.MultiExp(points, toBLS12377Scalars(scalars), ecc.MultiExpConfig{NbTasks: runtime.NumCPU()}); err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func (bls12377Curve) multiExpG2(points []bls377.G2Affine, scalars []*big.Int) bls377.G2Affine {
+	if len(points) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	var res bls377.G2Affine
+	if _, err := res.MultiExp(points, toBLS12377Scalars(scalars), ecc.MultiExpConfig{NbTasks: runtime.NumCPU()}); err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func (bls12377Curve) pairingCheck(a []bls377.G1Affine, b []bls377.G2Affine) bool {
+	ok, err := bls377.PairingCheck(a, b)
+	if err != nil {
+		panic(err)
+	}
+	return ok
+}
+
+func (bls12377Curve) encodeG1(p bls377.G1Affine) []byte {
+	b := p.RawBytes()
+	return b[:]
+}
+
+// decodeG1 relies on SetBytes, which checks the subgroup of the points it decodes
+func (bls12377Curve) decodeG1(b []byte) (bls377.G1Affine, error) {
+	var p bls377.G1Affine
+	if len(b) != bls377.SizeOfG1AffineUncompressed {
+		return p, fmt.Errorf("expected %d bytes, got %d", bls377.SizeOfG1AffineUncompressed, len(b))
+	}
+	if _, err := p.SetBytes(b); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func (bls12377Curve) encodeG2(p bls377.G2Affine) []byte {
+	b := p.RawBytes()
+	return b[:]
+}
+
+// decodeG2 relies on SetBytes, which checks the subgroup of the points it decodes
+func (bls12377Curve) decodeG2(b []byte) (bls377.G2Affine, error) {
+	var p bls377.G2Affine
+	if len(b) != bls377.SizeOfG2AffineUncompressed {
+		return p, fmt.Errorf("expected %d bytes, got %d", bls377.SizeOfG2AffineUncompressed, len(b))
+	}
+	if _, err := p.SetBytes(b); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// toBLS12377Scalars converts scalars in [0, r) to gnark-crypto's field elements
+func toBLS12377Scalars(scalars []*big.Int) []bls377fr.Element {
+	res := make([]bls377fr.Element, len(scalars))
+	for i, s := range scalars {
+		res[i].SetBigInt(s)
+	}
+	return res
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// bls12381Curve is BLS12-381 in go-ethereum's types, the multi-scalar multiplications and pairings go
+// through the Backend in use like those of the scheme
+type bls12381Curve struct{}
+
+func (bls12381Curve) id() curveID { return curveBLS12381 }
+
+func (bls12381Curve) order() *big.Int { return new(big.Int).Set(frModulus) }
+
+func (bls12381Curve) g1Generator() *bls.PointG1 {
+	g := getG1()
+	defer putG1(g)
+	return g.One()
+}
+
+func (bls12381Curve) g2Generator() *bls.PointG2 {
+	g := getG2()
+	defer putG2(g)
+	return g.One()
+}
+
+func (bls12381Curve) g1Zero() *bls.PointG1 {
+	g := getG1()
+	defer putG1(g)
+	return g.Zero()
+}
+
+func (bls12381Curve) g1Add(a, b *bls.PointG1) *bls.PointG1 {
+	g := getG1()
+	defer putG1(g)
+	return g.Add(g.New(), a, b)
+}
+
+func (bls12381Curve) g1Neg(p *bls.PointG1) *bls.PointG1 {
+	g := getG1()
+	defer putG1(g)
+	return g.Neg(g.New(), p)
+}
+
+func (bls12381Curve) g1Mul(p *bls.PointG1, s *big.Int) *bls.PointG1 {
+	g := getG1()
+	defer putG1(g)
+	return mulG1(g, g.New(), p, frFromBig(s))
+}
+
+func (bls12381Curve) g2Mul(p *bls.PointG2, s *big.Int) *bls.PointG2 {
+	g := getG2()
+	defer putG2(g)
+	return mulG2(g, g.New(), p, frFromBig(s))
+}
+
+func (bls12381Curve) multiExpG1(points []*bls.PointG1, scalars []*big.Int) *bls.PointG1 {
+	return backend.MultiExpG1(points, frVector(scalars))
+}
+
+func (bls12381Curve) multiExpG2(points []*bls.PointG2, scalars []*big.Int) *bls.PointG2 {
+	return backend.MultiExpG2(points, frVector(scalars))
+}
+
+func (bls12381Curve) pairingCheck(a []*bls.PointG1, b []*bls.PointG2) bool {
+	return backend.PairingCheck(a, b)
+}
+
+func (bls12381Curve) encodeG1(p *bls.PointG1) []byte {
+	g := getG1()
+	defer putG1(g)
+	return g.ToBytes(p)
+}
+
+func (bls12381Curve) decodeG1(b []byte) (*bls.PointG1, error) {
+	g := getG1()
+	defer putG1(g)
+	p, err := g.FromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if !g.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in the correct subgroup")
+	}
+	return p, nil
+}
+
+func (bls12381Curve) encodeG2(p *bls.PointG2) []byte {
+	g := getG2()
+	defer putG2(g)
+	return g.ToBytes(p)
+}
+
+func (bls12381Curve) decodeG2(b []byte) (*bls.PointG2, error) {
+	g := getG2()
+	defer putG2(g)
+	p, err := g.FromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if !g.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in the correct subgroup")
+	}
+	return p, nil
+}
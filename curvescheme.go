@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+/*
+	The scheme on any pairingCurve and for any vector length, for curves other than the one of the rest
+	of the tree and for parameter sets of another size than the compiled in n, see migration.go. It
+	covers the core of main.go and fiatshamir.go: setup, commitments, single proofs and their
+	non-interactive aggregation under one commitment, on parameters held by a curveParams instead of the
+	globals, with its size N in place of n. The
+	proofs are those of generateProofSingle, and a verifier checks
+		e(C, g2^{alpha^{N+1-i}}) * e(pi, g2)^{-1} * e(g1^alpha, g2^{alpha^N})^{-m_i} = 1
+	in a single pairing check, the GT table of target.go being specific to go-ethereum's types. The
+	aggregation scalars come from the transcript of sameCommitmentScalars reduced modulo the order of
+	the curve. Parameter files have the layout of params.go with the curve ID and N in the header and
+	the points in the encodings of the curve. On BLS12-381 with N = n commitments, proofs, aggregates,
+	files and fingerprints equal those of the rest of the tree. On BLS12-377 it is the whole of the scheme,
+	as BLS12377Params and through the CLI (clicurve.go): cross-commitment aggregation, ProveAll, the
+	verifier keys, the servers and every other feature stay on BLS12-381.
+*/
+
+// curveParams holds parameters of the scheme on curve for vectors of length N = len(pp2)
+type curveParams[G1, G2 any] struct {
+	curve pairingCurve[G1, G2]
+	// pp1[i-1] = {g1 ^ {alpha ^ i}} for 1 <= i <= 2N except for N + 1, pp1[N] = 0
+	pp1 []G1
+	// pp2[i-1] = {g2 ^ {alpha ^ i}} for 1 <= i <= N
+	pp2 []G2
+}
+
+// Size returns N, the length of the vectors of the parameters
+func (pp *curveParams[G1, G2]) Size() int {
+	return len(pp.pp2)
+}
+
+// newCurveParams runs the setup of main.go on curve for vectors of the given length with a fresh
+// random alpha
+func newCurveParams[G1, G2 any](curve pairingCurve[G1, G2], size int) *curveParams[G1, G2] {
+	// 70 random bytes keep the bias of the reduction out of sight, as in setup
+	buf := make([]byte, 70)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("error while generating random string: %s", err))
+	}
+	return newCurveParamsFrom(curve, size, new(big.Int).SetBytes(buf))
+}
+
+// newCurveParamsFrom runs the setup of main.go on curve for the given alpha, which is reduced modulo
+// the group order. It is meant for tests, parameters of a known alpha are worthless
+func newCurveParamsFrom[G1, G2 any](curve pairingCurve[G1, G2], size int, alpha *big.Int) *curveParams[G1, G2] {
+	if size < 1 {
+		panic("the size must be positive")
+	}
+	q := curve.order()
+	alpha = new(big.Int).Mod(alpha, q)
+	pp := &curveParams[G1, G2]{curve: curve, pp1: make([]G1, 2*size), pp2: make([]G2, size)}
+	g1, g2 := curve.g1Generator(), curve.g2Generator()
+	power := big.NewInt(1)
+	for i := 1; i < 2*size+1; i++ {
+		power.Mul(power, alpha).Mod(power, q)
+		if i == size+1 {
+			pp.pp1[i-1] = curve.g1Zero()
+		} else {
+			pp.pp1[i-1] = curve.g1Mul(g1, power)
+		}
+		if i <= size {
+			pp.pp2[i-1] = curve.g2Mul(g2, power)
+		}
+	}
+	return pp
+}
+
+// Order returns the group order r of the curve, entries and values lie in [0, r)
+func (pp *curveParams[G1, G2]) Order() *big.Int {
+	return pp.curve.order()
+}
+
+// checkScalar panics unless s lies in [0, r)
+func (pp *curveParams[G1, G2]) checkScalar(s *big.Int) {
+	if s.Sign() < 0 || s.Cmp(pp.curve.order()) != -1 {
+		panic("the message does not lie in the group")
+	}
+}
+
+// checkIndex panics unless the index lies in [0, N)
+func (pp *curveParams[G1, G2]) checkIndex(index int) {
+	if !(0 <= index && index < pp.Size()) {
+		panic("out of range index")
+	}
+}
+
+// Commit returns \sum m_i * g1^{alpha^i} for a message of N entries
+func (pp *curveParams[G1, G2]) Commit(message []*big.Int) G1 {
+	if len(message) != pp.Size() {
+		panic("wrong array size")
+	}
+	for _, m := range message {
+		pp.checkScalar(m)
+	}
+	return pp.curve.multiExpG1(pp.pp1[:pp.Size()], message)
+}
+
+// Prove returns the proof of generateProofSingle for the entry at index
+func (pp *curveParams[G1, G2]) Prove(message []*big.Int, index int) G1 {
+	size := pp.Size()
+	if len(message) != size {
+		panic("wrong array size")
+	}
+	pp.checkIndex(index)
+	for _, m := range message {
+		pp.checkScalar(m)
+	}
+	// the entry at index meets pp1[N], the point at infinity, so it drops out by itself
+	return pp.curve.multiExpG1(pp.pp1[size-index:2*size-index], message)
+}
+
+// Verify checks a proof of Prove against the commitment
+func (pp *curveParams[G1, G2]) Verify(com G1, entry *big.Int, proof G1, index int) bool {
+	pp.checkIndex(index)
+	pp.checkScalar(entry)
+	size, c := pp.Size(), pp.curve
+	return c.pairingCheck(
+		[]G1{com, c.g1Neg(proof), c.g1Neg(c.g1Mul(pp.pp1[0], entry))},
+		[]G2{pp.pp2[size-index-1], c.g2Generator(), pp.pp2[size-1]})
+}
+
+// checkOpened is checkOpened of fiatshamir.go for N and the order of the curve
+func (pp *curveParams[G1, G2]) checkOpened(indices []int, values []*big.Int) {
+	if len(indices) != len(values) {
+		panic("arrays with incorrect length")
+	}
+	seen := make(map[int]bool, len(indices))
+	for k, i := range indices {
+		pp.checkIndex(i)
+		if seen[i] {
+			panic("duplicate index")
+		}
+		seen[i] = true
+		pp.checkScalar(values[k])
+	}
+}
+
+// scalars returns sameCommitmentScalars for the openings of com, reduced modulo the order of the curve
+func (pp *curveParams[G1, G2]) scalars(com G1, indices []int, values []*big.Int) []*big.Int {
+	pp.checkOpened(indices, values)
+	t := NewTranscript(sameCommitmentDomain)
+	t.Append("C", pp.curve.encodeG1(com))
+	appendOpenings(t, indices, values)
+	q := pp.curve.order()
+	order := indexOrder(indices)
+	res := make([]*big.Int, len(order))
+	for _, k := range order {
+		res[k] = t.challengeScalarMod("t", q)
+	}
+	return res
+}
+
+// Aggregate is AggregateSameCommitment, it combines proofs of the entries at indices with values
+func (pp *curveParams[G1, G2]) Aggregate(com G1, indices []int, values []*big.Int, proofs []G1) G1 {
+	if len(proofs) != len(indices) {
+		panic("arrays with incorrect length")
+	}
+	return pp.curve.multiExpG1(proofs, pp.scalars(com, indices, values))
+}
+
+// ProveAggregated returns Aggregate of the proofs of Prove for the entries of message at indices, in
+// one multi-exponentiation over pp1 instead of one per index: the proof of i weighs pp1[N-i+j] by m_j
+func (pp *curveParams[G1, G2]) ProveAggregated(com G1, message []*big.Int, indices []int) G1 {
+	size := pp.Size()
+	if len(message) != size {
+		panic("wrong array size")
+	}
+	values := make([]*big.Int, len(indices))
+	for k, i := range indices {
+		pp.checkIndex(i)
+		values[k] = message[i]
+	}
+	t := pp.scalars(com, indices, values)
+	q := pp.curve.order()
+	weights := make([]*big.Int, 2*size)
+	for l := range weights {
+		weights[l] = new(big.Int)
+	}
+	term := new(big.Int)
+	for k, i := range indices {
+		for j, m := range message {
+			// j = i meets pp1[N], the point at infinity, as in prove
+			weights[size-i+j].Add(weights[size-i+j], term.Mul(t[k], m))
+		}
+	}
+	for _, w := range weights {
+		w.Mod(w, q)
+	}
+	return pp.curve.multiExpG1(pp.pp1, weights)
+}
+
+// VerifyAggregated is VerifySameCommitment, it checks an aggregate of Aggregate
+func (pp *curveParams[G1, G2]) VerifyAggregated(com G1, indices []int, values []*big.Int, proof G1) bool {
+	t := pp.scalars(com, indices, values)
+	size, q := pp.Size(), pp.curve.order()
+	bases := make([]G2, len(indices))
+	sum := new(big.Int)
+	for k, i := range indices {
+		bases[k] = pp.pp2[size-i-1]
+		sum.Add(sum, new(big.Int).Mul(t[k], values[k]))
+	}
+	sum.Mod(sum, q)
+	c := pp.curve
+	return c.pairingCheck(
+		[]G1{com, c.g1Neg(proof), c.g1Neg(c.g1Mul(pp.pp1[0], sum))},
+		[]G2{c.multiExpG2(bases, t), c.g2Generator(), pp.pp2[size-1]})
+}
+
+// Write serializes the parameters in the layout of params.go
+func (pp *curveParams[G1, G2]) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := writeCurveFileHeader(bw, paramsMagic, pp.curve.id(), pp.Size(), 0); err != nil {
+		return err
+	}
+	for _, p := range pp.pp1 {
+		if _, err := bw.Write(pp.curve.encodeG1(p)); err != nil {
+			return err
+		}
+	}
+	for _, p := range pp.pp2 {
+		if _, err := bw.Write(pp.curve.encodeG2(p)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Fingerprint is PublicParams.fingerprint for the parameters
+func (pp *curveParams[G1, G2]) Fingerprint() [fingerprintSize]byte {
+	h := sha256.New()
+	h.Write([]byte("PointProofs-SRS-fingerprint"))
+	// writing into a hash cannot fail
+	_ = pp.Write(h)
+	var res [fingerprintSize]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
+// checkPowers is checkPowers of srs.go for the parameters: pp1 and pp2 hold the powers of one non-zero
+// alpha, checked in three pairing checks with random coefficients
+func (pp *curveParams[G1, G2]) checkPowers() error {
+	size, c := pp.Size(), pp.curve
+	zero := c.encodeG1(c.g1Zero())
+	if !bytes.Equal(c.encodeG1(pp.pp1[size]), zero) {
+		return fmt.Errorf("pp1[%d] must be the point at infinity", size)
+	}
+	if bytes.Equal(c.encodeG1(pp.pp1[0]), zero) {
+		return errors.New("degenerate parameters, alpha is zero")
+	}
+	g1, g2 := c.g1Generator(), c.g2Generator()
+	// pairs (pp1[k], pp1[k + 1]) which do not touch the hole at index N
+	var lo, hi []G1
+	for k := 0; k < 2*size-1; k++ {
+		if k != size-1 && k != size {
+			lo = append(lo, pp.pp1[k])
+			hi = append(hi, pp.pp1[k+1])
+		}
+	}
+	if len(lo) > 0 {
+		r := generateBigIntegerArray(len(lo), c.order())
+		if !c.pairingCheck([]G1{c.multiExpG1(hi, r), c.g1Neg(c.multiExpG1(lo, r))}, []G2{g2, pp.pp2[0]}) {
+			return errors.New("pp1 is not a sequence of consecutive powers")
+		}
+	}
+	if size > 1 && !c.pairingCheck([]G1{pp.pp1[size+1], c.g1Neg(pp.pp1[size-1])}, []G2{g2, pp.pp2[1]}) {
+		return fmt.Errorf("pp1[%d] is not consistent with pp1[%d]", size+1, size-1)
+	}
+	u := generateBigIntegerArray(size, c.order())
+	if !c.pairingCheck([]G1{c.multiExpG1(pp.pp1[:size], u), c.g1Neg(g1)}, []G2{g2, c.multiExpG2(pp.pp2, u)}) {
+		return errors.New("pp2 does not match pp1")
+	}
+	return nil
+}
+
+// EncodeArtifact serializes a commitment or proof as the artifact of the given kind of artifacts.go,
+// bound to the fingerprint of the parameters instead of the installed ones
+func (pp *curveParams[G1, G2]) EncodeArtifact(kind byte, p G1) []byte {
+	fingerprint := pp.Fingerprint()
+	data := append([]byte{kind}, fingerprint[:]...)
+	return append(data, pp.curve.encodeG1(p)...)
+}
+
+// DecodeArtifact parses an artifact of EncodeArtifact, checking its kind, its fingerprint and the
+// subgroup of the point
+func (pp *curveParams[G1, G2]) DecodeArtifact(kind byte, data []byte) (G1, error) {
+	var zero G1
+	if len(data) != 1+fingerprintSize+g1Size {
+		return zero, fmt.Errorf("expected %d bytes, got %d", 1+fingerprintSize+g1Size, len(data))
+	}
+	if data[0] != kind {
+		return zero, fmt.Errorf("expected artifact of kind %d, got %d", kind, data[0])
+	}
+	if fingerprint := pp.Fingerprint(); !bytes.Equal(data[1:1+fingerprintSize], fingerprint[:]) {
+		return zero, fmt.Errorf("artifact was produced under parameters %x, these are %x", data[1:1+fingerprintSize], fingerprint)
+	}
+	return pp.curve.decodeG1(data[1+fingerprintSize:])
+}
+
+// readCurveParams parses parameters of curve and any size written by Write. Unlike readPublicParams it
+// checks the subgroups of the points, but like it not that they are well-formed powers
+func readCurveParams[G1, G2 any](r io.Reader, curve pairingCurve[G1, G2]) (*curveParams[G1, G2], error) {
+	br := bufio.NewReader(r)
+	c, flags, size, err := readCurveFileHeader(br, paramsMagic)
+	if err != nil {
+		return nil, err
+	}
+	if c != curve.id() {
+		return nil, fmt.Errorf("parameters are for %s, expected %s", c, curve.id())
+	}
+	if flags != 0 {
+		return nil, errors.New("only parameters without optional sections are supported on this curve")
+	}
+	if size < 1 {
+		return nil, errors.New("parameters for empty vectors")
+	}
+	// the points are appended as they are read, so a forged size runs into the end of the file
+	// instead of allocating its worth
+	pp := &curveParams[G1, G2]{curve: curve}
+	buf := make([]byte, g1Size)
+	for i := 0; i < 2*size; i++ {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, fmt.Errorf("pp1[%d]: %w", i, err)
+		}
+		p, err := curve.decodeG1(buf)
+		if err != nil {
+			return nil, fmt.Errorf("pp1[%d]: %w", i, err)
+		}
+		pp.pp1 = append(pp.pp1, p)
+	}
+	buf = make([]byte, g2Size)
+	for i := 0; i < size; i++ {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, fmt.Errorf("pp2[%d]: %w", i, err)
+		}
+		p, err := curve.decodeG2(buf)
+		if err != nil {
+			return nil, fmt.Errorf("pp2[%d]: %w", i, err)
+		}
+		pp.pp2 = append(pp.pp2, p)
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, errors.New("trailing bytes after parameters")
+	}
+	return pp, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	bls377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// TestCurveSchemeBLS12381 runs the scheme of curvescheme.go on the installed parameters, everything it
+// produces must equal what the rest of the tree produces
+func TestCurveSchemeBLS12381(t *testing.T) {
+	f := benchSetup(t)
+	pp := &curveParams[*bls.PointG1, *bls.PointG2]{curve: bls12381Curve{}, pp1: pp1[:], pp2: pp2[:]}
+	g := getG1()
+	defer putG1(g)
+	com := commit(f.message)
+	if !g.Equal(pp.Commit(f.message), com) {
+		t.Fatal("commitments differ")
+	}
+	proofs := make([]*bls.PointG1, len(f.indices))
+	openings := make([]Opening, len(f.indices))
+	for k, i := range f.indices {
+		proofs[k] = pp.Prove(f.message, i)
+		if !g.Equal(proofs[k], generateProofSingle(f.message, i)) {
+			t.Fatalf("proofs of index %d differ", i)
+		}
+		if !pp.Verify(com, f.values[k], proofs[k], i) {
+			t.Fatalf("proof of index %d rejected", i)
+		}
+		openings[k] = Opening{Index: i, Value: f.values[k], Proof: proofs[k]}
+	}
+	aggregated := pp.Aggregate(com, f.indices, f.values, proofs)
+	if !g.Equal(aggregated, AggregateSameCommitment(com, openings)) {
+		t.Fatal("aggregated proofs differ")
+	}
+	if !pp.VerifyAggregated(com, f.indices, f.values, aggregated) {
+		t.Fatal("aggregated proof rejected")
+	}
+	var file bytes.Buffer
+	if err := pp.Write(&file); err != nil {
+		t.Fatal(err)
+	}
+	installed := &PublicParams{PP1: pp1, PP2: pp2}
+	if !bytes.Equal(file.Bytes(), installed.marshal()) || pp.Fingerprint() != installed.fingerprint() {
+		t.Fatal("parameter files differ")
+	}
+}
+
+func TestCurveSchemeBLS12377(t *testing.T) {
+	pp := newCurveParams[bls377.G1Affine, bls377.G2Affine](bls12377Curve{}, n)
+	q := pp.curve.order()
+	message := generateBigIntegerArray(n, q)
+	com := pp.Commit(message)
+	indices := []int{0, 3, 500, n - 1}
+	values := make([]*big.Int, len(indices))
+	proofs := make([]bls377.G1Affine, len(indices))
+	for k, i := range indices {
+		values[k] = message[i]
+		proofs[k] = pp.Prove(message, i)
+		if !pp.Verify(com, values[k], proofs[k], i) {
+			t.Fatalf("proof of index %d rejected", i)
+		}
+		wrong := new(big.Int).Add(values[k], big.NewInt(1))
+		if pp.Verify(com, wrong, proofs[k], i) {
+			t.Fatalf("wrong value of index %d accepted", i)
+		}
+	}
+	if pp.Verify(com, values[0], proofs[0], indices[1]) {
+		t.Fatal("proof accepted for another index")
+	}
+	aggregated := pp.Aggregate(com, indices, values, proofs)
+	if !pp.VerifyAggregated(com, indices, values, aggregated) {
+		t.Fatal("aggregated proof rejected")
+	}
+	if direct := pp.ProveAggregated(com, message, indices); !direct.Equal(&aggregated) {
+		t.Fatal("aggregated proofs differ")
+	}
+	wrong := append([]*big.Int(nil), values...)
+	wrong[2] = new(big.Int).Add(wrong[2], big.NewInt(1))
+	if pp.VerifyAggregated(com, indices, wrong, aggregated) {
+		t.Fatal("aggregated proof of a wrong value accepted")
+	}
+
+	// the file carries the curve, the scheme of the rest of the tree refuses it
+	var file bytes.Buffer
+	if err := pp.Write(&file); err != nil {
+		t.Fatal(err)
+	}
+	if file.Bytes()[5] != byte(curveBLS12377) {
+		t.Fatalf("header names curve %d", file.Bytes()[5])
+	}
+	read, err := readCurveParams[bls377.G1Affine, bls377.G2Affine](bytes.NewReader(file.Bytes()), bls12377Curve{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Fingerprint() != pp.Fingerprint() || !read.Verify(com, values[1], proofs[1], indices[1]) {
+		t.Fatal("parameters read back differ")
+	}
+	if _, err := readPublicParams(bytes.NewReader(file.Bytes())); err == nil {
+		t.Fatal("BLS12-377 parameters installed as the scheme's")
+	}
+	if _, err := readCurveParams[*bls.PointG1, *bls.PointG2](bytes.NewReader(file.Bytes()), bls12381Curve{}); err == nil {
+		t.Fatal("BLS12-377 parameters read as BLS12-381 ones")
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Data availability sampling. A blob of n/2 field elements is extended with a Reed-Solomon code of rate
+	1/2: it is read as the evaluations of a polynomial p of degree below n/2 at the (n/2)-th roots of
+	unity, and the codeword holds p(omega^k) for the n-th roots of unity omega^k, so the blob sits at the
+	even positions and any n/2 chunks determine it. The codeword is committed as a message, and a light
+	client checks availability by asking for the openings of a few random chunks (SampleOpen) and
+	verifying them at once (VerifySamples): if less than half the chunks can be served, each sample
+	fails with probability at least 1/2, and s samples all pass with probability at most 2^-s.
+	That only helps if the commitment is to a codeword. The coefficients a_j = (1/n) \sum_k c_k omega^{-jk}
+	of the committed c vanish for j >= n/2 exactly then, so the encoding proof is the inner product
+	proof of <c, w> = 0 for
+		w_k = (1/n) \sum_{j >= n/2} rho^{j - n/2} omega^{-jk}
+	with rho the challenge "rho" of a transcript of domain dasDomain that absorbed "C": C. A commitment to
+	anything else passes with probability at most n / (2q). w is the inverse FFT of the vector holding the
+	powers of rho at positions n/2, ..., n - 1, so both sides compute it with O(n log n) operations.
+*/
+
+// dasBlobSize is the number of field elements of a blob, the codeword has n
+const dasBlobSize = n / 2
+
+// CodedBlob is the codeword of a blob with its commitment
+type CodedBlob struct {
+	codeword []*big.Int
+	com      *bls.PointG1
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the blob, n/2 field elements
+	It returns the codeword of the blob, committed.
+*/
+func EncodeBlob(blob []*big.Int) *CodedBlob {
+	if len(blob) != dasBlobSize {
+		panic("wrong array size")
+	}
+	for _, v := range blob {
+		if v.Sign() < 0 || v.Cmp(frModulus) != -1 {
+			panic("the message does not lie in the group")
+		}
+	}
+	omega := rootOfUnity(n)
+	var omegaSquared fr
+	omegaSquared.mul(omega, omega)
+	coeffs := make([]fr, n)
+	copy(coeffs, frVector(blob))
+	ifftScalars(coeffs[:dasBlobSize], omegaSquared)
+	fftScalars(coeffs, omega)
+	b := &CodedBlob{codeword: bigVector(coeffs)}
+	b.com = commit(b.codeword)
+	return b
+}
+
+// Commitment returns the commitment to the codeword
+func (b *CodedBlob) Commitment() *bls.PointG1 {
+	return new(bls.PointG1).Set(b.com)
+}
+
+// Chunk returns chunk k of the codeword, the even chunks 2j being the blob
+func (b *CodedBlob) Chunk(k int) *big.Int {
+	if !(0 <= k && k < n) {
+		panic("out of range index")
+	}
+	return new(big.Int).Set(b.codeword[k])
+}
+
+// encodingQuery returns the query w above for the commitment
+func encodingQuery(com *bls.PointG1) Query {
+	t := NewTranscript(dasDomain)
+	t.AppendG1("C", com)
+	rho := frFromBig(t.ChallengeScalar("rho"))
+	w := make([]fr, n)
+	power := frOne
+	for j := dasBlobSize; j < n; j++ {
+		w[j] = power
+		power.mul(power, rho)
+	}
+	ifftScalars(w, rootOfUnity(n))
+	return DenseQuery(bigVector(w))
+}
+
+// EncodingProof returns the proof that the commitment is to a codeword, checked by VerifyEncoding
+func (b *CodedBlob) EncodingProof() *bls.PointG1 {
+	_, proof := ProveInnerProduct(b.codeword, encodingQuery(b.com))
+	return proof
+}
+
+// VerifyEncoding reports whether the proof shows the commitment is to a codeword of a blob
+func VerifyEncoding(com *bls.PointG1, proof *bls.PointG1) bool {
+	return VerifyInnerProduct(com, encodingQuery(com), new(big.Int), proof)
+}
+
+// SampleOpen returns the opening of chunk k, for a light client that asked for it
+func (b *CodedBlob) SampleOpen(k int) Opening {
+	return Opening{Index: k, Value: b.Chunk(k), Proof: ProveSet(b.codeword, []int{k})[0]}
+}
+
+// DrawSamples returns count distinct random chunk indices for a light client to ask for
+func DrawSamples(count int) []int {
+	if !(0 <= count && count <= n) {
+		panic("invalid number of samples")
+	}
+	chosen := make(map[int]bool, count)
+	res := make([]int, 0, count)
+	size := big.NewInt(n)
+	for len(res) < count {
+		k := int(generateBigIntegerArray(1, size)[0].Int64())
+		if !chosen[k] {
+			chosen[k] = true
+			res = append(res, k)
+		}
+	}
+	return res
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the commitment, whose encoding proof has been checked
+		4. the indices the client asked for
+		5. the openings the server answered with
+	It reports whether every requested chunk was opened, checking the openings at once with VerifyBatch.
+*/
+func VerifySamples(com *bls.PointG1, indices []int, samples []Opening) bool {
+	if len(samples) != len(indices) {
+		return false
+	}
+	for k, o := range samples {
+		if o.Index != indices[k] || o.Value == nil || o.Value.Sign() < 0 || o.Value.Cmp(frModulus) != -1 {
+			return false
+		}
+	}
+	return VerifyBatch(samples, com)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// pairingRelation is an equation e(A1, B1) = e(A2, B2) well-formed parameters satisfy
+type pairingRelation struct {
+	name   string
+	a1, a2 *bls.PointG1
+	b1, b2 *bls.PointG2
+}
+
+/*
+	It takes a parameter file that may be corrupted or malicious and returns a description of every
+	problem found in it, an empty list meaning the file is well-formed. Unlike readPublicParams and
+	checkPowers it does not stop at the first problem:
+		1. the header and the file size are checked
+		2. every point is decoded and checked to lie in the correct subgroup, failures are reported per index
+		3. the pairing relations of checkPowers and checkExtendedG2 are first checked in batches like they do, and
+		   only if the batch fails, one by one, so the exact failing relations are listed
+	Relations involving a point which already failed to decode are skipped.
+*/
+func diagnoseParams(data []byte) []string {
+	var issues []string
+	r := bytes.NewReader(data)
+	flags, err := readFileHeader(r, paramsMagic)
+	if err != nil {
+		return append(issues, err.Error())
+	}
+	// older versions have a shorter header
+	expected := len(data) - r.Len() + 2*n*g1Size + n*g2Size
+	if flags&paramsFlagFullG2 != 0 {
+		expected += n * g2Size
+	}
+	if len(data) != expected {
+		return append(issues, fmt.Sprintf("file is %d bytes long, expected %d", len(data), expected))
+	}
+	e := bls.NewPairingEngine()
+	// nil marks a point which could not be used
+	var p1 [2 * n]*bls.PointG1
+	var p2 [n]*bls.PointG2
+	for i := 0; i < 2*n; i++ {
+		p, err := readG1(r)
+		switch {
+		case err != nil:
+			issues = append(issues, fmt.Sprintf("pp1[%d]: %s", i, err))
+		case !e.G1.InCorrectSubgroup(p):
+			issues = append(issues, fmt.Sprintf("pp1[%d]: not in the correct subgroup", i))
+		default:
+			p1[i] = p
+		}
+	}
+	for i := 0; i < n; i++ {
+		p, err := readG2(r)
+		switch {
+		case err != nil:
+			issues = append(issues, fmt.Sprintf("pp2[%d]: %s", i, err))
+		case !e.G2.InCorrectSubgroup(p):
+			issues = append(issues, fmt.Sprintf("pp2[%d]: not in the correct subgroup", i))
+		default:
+			p2[i] = p
+		}
+	}
+	var ext []*bls.PointG2
+	if flags&paramsFlagFullG2 != 0 {
+		ext = make([]*bls.PointG2, n)
+		for i := 0; i < n; i++ {
+			p, err := readG2(r)
+			switch {
+			case err != nil:
+				issues = append(issues, fmt.Sprintf("pp2ext[%d]: %s", i, err))
+			case !e.G2.InCorrectSubgroup(p):
+				issues = append(issues, fmt.Sprintf("pp2ext[%d]: not in the correct subgroup", i))
+			default:
+				ext[i] = p
+			}
+		}
+		if ext[0] != nil && !e.G2.IsZero(ext[0]) {
+			issues = append(issues, "pp2ext[0]: must be the point at infinity")
+		}
+	}
+	if p1[n] != nil && !e.G1.IsZero(p1[n]) {
+		issues = append(issues, fmt.Sprintf("pp1[%d]: must be the point at infinity", n))
+	}
+	if p1[0] != nil && e.G1.IsZero(p1[0]) {
+		issues = append(issues, "pp1[0]: is the point at infinity, alpha is zero")
+	}
+	// every relation checkPowers relies on
+	var relations []pairingRelation
+	for k := 0; k < 2*n-1; k++ {
+		if k == n-1 || k == n {
+			continue
+		}
+		relations = append(relations, pairingRelation{
+			name: fmt.Sprintf("e(pp1[%d], g2) = e(pp1[%d], pp2[0])", k+1, k),
+			a1:   p1[k+1], b1: e.G2.One(), a2: p1[k], b2: p2[0],
+		})
+	}
+	relations = append(relations, pairingRelation{
+		name: fmt.Sprintf("e(pp1[%d], g2) = e(pp1[%d], pp2[1])", n+1, n-1),
+		a1:   p1[n+1], b1: e.G2.One(), a2: p1[n-1], b2: p2[1],
+	})
+	for k := 0; k < n; k++ {
+		relations = append(relations, pairingRelation{
+			name: fmt.Sprintf("e(pp1[%d], g2) = e(g1, pp2[%d])", k, k),
+			a1:   p1[k], b1: e.G2.One(), a2: e.G1.One(), b2: p2[k],
+		})
+	}
+	for j := 1; j < len(ext); j++ {
+		relations = append(relations, pairingRelation{
+			name: fmt.Sprintf("e(pp1[%d], g2) = e(g1, pp2ext[%d])", n+j, j),
+			a1:   p1[n+j], b1: e.G2.One(), a2: e.G1.One(), b2: ext[j],
+		})
+	}
+	// the batch checks are only meaningful when every point could be used
+	if len(issues) == 0 && checkPowers(e, p1, p2) == nil && checkExtendedG2(e, p1, ext) == nil {
+		return issues
+	}
+	for _, rel := range relations {
+		if rel.a1 == nil || rel.a2 == nil || rel.b2 == nil {
+			continue
+		}
+		if !pairingsEqual(e, rel.a1, rel.b1, rel.a2, rel.b2) {
+			issues = append(issues, "relation fails: "+rel.name)
+		}
+	}
+	return issues
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Distributed proving. Commitments and proofs are linear in the message, so a message split among k
+	provers, prover p holding the entries at offset_p, ..., offset_p + len_p - 1 and none of the others,
+	is proven by summing what every prover computes on its part alone:
+		pi_i = \sum_p \sum_{j in part p} m_j * pp1[n - i + j]
+	one MSM over len_p points per index and prover. The same holds for additive shares of the whole
+	message, offset 0 and length n each, and for commitments, which add up with Commitment.Add. A
+	prover answers with PartialProofs, serialized as
+		kind || fingerprint || count || (index || pi_p)*
+	with count and the indices as big endian uint32 and the points compressed, so any transport carries
+	them and the combiner rejects answers computed under other parameters.
+*/
+
+// PartialProofs are the contributions of one prover to the proofs of the indices
+type PartialProofs struct {
+	Indices []int
+	Proofs  []*bls.PointG1
+}
+
+// checkPart panics unless the part lies in the message and its entries in the field
+func checkPart(offset int, entries []*big.Int) {
+	if !(0 <= offset && offset+len(entries) <= n) {
+		panic("the part does not lie in the message")
+	}
+	for _, x := range entries {
+		if x.Sign() < 0 || x.Cmp(frModulus) != -1 {
+			panic("the message does not lie in the group")
+		}
+	}
+}
+
+// CommitPartial returns the contribution of the entries at offset, offset + 1, ... to the commitment
+func CommitPartial(offset int, entries []*big.Int) *bls.PointG1 {
+	checkPart(offset, entries)
+	if len(entries) == 0 {
+		return bls.NewG1().Zero()
+	}
+	return currentMSM().MultiExpG1(pp1Range(offset, offset+len(entries)), frVector(entries))
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the offset of the part of the message the prover holds
+		4. the entries of that part
+		5. the indices to prove, inside the part or not
+	It returns the contributions of the part to the proofs of the indices.
+*/
+func ProvePartial(offset int, entries []*big.Int, indices []int) *PartialProofs {
+	checkPart(offset, entries)
+	res := &PartialProofs{Indices: append([]int{}, indices...), Proofs: make([]*bls.PointG1, len(indices))}
+	scalars := frVector(entries)
+	for k, i := range indices {
+		if !(0 <= i && i < n) {
+			panic("out of range index")
+		}
+		if len(entries) == 0 {
+			res.Proofs[k] = bls.NewG1().Zero()
+			continue
+		}
+		// the term of the index itself, if the part holds it, falls on pp1[n], the point at infinity
+		res.Proofs[k] = currentMSM().MultiExpG1(pp1Range(n-i+offset, n-i+offset+len(entries)), scalars)
+	}
+	return res
+}
+
+// CombinePartial sums the contributions of all provers into the proofs of the indices, which all of
+// them have to answer in the same order
+func CombinePartial(parts []*PartialProofs) ([]*bls.PointG1, error) {
+	if len(parts) == 0 {
+		return nil, errors.New("no partial proofs")
+	}
+	g := getG1()
+	defer putG1(g)
+	proofs := make([]*bls.PointG1, len(parts[0].Indices))
+	for k := range proofs {
+		proofs[k] = g.Zero()
+	}
+	for p, part := range parts {
+		if len(part.Indices) != len(proofs) || len(part.Proofs) != len(proofs) {
+			return nil, fmt.Errorf("prover %d answered %d indices, expected %d", p, len(part.Proofs), len(proofs))
+		}
+		for k, i := range part.Indices {
+			if i != parts[0].Indices[k] {
+				return nil, fmt.Errorf("prover %d answered index %d at position %d, expected %d", p, i, k, parts[0].Indices[k])
+			}
+			g.Add(proofs[k], proofs[k], part.Proofs[k])
+		}
+	}
+	return proofs, nil
+}
+
+// encodePartialProofs serializes partial proofs
+func encodePartialProofs(p *PartialProofs) []byte {
+	if len(p.Indices) != len(p.Proofs) {
+		panic("arrays with incorrect length")
+	}
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer cannot fail
+	_ = writeArtifactHeader(&buf, artifactPartialProofs)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(p.Indices)))
+	for k, i := range p.Indices {
+		if !(0 <= i && i < n) {
+			panic("out of range index")
+		}
+		_ = binary.Write(&buf, binary.BigEndian, uint32(i))
+		_ = writeCompressedG1(&buf, p.Proofs[k])
+	}
+	return buf.Bytes()
+}
+
+// decodePartialProofs parses partial proofs produced under the installed parameters
+func decodePartialProofs(data []byte) (*PartialProofs, error) {
+	r := bytes.NewReader(data)
+	if err := readArtifactHeader(r, artifactPartialProofs); err != nil {
+		return nil, err
+	}
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+	if int64(count)*(4+g1CompressedSize) != int64(r.Len()) {
+		return nil, fmt.Errorf("expected %d partial proofs, got %d bytes", count, r.Len())
+	}
+	p := &PartialProofs{Indices: make([]int, count), Proofs: make([]*bls.PointG1, count)}
+	for k := range p.Indices {
+		var index uint32
+		// the length was checked above
+		_ = binary.Read(r, binary.BigEndian, &index)
+		if index >= n {
+			return nil, fmt.Errorf("index %d out of range", index)
+		}
+		p.Indices[k] = int(index)
+		proof, err := readCompressedG1(r)
+		if err != nil {
+			return nil, fmt.Errorf("partial proof %d: %w", k, err)
+		}
+		p.Proofs[k] = proof
+	}
+	return p, nil
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Distributed setup: instead of one party sampling alpha, k parties take turns and each one
+	raises the current powers to its own secret s_j. The final parameters are powers of
+	alpha = s_1 * ... * s_k, so alpha stays unknown as long as a single party erases its secret.
+	Parties go in the order 0, 1, ..., k - 1 and every party checks every contribution it receives.
+*/
+
+// dkgContribution is the message a party broadcasts after folding its secret s into the powers
+type dkgContribution struct {
+	Party uint32
+	PP1   [2 * n]*bls.PointG1
+	PP2   [n]*bls.PointG2
+	// S = g1^s and a Schnorr proof (R, Z) showing the party knows s
+	S *bls.PointG1
+	R *bls.PointG1
+	Z *big.Int
+}
+
+// dkgTransport delivers messages between the parties, it can be backed by anything (TCP, a message bus, files, ...)
+type dkgTransport interface {
+	// Send broadcasts msg to all other parties
+	Send(msg []byte) error
+	// Receive returns the next message broadcast by another party, in the order they were sent
+	Receive() ([]byte, error)
+}
+
+// newTrivialSRS returns the parameters for alpha = 1, which is the state before the first contribution
+func newTrivialSRS(e *bls.Engine) ([2 * n]*bls.PointG1, [n]*bls.PointG2) {
+	var pp1 [2 * n]*bls.PointG1
+	var pp2 [n]*bls.PointG2
+	for i := 0; i < 2*n; i++ {
+		if i == n {
+			pp1[i] = e.G1.Zero()
+		} else {
+			pp1[i] = e.G1.One()
+		}
+	}
+	for i := 0; i < n; i++ {
+		pp2[i] = e.G2.One()
+	}
+	return pp1, pp2
+}
+
+// dkgChallenge is the Fiat-Shamir challenge of the proof of knowledge, bound to the previous state
+func dkgChallenge(e *bls.Engine, party uint32, prev *bls.PointG1, s *bls.PointG1, r *bls.PointG1) fr {
+	t := NewTranscript(dkgDomain)
+	t.AppendUint32("party", party)
+	t.AppendG1("previous", prev)
+	t.AppendG1("S", s)
+	t.AppendG1("R", r)
+	return frFromBig(t.ChallengeScalar("c"))
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine owned by the calling party
+		2. the current parameters pp1, pp2
+		3. the index of the contributing party
+	It samples a fresh secret s, returns the parameters raised to the powers of s and erases s
+*/
+func contribute(e *bls.Engine, pp1 [2 * n]*bls.PointG1, pp2 [n]*bls.PointG2, party uint32) *dkgContribution {
+	q := e.G1.Q()
+	s := big.NewInt(0)
+	for s.Sign() == 0 {
+		s = generateBigIntegerArray(1, q)[0]
+	}
+	msg := &dkgContribution{Party: party}
+	// power runs through s^{i + 1}
+	sf := frFromBig(s)
+	power := sf
+	for i := 0; i < 2*n; i++ {
+		p := power.big()
+		c := e.G1.New()
+		if i != n {
+			e.G1.MulScalar(c, pp1[i], p)
+		}
+		msg.PP1[i] = c
+		if i < n {
+			c2 := e.G2.New()
+			e.G2.MulScalar(c2, pp2[i], p)
+			msg.PP2[i] = c2
+		}
+		p.SetInt64(0)
+		power.mul(power, sf)
+	}
+	batchAffineG1(msg.PP1[:])
+	batchAffineG2(msg.PP2[:])
+	// Schnorr proof of knowledge of s
+	k := generateBigIntegerArray(1, q)[0]
+	msg.S = e.G1.MulScalar(e.G1.New(), e.G1.One(), s)
+	msg.R = e.G1.MulScalar(e.G1.New(), e.G1.One(), k)
+	c := dkgChallenge(e, party, pp1[0], msg.S, msg.R)
+	var z fr
+	z.mul(c, sf)
+	z.add(z, frFromBig(k))
+	msg.Z = z.big()
+	// big.Int gives no guarantee the memory is wiped, but at least we do not keep the secrets around
+	s.SetInt64(0)
+	k.SetInt64(0)
+	sf, power, z = fr{}, fr{}, fr{}
+	return msg
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine owned by the calling party
+		2. the parameters pp1, pp2 before the contribution
+		3. the contribution
+	It returns an error if the contribution is not a valid re-randomisation of the previous parameters
+*/
+func verifyContribution(e *bls.Engine, pp1 [2 * n]*bls.PointG1, pp2 [n]*bls.PointG2, msg *dkgContribution) error {
+	if !e.G1.InCorrectSubgroup(msg.S) || !e.G1.InCorrectSubgroup(msg.R) {
+		return errors.New("proof of knowledge is not in the correct subgroup")
+	}
+	if err := checkSubgroups(e, msg.PP1, msg.PP2); err != nil {
+		return err
+	}
+	// g1^Z = R + c * S
+	c := dkgChallenge(e, msg.Party, pp1[0], msg.S, msg.R)
+	lhs := e.G1.MulScalar(e.G1.New(), e.G1.One(), msg.Z)
+	rhs := mulG1(e.G1, e.G1.New(), msg.S, c)
+	e.G1.Add(rhs, rhs, msg.R)
+	if !e.G1.Equal(lhs, rhs) {
+		return errors.New("invalid proof of knowledge")
+	}
+	// the new alpha is the old one times s: e(pp1'[0], g2) = e(g1^s, pp2[0])
+	if !pairingsEqual(e, msg.PP1[0], e.G2.One(), msg.S, pp2[0]) {
+		return errors.New("contribution does not build on the previous parameters")
+	}
+	return checkPowers(e, msg.PP1, msg.PP2)
+}
+
+// marshal encodes the contribution as party || pp1 || pp2 || S || R || Z
+func (msg *dkgContribution) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.BigEndian, msg.Party)
+	for i := 0; i < 2*n; i++ {
+		if err := writeG1(&buf, msg.PP1[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < n; i++ {
+		if err := writeG2(&buf, msg.PP2[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeG1(&buf, msg.S); err != nil {
+		return nil, err
+	}
+	if err := writeG1(&buf, msg.R); err != nil {
+		return nil, err
+	}
+	if err := writeScalar(&buf, msg.Z); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalContribution decodes a message produced by marshal
+func unmarshalContribution(data []byte) (*dkgContribution, error) {
+	r := bytes.NewReader(data)
+	msg := &dkgContribution{}
+	var err error
+	if err = binary.Read(r, binary.BigEndian, &msg.Party); err != nil {
+		return nil, err
+	}
+	for i := 0; i < 2*n; i++ {
+		if msg.PP1[i], err = readG1(r); err != nil {
+			return nil, fmt.Errorf("pp1[%d]: %w", i, err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if msg.PP2[i], err = readG2(r); err != nil {
+			return nil, fmt.Errorf("pp2[%d]: %w", i, err)
+		}
+	}
+	if msg.S, err = readG1(r); err != nil {
+		return nil, err
+	}
+	if msg.R, err = readG1(r); err != nil {
+		return nil, err
+	}
+	if msg.Z, err = readScalar(r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("trailing bytes after contribution")
+	}
+	return msg, nil
+}
+
+/*
+	It takes the following arguments:
+		1. the index of this party, 0 <= party < parties
+		2. the total number of parties
+		3. a transport connecting this party to all others
+	It runs the whole ceremony from this party's point of view and returns the final pp1 and pp2,
+	which are the same for every honest party. Each party uses its own engine, so several parties
+	can run in the same process.
+*/
+func runDKG(party int, parties int, t dkgTransport) ([2 * n]*bls.PointG1, [n]*bls.PointG2, error) {
+	if !(0 <= party && party < parties) {
+		panic("out of range party")
+	}
+	e := bls.NewPairingEngine()
+	pp1, pp2 := newTrivialSRS(e)
+	for j := 0; j < parties; j++ {
+		var msg *dkgContribution
+		if j == party {
+			msg = contribute(e, pp1, pp2, uint32(party))
+			data, err := msg.marshal()
+			if err != nil {
+				return pp1, pp2, err
+			}
+			if err := t.Send(data); err != nil {
+				return pp1, pp2, err
+			}
+		} else {
+			data, err := t.Receive()
+			if err != nil {
+				return pp1, pp2, err
+			}
+			if msg, err = unmarshalContribution(data); err != nil {
+				return pp1, pp2, fmt.Errorf("party %d: %w", j, err)
+			}
+			if msg.Party != uint32(j) {
+				return pp1, pp2, fmt.Errorf("expected contribution of party %d, got party %d", j, msg.Party)
+			}
+			if err := verifyContribution(e, pp1, pp2, msg); err != nil {
+				return pp1, pp2, fmt.Errorf("party %d: %w", j, err)
+			}
+		}
+		pp1, pp2 = msg.PP1, msg.PP2
+	}
+	return pp1, pp2, nil
+}
+
+// localTransport connects parties running in the same process through channels
+type localTransport struct {
+	self    int
+	inboxes []chan []byte
+	// broadcasts are atomic, so every party sees the messages in the same order
+	mu *sync.Mutex
+}
+
+// newLocalDKGNetwork returns one connected transport per party, mostly useful for demos and local testing
+func newLocalDKGNetwork(parties int) []dkgTransport {
+	inboxes := make([]chan []byte, parties)
+	for i := range inboxes {
+		// every party broadcasts exactly once, so sends never block
+		inboxes[i] = make(chan []byte, parties)
+	}
+	mu := &sync.Mutex{}
+	res := make([]dkgTransport, parties)
+	for i := range res {
+		res[i] = &localTransport{self: i, inboxes: inboxes, mu: mu}
+	}
+	return res
+}
+
+func (t *localTransport) Send(msg []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, inbox := range t.inboxes {
+		if i != t.self {
+			inbox <- msg
+		}
+	}
+	return nil
+}
+
+func (t *localTransport) Receive() ([]byte, error) {
+	return <-t.inboxes[t.self], nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// TestDKG runs a ceremony of three parties in one process and checks they end with the same
+// well-formed parameters
+func TestDKG(t *testing.T) {
+	if testing.Short() {
+		t.Skip("every party raises and checks all the powers")
+	}
+	const parties = 3
+	transports := newLocalDKGNetwork(parties)
+	pp1s := make([][2 * n]*bls.PointG1, parties)
+	pp2s := make([][n]*bls.PointG2, parties)
+	errs := make([]error, parties)
+	var wg sync.WaitGroup
+	for p := 0; p < parties; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			pp1s[p], pp2s[p], errs[p] = runDKG(p, parties, transports[p])
+		}(p)
+	}
+	wg.Wait()
+	for p, err := range errs {
+		if err != nil {
+			t.Fatalf("party %d: %v", p, err)
+		}
+	}
+	e := bls.NewPairingEngine()
+	for p := 1; p < parties; p++ {
+		for i := range pp1s[0] {
+			if !e.G1.Equal(pp1s[p][i], pp1s[0][i]) {
+				t.Fatalf("parties 0 and %d disagree on pp1[%d]", p, i)
+			}
+		}
+		for i := range pp2s[0] {
+			if !e.G2.Equal(pp2s[p][i], pp2s[0][i]) {
+				t.Fatalf("parties 0 and %d disagree on pp2[%d]", p, i)
+			}
+		}
+	}
+	if e.G1.Equal(pp1s[0][0], e.G1.One()) {
+		t.Fatal("the ceremony left alpha = 1")
+	}
+	if err := checkPowers(e, pp1s[0], pp2s[0]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDKGRejects checks verifyContribution turns down tampered proofs of knowledge and powers, and
+// runDKG a contribution out of turn
+func TestDKGRejects(t *testing.T) {
+	e := bls.NewPairingEngine()
+	pp1, pp2 := newTrivialSRS(e)
+	msg := contribute(e, pp1, pp2, 0)
+	if err := verifyContribution(e, pp1, pp2, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	wrongS := *msg
+	wrongS.S = e.G1.Add(e.G1.New(), msg.S, e.G1.One())
+	wrongZ := *msg
+	wrongZ.Z = new(big.Int).Add(msg.Z, big.NewInt(1))
+	// the arrays are copied with the struct, the original stays intact
+	nonPower := *msg
+	nonPower.PP1[3], nonPower.PP1[4] = msg.PP1[4], msg.PP1[3]
+	otherParty := *msg
+	otherParty.Party = 1
+	for name, bad := range map[string]*dkgContribution{
+		"wrong S": &wrongS, "wrong Z": &wrongZ, "non-power pp1": &nonPower, "other party": &otherParty,
+	} {
+		if err := verifyContribution(e, pp1, pp2, bad); err == nil {
+			t.Fatalf("%s: contribution accepted", name)
+		}
+	}
+
+	// party 0 of three expects party 1 next and gets the contribution of party 2
+	next := contribute(e, msg.PP1, msg.PP2, 2)
+	data, err := next.marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = runDKG(0, 3, &scriptedTransport{received: [][]byte{data}})
+	if err == nil || !strings.Contains(err.Error(), "expected contribution of party 1") {
+		t.Fatalf("contribution out of turn: %v", err)
+	}
+}
+
+// scriptedTransport drops what is sent and hands out the given messages in order
+type scriptedTransport struct {
+	received [][]byte
+}
+
+func (t *scriptedTransport) Send(msg []byte) error {
+	return nil
+}
+
+func (t *scriptedTransport) Receive() ([]byte, error) {
+	if len(t.received) == 0 {
+		return nil, errors.New("no more messages")
+	}
+	msg := t.received[0]
+	t.received = t.received[1:]
+	return msg, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Emptiness proofs. In key-value use a position holding 0 is an absent key, so proving m_i = 0 proves
+	non-membership. With m_i = 0 the target of the verification equation is the identity,
+		e(C, g_2^{alpha^{n+1-i}}) = e(pi, g_2)
+	and the proof is the plain proof of index i. Serialized it is
+		kind || fingerprint || i || pi
+	with i as a big endian uint32 and pi compressed, 61 bytes.
+*/
+
+// EmptinessProof proves that the entry at Index is zero
+type EmptinessProof struct {
+	Index int
+	Proof *bls.PointG1
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the message vector
+		4. an index holding zero
+	It returns the proof that the entry at index is zero, it panics if it is not.
+*/
+func ProveEmpty(message []*big.Int, index int) *EmptinessProof {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	if len(message) == n && message[index].Sign() != 0 {
+		panic("the entry is not zero")
+	}
+	return &EmptinessProof{Index: index, Proof: ProveSet(message, []int{index})[0]}
+}
+
+// VerifyEmpty reports whether p proves that the entry of com at p.Index is zero
+func VerifyEmpty(com *bls.PointG1, p *EmptinessProof) bool {
+	return verifySingleProof(com, new(big.Int), p.Proof, p.Index)
+}
+
+// encodeEmptinessProof serializes an emptiness proof
+func encodeEmptinessProof(p *EmptinessProof) []byte {
+	if !(0 <= p.Index && p.Index < n) {
+		panic("out of range index")
+	}
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer cannot fail
+	_ = writeArtifactHeader(&buf, artifactEmptinessProof)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(p.Index))
+	_ = writeCompressedG1(&buf, p.Proof)
+	return buf.Bytes()
+}
+
+// decodeEmptinessProof parses an emptiness proof produced under the installed parameters
+func decodeEmptinessProof(data []byte) (*EmptinessProof, error) {
+	size := 1 + fingerprintSize + 4 + g1CompressedSize
+	if len(data) != size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", size, len(data))
+	}
+	r := bytes.NewReader(data)
+	if err := readArtifactHeader(r, artifactEmptinessProof); err != nil {
+		return nil, err
+	}
+	var index uint32
+	// the length was checked above
+	_ = binary.Read(r, binary.BigEndian, &index)
+	if index >= n {
+		return nil, fmt.Errorf("index %d out of range", index)
+	}
+	proof, err := readCompressedG1(r)
+	if err != nil {
+		return nil, err
+	}
+	return &EmptinessProof{Index: int(index), Proof: proof}, nil
+}
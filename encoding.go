@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	gnark "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// sizes of the encodings used whenever points or scalars leave the process
+const (
+	// uncompressed x || y, as produced by G1.ToBytes
+	g1Size = 96
+	// uncompressed x || y over Fp2, as produced by G2.ToBytes
+	g2Size = 192
+	// big endian scalar modulo the group order
+	scalarSize = 32
+	// compressed x with the flags in the top bits, the zcash encoding also used by gnark-crypto and blst
+	g1CompressedSize = 48
+)
+
+// The helpers below borrow group instances from the pools of pool.go instead of using the global
+// engine, so they are safe to call from several goroutines at once.
+
+// writeG1 writes a G1 point in uncompressed form, the point at infinity is all zeros
+func writeG1(w io.Writer, p *bls.PointG1) error {
+	g := getG1()
+	defer putG1(g)
+	_, err := w.Write(g.ToBytes(p))
+	return err
+}
+
+// readG1 reads a G1 point written by writeG1, it only checks the point is on the curve
+func readG1(r io.Reader) (*bls.PointG1, error) {
+	buf := make([]byte, g1Size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	g := getG1()
+	defer putG1(g)
+	return g.FromBytes(buf)
+}
+
+// writeCompressedG1 writes a G1 point in compressed form
+func writeCompressedG1(w io.Writer, p *bls.PointG1) error {
+	b := toGnarkG1s([]*bls.PointG1{p})[0].Bytes()
+	_, err := w.Write(b[:])
+	return err
+}
+
+// readCompressedG1 reads a G1 point written by writeCompressedG1, recovering y takes a square root. Unlike
+// readG1 it also checks the point lies in the correct subgroup
+func readCompressedG1(r io.Reader) (*bls.PointG1, error) {
+	buf := make([]byte, g1CompressedSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	// the top bit marks the compressed encoding, gnark-crypto would read the rest as uncompressed
+	if buf[0]&0x80 == 0 {
+		return nil, errors.New("point is not compressed")
+	}
+	var p gnark.G1Affine
+	if _, err := p.SetBytes(buf); err != nil {
+		return nil, err
+	}
+	return fromGnarkG1(&p), nil
+}
+
+// writeG2 writes a G2 point in uncompressed form, the point at infinity is all zeros
+func writeG2(w io.Writer, p *bls.PointG2) error {
+	g := getG2()
+	defer putG2(g)
+	_, err := w.Write(g.ToBytes(p))
+	return err
+}
+
+// readG2 reads a G2 point written by writeG2, it only checks the point is on the curve
+func readG2(r io.Reader) (*bls.PointG2, error) {
+	buf := make([]byte, g2Size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	g := getG2()
+	defer putG2(g)
+	return g.FromBytes(buf)
+}
+
+// writeScalar writes a scalar in [0, q) as a fixed size big endian integer
+func writeScalar(w io.Writer, s *big.Int) error {
+	if s.Sign() < 0 || s.Cmp(bls.NewG1().Q()) != -1 {
+		return errors.New("scalar does not lie in the field")
+	}
+	buf := make([]byte, scalarSize)
+	s.FillBytes(buf)
+	_, err := w.Write(buf)
+	return err
+}
+
+// readScalar reads a scalar written by writeScalar and rejects non-canonical values
+func readScalar(r io.Reader) (*big.Int, error) {
+	buf := make([]byte, scalarSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	s := new(big.Int).SetBytes(buf)
+	if s.Cmp(bls.NewG1().Q()) != -1 {
+		return nil, errors.New("scalar does not lie in the field")
+	}
+	return s, nil
+}
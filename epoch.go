@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Epochs. A commitment that changes over time is published once per epoch together with the batch of
+	updates leading to the next one. Since both commitments and proofs follow updates homomorphically
+	(see update.go), a proof issued at epoch e is carried to epoch e + k by applying the k batches
+	published in between, one MSM over the changed entries per batch, without the message. The epoch
+	travels with the commitment and the proof, so a proof is never checked against a commitment of
+	another epoch.
+*/
+
+// UpdateBatch is the set of changes turning the commitment of Epoch into that of Epoch + 1
+type UpdateBatch struct {
+	Epoch   uint64
+	Updates []Update
+}
+
+// EpochCommitment is a commitment at an epoch
+type EpochCommitment struct {
+	Epoch      uint64
+	Commitment *Commitment
+}
+
+// NewEpochCommitment returns the commitment to the message at epoch 0
+func NewEpochCommitment(message []*big.Int) *EpochCommitment {
+	return &EpochCommitment{Commitment: (*Commitment)(commit(message))}
+}
+
+// Advance applies the batch of the current epoch and moves to the next epoch
+func (c *EpochCommitment) Advance(batch UpdateBatch) error {
+	if batch.Epoch != c.Epoch {
+		return fmt.Errorf("the batch leads from epoch %d, the commitment is at epoch %d", batch.Epoch, c.Epoch)
+	}
+	ApplyUpdates(c.Commitment, batch.Updates)
+	c.Epoch++
+	return nil
+}
+
+// EpochProof is the opening of an entry at an epoch
+type EpochProof struct {
+	Epoch uint64
+	Index int
+	Value *big.Int
+	Proof *Proof
+}
+
+// NewEpochProof returns the proof of the entry at the index for the commitment of the message at the epoch
+func NewEpochProof(message []*big.Int, index int, epoch uint64) *EpochProof {
+	return &EpochProof{
+		Epoch: epoch,
+		Index: index,
+		Value: new(big.Int).Set(message[index]),
+		Proof: (*Proof)(ProveSet(message, []int{index})[0]),
+	}
+}
+
+/*
+	It takes the following arguments:
+		1. pp1 (implicitly)
+		2. the batches published since the epoch of the proof, in order
+	It turns the proof into that of the same index at the epoch after the last batch, updating the value
+	with the changes of the index itself. On error the proof is left unchanged.
+*/
+func (p *EpochProof) AdvanceEpoch(batches []UpdateBatch) error {
+	for k, batch := range batches {
+		if batch.Epoch != p.Epoch+uint64(k) {
+			return fmt.Errorf("batch %d leads from epoch %d, expected epoch %d", k, batch.Epoch, p.Epoch+uint64(k))
+		}
+	}
+	var all []Update
+	for _, batch := range batches {
+		all = append(all, batch.Updates...)
+	}
+	// the batches fold into a single MSM, the updates of each index add up
+	p.Proof.ApplyUpdates(p.Index, all)
+	for _, u := range all {
+		if u.Index == p.Index {
+			p.Value.Add(p.Value, u.Delta)
+			p.Value.Mod(p.Value, frModulus)
+		}
+	}
+	p.Epoch += uint64(len(batches))
+	return nil
+}
+
+// VerifyEpochProof reports whether the proof opens the commitment, both have to be at the same epoch
+func VerifyEpochProof(com *EpochCommitment, p *EpochProof) bool {
+	if com.Epoch != p.Epoch {
+		return false
+	}
+	return verifySingleProof(com.Commitment.Point(), p.Value, (*bls.PointG1)(p.Proof), p.Index)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Equality at an index. Two hiding commitments C_1, C_2 agree at index i exactly when their difference
+		D = C_1 - C_2 = \sum (m_{1,j} - m_{2,j}) * pp1[j] + (r_1 - r_2) * h
+	is a hiding commitment opening to 0 at i, so the proof is a hiding proof of 0 for D, computed from the
+	difference of the messages and of the randomness. It reveals neither the common value nor anything
+	about the other entries. With plain commitments the proof still verifies, but anyone can then test
+	guesses of the value against either commitment.
+*/
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1, pp2 (implicitly)
+		3. the message and randomness of the first hiding commitment
+		4. the message and randomness of the second hiding commitment
+		5. the index where both messages agree
+	It returns a proof that both commitments hold the same entry at the index, a new one at every call.
+*/
+func ProveEqualAt(message1 []*big.Int, r1 *big.Int, message2 []*big.Int, r2 *big.Int, index int) *HidingProof {
+	if len(message1) != n || len(message2) != n {
+		panic("wrong array size")
+	}
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	if message1[index].Cmp(message2[index]) != 0 {
+		panic("the messages differ at the index")
+	}
+	diff := make([]*big.Int, n)
+	for j := range diff {
+		diff[j] = new(big.Int).Sub(message1[j], message2[j])
+		diff[j].Mod(diff[j], frModulus)
+	}
+	r := new(big.Int).Sub(r1, r2)
+	return ProveHiding(diff, r.Mod(r, frModulus), index)
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the two hiding commitments
+		4. the proof of ProveEqualAt
+		5. index
+	It reports whether the proof shows that both commitments hold the same entry at the index.
+*/
+func VerifyEqualAt(com1, com2 *bls.PointG1, proof *HidingProof, index int) bool {
+	g := getG1()
+	defer putG1(g)
+	return VerifyHiding(g.Sub(g.New(), com1, com2), new(big.Int), proof, index)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// srsFetcher downloads published parameter files and only accepts them once they are authenticated
+type srsFetcher struct {
+	// mirrors tried in order until one serves an authentic file
+	URLs []string
+	// hex encoded SHA-256 of the parameter file, checked when not empty
+	Digest string
+	// maintainer key, when set the detached ed25519 signature served at <url>.sig must verify
+	PublicKey ed25519.PublicKey
+	// defaults to a client with a one minute timeout
+	Client *http.Client
+}
+
+/*
+	It downloads the parameters from the first mirror that serves a file matching the pinned digest
+	and/or carrying a valid maintainer signature, and parses it into PublicParams. At least one of
+	the two checks has to be configured, an unauthenticated download is refused. The parsed
+	parameters are also checked to be well-formed powers of a single alpha.
+*/
+func (f *srsFetcher) fetch() (*PublicParams, error) {
+	if f.Digest == "" && f.PublicKey == nil {
+		return nil, errors.New("refusing to fetch parameters without a pinned digest or a maintainer key")
+	}
+	var digest []byte
+	if f.Digest != "" {
+		var err error
+		if digest, err = hex.DecodeString(f.Digest); err != nil || len(digest) != sha256.Size {
+			return nil, errors.New("pinned digest is not a hex encoded SHA-256 hash")
+		}
+	}
+	if f.PublicKey != nil && len(f.PublicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid maintainer key")
+	}
+	if len(f.URLs) == 0 {
+		return nil, errors.New("no parameter URLs configured")
+	}
+	var errs []string
+	for _, u := range f.URLs {
+		pp, err := f.fetchFrom(u, digest)
+		if err == nil {
+			return pp, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", u, err))
+	}
+	return nil, fmt.Errorf("no mirror served valid parameters:\n\t%s", strings.Join(errs, "\n\t"))
+}
+
+// fetchFrom downloads, authenticates and parses the parameters served at a single URL
+func (f *srsFetcher) fetchFrom(u string, digest []byte) (*PublicParams, error) {
+	data, err := f.download(u, paramsMaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+	if digest != nil {
+		sum := sha256.Sum256(data)
+		if !bytes.Equal(sum[:], digest) {
+			return nil, fmt.Errorf("digest mismatch, got %x", sum)
+		}
+	}
+	if f.PublicKey != nil {
+		sig, err := f.download(u+".sig", ed25519.SignatureSize)
+		if err != nil {
+			return nil, fmt.Errorf("signature: %w", err)
+		}
+		if len(sig) != ed25519.SignatureSize {
+			return nil, errors.New("signature: truncated")
+		}
+		if !ed25519.Verify(f.PublicKey, data, sig) {
+			return nil, errors.New("invalid maintainer signature")
+		}
+	}
+	return parseFetchedParams(data)
+}
+
+// parseFetchedParams parses authenticated parameters and checks them with checkParams
+func parseFetchedParams(data []byte) (*PublicParams, error) {
+	pp, err := readPublicParams(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkParams(pp); err != nil {
+		return nil, err
+	}
+	return pp, nil
+}
+
+// download fetches a http(s) resource with the client of f, see httpDownload
+func (f *srsFetcher) download(u string, maxSize int) ([]byte, error) {
+	return httpDownload(f.Client, u, maxSize)
+}
+
+// httpDownload fetches a http(s) resource and rejects anything longer than maxSize bytes. The client
+// defaults to one with a one minute timeout
+func httpDownload(client *http.Client, u string, maxSize int) ([]byte, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+	// integrity comes from what the caller checks (digests, signatures, CIDs), not from the channel, but
+	// nothing else is allowed
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return nil, fmt.Errorf("unsupported scheme %q", parsed.Scheme)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	// never read more than we expect, a hostile mirror could serve an endless body
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf("expected at most %d bytes, got more", maxSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"math/big"
+	"math/bits"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// the scalar field of BLS12-381 has 2^32 as its largest power of two subgroup, generated from 7
+const (
+	frTwoAdicity    = 32
+	frMultGenerator = 7
+	// bit length of the group order
+	frBitLen = 255
+)
+
+// rootOfUnity returns a primitive size-th root of unity in the scalar field, size is a power of two
+func rootOfUnity(size int) fr {
+	if size <= 0 || size&(size-1) != 0 || bits.TrailingZeros(uint(size)) > frTwoAdicity {
+		panic("domain size has to be a power of two of at most 2^32")
+	}
+	exp := new(big.Int).Sub(frModulus, big.NewInt(1))
+	exp.Div(exp, big.NewInt(int64(size)))
+	var omega fr
+	return *omega.exp(frFromUint64(frMultGenerator), exp)
+}
+
+// twiddles returns omega^0, ..., omega^{size/2 - 1}
+func twiddles(omega fr, size int) []fr {
+	res := make([]fr, size/2)
+	w := frOne
+	for i := range res {
+		res[i] = w
+		w.mul(w, omega)
+	}
+	return res
+}
+
+// bitReverse permutes a in place so that a[i] ends up at the bit reversal of i, len(a) is a power of two
+func bitReverse[T any](a []T) {
+	shift := 64 - bits.TrailingZeros(uint(len(a)))
+	for i := range a {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+}
+
+// fftScalars replaces a by its evaluations a'[k] = \sum_i a[i] omega^{ik}, omega being a primitive len(a)-th root of unity
+func fftScalars(a []fr, omega fr) {
+	size := len(a)
+	tw := twiddles(omega, size)
+	bitReverse(a)
+	var t fr
+	for m := 2; m <= size; m <<= 1 {
+		step := size / m
+		for start := 0; start < size; start += m {
+			for j := 0; j < m/2; j++ {
+				u := a[start+j]
+				t.mul(a[start+j+m/2], tw[j*step])
+				a[start+j].add(u, t)
+				a[start+j+m/2].sub(u, t)
+			}
+		}
+	}
+}
+
+// fftG1 replaces a by a'[k] = \sum_i omega^{ik} a[i], which is fftScalars carried out in the exponent
+func fftG1(g *bls.G1, a []*bls.PointG1, omega fr) {
+	size := len(a)
+	tw := twiddles(omega, size)
+	bitReverse(a)
+	t := g.New()
+	for m := 2; m <= size; m <<= 1 {
+		step := size / m
+		for start := 0; start < size; start += m {
+			for j := 0; j < m/2; j++ {
+				u, v := a[start+j], a[start+j+m/2]
+				// the first twiddle is always one
+				if j == 0 {
+					t.Set(v)
+				} else {
+					mulG1(g, t, v, tw[j*step])
+				}
+				a[start+j] = g.Add(g.New(), u, t)
+				a[start+j+m/2] = g.Sub(g.New(), u, t)
+			}
+		}
+	}
+}
+
+// ifftScalars is the inverse of fftScalars
+func ifftScalars(a []fr, omega fr) {
+	var omegaInv, sizeInv fr
+	fftScalars(a, *omegaInv.inverse(omega))
+	sizeInv.inverse(frFromUint64(uint64(len(a))))
+	for i := range a {
+		a[i].mul(a[i], sizeInv)
+	}
+}
+
+// ifftG1 is the inverse of fftG1
+func ifftG1(g *bls.G1, a []*bls.PointG1, omega fr) {
+	var omegaInv, sizeInv fr
+	fftG1(g, a, *omegaInv.inverse(omega))
+	sizeInv.inverse(frFromUint64(uint64(len(a))))
+	for i := range a {
+		a[i] = mulG1(g, g.New(), a[i], sizeInv)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"sort"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Non-interactive aggregation. aggregateProof and verifySameCommitmentAggregation take the scalars t_i
+	from the caller, and a prover free to pick them can make a wrong opening pass. The paper derives them
+	as t_i = H(C, S, m[S], i) instead, so that neither side chooses them and any two implementations
+	agree. Here they are the challenges "t" of a transcript (see transcript.go) of domain
+	sameCommitmentDomain that absorbed
+		"C": C, "|S|": |S|, then "i": i, "m_i": m_i for i in S in increasing order of i
+	one challenge per index in that order. Across commitments every C_j is aggregated with its own
+	t_{j,i} as above, and the results with the challenges "t'" of a transcript of domain
+	crossCommitmentDomain that absorbed "m": m, then the above for every commitment in canonical order:
+	increasing in the uncompressed encoding of C_j, then in the sorted indices and their values.
+	The scalar of an opening therefore depends on the claims alone and not on the order they are handed
+	over in, so aggregates of the same claims are byte-identical whoever computes them, and verifiers
+	accept the claims in any order.
+*/
+
+// checkOpened panics unless the indices are distinct and in range and the values lie in the field
+func checkOpened(indices []int, values []*big.Int) {
+	if len(indices) != len(values) {
+		panic("arrays with incorrect length")
+	}
+	seen := make(map[int]bool, len(indices))
+	for k, i := range indices {
+		if !(0 <= i && i < n) {
+			panic("out of range index")
+		}
+		if seen[i] {
+			panic("duplicate index")
+		}
+		seen[i] = true
+		if values[k].Sign() < 0 || values[k].Cmp(frModulus) != -1 {
+			panic("the message does not lie in the group")
+		}
+	}
+}
+
+// indexOrder returns the positions of the indices in increasing order of the indices
+func indexOrder(indices []int) []int {
+	order := make([]int, len(indices))
+	for k := range order {
+		order[k] = k
+	}
+	sort.Slice(order, func(a, b int) bool { return indices[order[a]] < indices[order[b]] })
+	return order
+}
+
+// appendOpenings absorbs the opened indices and values, which have passed checkOpened, in increasing
+// order of the indices
+func appendOpenings(t *Transcript, indices []int, values []*big.Int) {
+	t.AppendUint32("|S|", uint32(len(indices)))
+	for _, k := range indexOrder(indices) {
+		t.AppendUint32("i", uint32(indices[k]))
+		t.AppendScalar("m_i", values[k])
+	}
+}
+
+// appendOpened absorbs the commitment and its opened indices and values, which have passed checkOpened
+func appendOpened(t *Transcript, com *bls.PointG1, indices []int, values []*big.Int) {
+	t.AppendG1("C", com)
+	appendOpenings(t, indices, values)
+}
+
+// challengesInOrder returns the challenges of t for the positions listed by order, the k-th challenge
+// going to position order[k]
+func challengesInOrder(t *Transcript, label string, order []int) []*big.Int {
+	challenges := t.ChallengeScalars(label, len(order))
+	res := make([]*big.Int, len(order))
+	for k, pos := range order {
+		res[pos] = challenges[k]
+	}
+	return res
+}
+
+// sameCommitmentScalars derives the aggregation scalars of the openings of com at indices to values,
+// res[k] belonging to indices[k]. The indices have to be distinct and in range and the values in the field
+func sameCommitmentScalars(com *bls.PointG1, indices []int, values []*big.Int) []*big.Int {
+	checkOpened(indices, values)
+	t := NewTranscript(sameCommitmentDomain)
+	appendOpened(t, com, indices, values)
+	return challengesInOrder(t, "t", indexOrder(indices))
+}
+
+// openedKey encodes the commitment and its openings so that comparing the keys byte by byte gives the
+// canonical order of the commitments, see above
+func openedKey(g *bls.G1, com *bls.PointG1, indices []int, values []*big.Int) []byte {
+	var key bytes.Buffer
+	key.Write(g.ToBytes(com))
+	for _, k := range indexOrder(indices) {
+		// fixed size fields keep the keys comparable
+		_ = binary.Write(&key, binary.BigEndian, uint32(indices[k]))
+		key.Write(values[k].FillBytes(make([]byte, 32)))
+	}
+	return key.Bytes()
+}
+
+// keyOrder returns the positions of the keys in increasing order of the keys
+func keyOrder(keys [][]byte) []int {
+	order := make([]int, len(keys))
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func(a, b int) bool { return bytes.Compare(keys[order[a]], keys[order[b]]) < 0 })
+	return order
+}
+
+// commitmentOrder returns the positions of the commitments in canonical order
+func commitmentOrder(coms []*bls.PointG1, indices [][]int, values [][]*big.Int) []int {
+	g := getG1()
+	defer putG1(g)
+	keys := make([][]byte, len(coms))
+	for j, com := range coms {
+		keys[j] = openedKey(g, com, indices[j], values[j])
+	}
+	return keyOrder(keys)
+}
+
+// crossCommitmentScalars derives the scalars t'_j of the commitments, the openings have to be valid
+// input of sameCommitmentScalars
+func crossCommitmentScalars(coms []*bls.PointG1, indices [][]int, values [][]*big.Int) []*big.Int {
+	if !(len(indices) == len(coms) && len(values) == len(coms)) {
+		panic("arrays with incorrect length")
+	}
+	for j := range coms {
+		checkOpened(indices[j], values[j])
+	}
+	order := commitmentOrder(coms, indices, values)
+	t := NewTranscript(crossCommitmentDomain)
+	t.AppendUint32("m", uint32(len(coms)))
+	for _, j := range order {
+		appendOpened(t, coms[j], indices[j], values[j])
+	}
+	return challengesInOrder(t, "t'", order)
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. the commitment
+		3. the openings of it, with distinct indices
+	It returns the aggregation of the proofs with the scalars derived from the commitment and the
+	opened indices and values, which VerifySameCommitment checks.
+*/
+func AggregateSameCommitment(com *bls.PointG1, openings []Opening) *bls.PointG1 {
+	observeBatch("aggregate_same", len(openings))
+	return profiled("aggregate_same", func() *bls.PointG1 {
+		indices := make([]int, len(openings))
+		values := make([]*big.Int, len(openings))
+		proofs := make([]*bls.PointG1, len(openings))
+		for k, o := range openings {
+			indices[k], values[k], proofs[k] = o.Index, o.Value, o.Proof
+		}
+		return aggregateProof(proofs, sameCommitmentScalars(com, indices, values), len(openings))
+	})
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the commitment
+		4. the opened indices, distinct and in any order
+		5. the values at these indices
+		6. the aggregated proof of AggregateSameCommitment
+	It reports whether the proof opens the commitment to the values at the indices.
+*/
+func VerifySameCommitment(com *bls.PointG1, indices []int, values []*big.Int, proof *bls.PointG1) bool {
+	scalars := sameCommitmentScalars(com, indices, values)
+	return verifySameCommitmentAggregation(com, proof, values, scalars, indices, len(indices))
+}
+
+// CommitmentOpenings are openings of a single commitment, as aggregated across commitments
+type CommitmentOpenings struct {
+	Commitment *bls.PointG1
+	Openings   []Opening
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. the openings of every commitment, with distinct indices per commitment
+	It aggregates the openings of every commitment like AggregateSameCommitment and the results with
+	the derived t'_j into a single proof, which VerifyCrossCommitment checks.
+*/
+func AggregateCrossCommitment(groups []CommitmentOpenings) *bls.PointG1 {
+	total := 0
+	for _, group := range groups {
+		total += len(group.Openings)
+	}
+	observeBatch("aggregate_cross", total)
+	return profiled("aggregate_cross", func() *bls.PointG1 {
+		coms := make([]*bls.PointG1, len(groups))
+		indices := make([][]int, len(groups))
+		values := make([][]*big.Int, len(groups))
+		partial := make([]*bls.PointG1, len(groups))
+		for j, group := range groups {
+			coms[j] = group.Commitment
+			for _, o := range group.Openings {
+				indices[j] = append(indices[j], o.Index)
+				values[j] = append(values[j], o.Value)
+			}
+			partial[j] = AggregateSameCommitment(group.Commitment, group.Openings)
+		}
+		return aggregateProof(partial, crossCommitmentScalars(coms, indices, values), len(groups))
+	})
+}
+
+// newCrossTranscript returns the transcript of the statement with the derived scalars, so it can be
+// checked on its own, serialized or batched with verifyCrossBatch
+func newCrossTranscript(coms []*bls.PointG1, indices [][]int, values [][]*big.Int, proof *bls.PointG1) *crossTranscript {
+	t := &crossTranscript{
+		Commitments: coms,
+		Proof:       proof,
+		Indices:     indices,
+		Values:      values,
+		ComScalars:  crossCommitmentScalars(coms, indices, values),
+	}
+	for j, com := range coms {
+		t.MessageScalars = append(t.MessageScalars, sameCommitmentScalars(com, indices[j], values[j]))
+	}
+	return t
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the commitments
+		4. the opened indices of every commitment, in any order
+		5. the values at these indices
+		6. the proof of AggregateCrossCommitment
+	It reports whether the proof opens every commitment to its values at its indices.
+*/
+func VerifyCrossCommitment(coms []*bls.PointG1, indices [][]int, values [][]*big.Int, proof *bls.PointG1) bool {
+	return newCrossTranscript(coms, indices, values, proof).verify()
+}
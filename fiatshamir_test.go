@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// fixtureOpenings returns the openings of the fixture, in reverse order if reversed is set
+func fixtureOpenings(f *benchFixture, reversed bool) []Opening {
+	openings := make([]Opening, len(f.indices))
+	for k, i := range f.indices {
+		pos := k
+		if reversed {
+			pos = len(openings) - 1 - k
+		}
+		openings[pos] = Opening{Index: i, Value: f.values[k], Proof: f.proofs[k]}
+	}
+	return openings
+}
+
+func TestSameCommitment(t *testing.T) {
+	f := benchSetup(t)
+	g := getG1()
+	defer putG1(g)
+	proof := AggregateSameCommitment(f.com, fixtureOpenings(f, false))
+	if !VerifySameCommitment(f.com, f.indices, f.values, proof) {
+		t.Fatal("aggregated proof rejected")
+	}
+	// the scalars depend on the claims, not on their order
+	if !g.Equal(proof, AggregateSameCommitment(f.com, fixtureOpenings(f, true))) {
+		t.Fatal("aggregation depends on the order of the openings")
+	}
+	reversed := fixtureOpenings(f, true)
+	indices := make([]int, len(reversed))
+	values := make([]*big.Int, len(reversed))
+	for k, o := range reversed {
+		indices[k], values[k] = o.Index, o.Value
+	}
+	if !VerifySameCommitment(f.com, indices, values, proof) {
+		t.Fatal("aggregated proof rejected for the claims in another order")
+	}
+
+	wrong := append([]*big.Int(nil), f.values...)
+	wrong[2] = new(big.Int).Add(wrong[2], big.NewInt(1))
+	if VerifySameCommitment(f.com, f.indices, wrong, proof) {
+		t.Fatal("aggregated proof accepted for a wrong value")
+	}
+	if VerifySameCommitment(f.com, f.indices[1:], f.values[1:], proof) {
+		t.Fatal("aggregated proof accepted for a subset of the claims")
+	}
+	// the interactive aggregation with the caller's scalars does not pass as the non-interactive one
+	if VerifySameCommitment(f.com, f.indices, f.values, f.aggregated) {
+		t.Fatal("proof aggregated with other scalars accepted")
+	}
+}
+
+func TestCrossCommitment(t *testing.T) {
+	f := benchSetup(t)
+	g := getG1()
+	defer putG1(g)
+	// the fixture's commitment and two more to other messages
+	var groups []CommitmentOpenings
+	var coms []*bls.PointG1
+	var indices [][]int
+	var values [][]*big.Int
+	for j := 0; j < 3; j++ {
+		message := f.message
+		if j > 0 {
+			message = generateBigIntegerArray(n, frModulus)
+		}
+		idx := []int{j, 100 + j, n - 1 - j}
+		group := CommitmentOpenings{Commitment: commit(message)}
+		var vals []*big.Int
+		for k, proof := range ProveSet(message, idx) {
+			group.Openings = append(group.Openings, Opening{Index: idx[k], Value: message[idx[k]], Proof: proof})
+			vals = append(vals, message[idx[k]])
+		}
+		groups = append(groups, group)
+		coms = append(coms, group.Commitment)
+		indices = append(indices, idx)
+		values = append(values, vals)
+	}
+	proof := AggregateCrossCommitment(groups)
+	if !VerifyCrossCommitment(coms, indices, values, proof) {
+		t.Fatal("aggregated proof rejected")
+	}
+	swapped := []CommitmentOpenings{groups[2], groups[0], groups[1]}
+	if !g.Equal(proof, AggregateCrossCommitment(swapped)) {
+		t.Fatal("aggregation depends on the order of the commitments")
+	}
+	if !VerifyCrossCommitment([]*bls.PointG1{coms[2], coms[0], coms[1]}, [][]int{indices[2], indices[0], indices[1]}, [][]*big.Int{values[2], values[0], values[1]}, proof) {
+		t.Fatal("aggregated proof rejected for the commitments in another order")
+	}
+
+	wrong := [][]*big.Int{values[0], append([]*big.Int(nil), values[1]...), values[2]}
+	wrong[1][0] = new(big.Int).Add(wrong[1][0], big.NewInt(1))
+	if VerifyCrossCommitment(coms, indices, wrong, proof) {
+		t.Fatal("aggregated proof accepted for a wrong value")
+	}
+	// the claims of one commitment attributed to another
+	if VerifyCrossCommitment([]*bls.PointG1{coms[1], coms[0], coms[2]}, indices, values, proof) {
+		t.Fatal("aggregated proof accepted with commitments exchanged")
+	}
+	if VerifyCrossCommitment(coms[:2], indices[:2], values[:2], proof) {
+		t.Fatal("aggregated proof accepted for a subset of the commitments")
+	}
+}
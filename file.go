@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	File commitments. A file is cut into chunks of 31 bytes, each read as a big endian integer (so it lies
+	in the field) and the last one padded with zeros, and the chunks fill as many vectors of n entries as
+	needed, the last one padded with zero entries. The file is committed to by its size and the
+	commitments of the vectors. The proof that the file holds data at the byte range [x, y) gives the
+	bytes of the chunks covering the range that lie outside of it, less than 31 on each side, and for
+	every vector the range spans the proof of OpenRange for its chunks. The verifier rebuilds the chunks
+	from them and data and checks each proof with VerifyRange.
+*/
+
+// fileChunkSize is the number of bytes of the file per entry, 31 bytes always lie in the field
+const fileChunkSize = 31
+
+// FileCommitment commits to a file, it has to come from a trusted source like a digest does
+type FileCommitment struct {
+	Size        int64
+	Commitments []*bls.PointG1
+}
+
+// CommittedFile is a file together with its vectors and commitment
+type CommittedFile struct {
+	data    []byte
+	vectors [][]*big.Int
+	com     FileCommitment
+}
+
+// FileRangeProof proves the bytes of a range of a committed file
+type FileRangeProof struct {
+	// Prefix and Suffix are the bytes of the chunks covering the range before and after it
+	Prefix []byte
+	Suffix []byte
+	// Proofs holds the proof of OpenRange of every vector the chunks lie in, in order
+	Proofs []*bls.PointG1
+}
+
+// fileVectors cuts data into chunks and the chunks into vectors as above
+func fileVectors(data []byte) [][]*big.Int {
+	chunks := (len(data) + fileChunkSize - 1) / fileChunkSize
+	vectors := make([][]*big.Int, (chunks+n-1)/n)
+	for v := range vectors {
+		vectors[v] = make([]*big.Int, n)
+		for i := range vectors[v] {
+			vectors[v][i] = new(big.Int)
+		}
+	}
+	for c := 0; c < chunks; c++ {
+		end := (c + 1) * fileChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := make([]byte, fileChunkSize)
+		copy(chunk, data[c*fileChunkSize:end])
+		vectors[c/n][c%n].SetBytes(chunk)
+	}
+	return vectors
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the reader of the file
+	It reads the file to its end and returns it committed, or the error of the reader.
+*/
+func CommitFile(r io.Reader) (*CommittedFile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f := &CommittedFile{data: data, vectors: fileVectors(data)}
+	f.com = FileCommitment{Size: int64(len(data)), Commitments: CommitMany(f.vectors)}
+	return f, nil
+}
+
+// Commitment returns the commitment to the file
+func (f *CommittedFile) Commitment() FileCommitment {
+	res := FileCommitment{Size: f.com.Size, Commitments: make([]*bls.PointG1, len(f.com.Commitments))}
+	for v, com := range f.com.Commitments {
+		res.Commitments[v] = new(bls.PointG1).Set(com)
+	}
+	return res
+}
+
+// fileCover returns the chunks [a, b) covering the byte range [x, y) of a file of the given size and the
+// bytes [a * 31, end) they cover, it panics unless 0 <= x <= y <= size
+func fileCover(size, x, y int64) (a, b int, start, end int64) {
+	if !(0 <= x && x <= y && y <= size) {
+		panic("invalid byte range")
+	}
+	a = int(x / fileChunkSize)
+	b = int((y + fileChunkSize - 1) / fileChunkSize)
+	start, end = int64(a)*fileChunkSize, int64(b)*fileChunkSize
+	if end > size {
+		end = size
+	}
+	return a, b, start, end
+}
+
+// fileSpans calls span for every vector the chunks [a, b) lie in, with the local range of the chunks in it
+func fileSpans(a, b int, span func(v, lo, hi int)) {
+	for c := a; c < b; {
+		v := c / n
+		hi := (v + 1) * n
+		if hi > b {
+			hi = b
+		}
+		span(v, c-v*n, hi-v*n)
+		c = hi
+	}
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the byte range [x, y) of the file
+	It returns the proof that the file holds its bytes in the range, checked by VerifyFileRange.
+*/
+func (f *CommittedFile) ProveRange(x, y int64) *FileRangeProof {
+	a, b, start, end := fileCover(f.com.Size, x, y)
+	proof := &FileRangeProof{
+		Prefix: append([]byte{}, f.data[start:x]...),
+		Suffix: append([]byte{}, f.data[y:end]...),
+	}
+	fileSpans(a, b, func(v, lo, hi int) {
+		proof.Proofs = append(proof.Proofs, OpenRange(f.vectors[v], lo, hi))
+	})
+	return proof
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the commitment to the file
+		4. the byte range [x, y)
+		5. the bytes claimed to be in the range
+		6. the proof of ProveRange
+	It reports whether the proof shows the file holds data in the range.
+*/
+func VerifyFileRange(com FileCommitment, x, y int64, data []byte, proof *FileRangeProof) bool {
+	if !(0 <= x && x <= y && y <= com.Size) || int64(len(data)) != y-x {
+		return false
+	}
+	a, b, start, end := fileCover(com.Size, x, y)
+	if int64(len(proof.Prefix)) != x-start || int64(len(proof.Suffix)) != end-y {
+		return false
+	}
+	if b > len(com.Commitments)*n {
+		return false
+	}
+	var covered bytes.Buffer
+	covered.Write(proof.Prefix)
+	covered.Write(data)
+	covered.Write(proof.Suffix)
+	vectors := fileVectors(covered.Bytes())
+	chunks := make([]*big.Int, 0, b-a)
+	for _, vector := range vectors {
+		chunks = append(chunks, vector...)
+	}
+	var proofs int
+	ok := true
+	fileSpans(a, b, func(v, lo, hi int) {
+		if !ok || proofs == len(proof.Proofs) {
+			ok = false
+			return
+		}
+		offset := v*n + lo - a
+		ok = VerifyRange(com.Commitments[v], lo, hi, chunks[offset:offset+hi-lo], proof.Proofs[proofs])
+		proofs++
+	})
+	return ok && proofs == len(proof.Proofs)
+}
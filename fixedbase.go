@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Fixed-base scalar multiplication. The bases pp1[i] do not change for the lifetime of the parameters,
+	so the multiples d * 2^{w * k} * pp1[i] can be computed once for every signed w bit digit d and every
+	window k. A scalar multiplication then is one table lookup and addition per window, about 256 / w
+	additions instead of the 255 doublings and 128 additions of MulScalar.
+	The window w is the memory/speed knob: a table holds (256 / w) * 2^{w - 1} points of 144 bytes,
+		w = 4	64 additions,	74 KB per base
+		w = 6	43 additions,	198 KB per base
+		w = 8	32 additions,	590 KB per base
+	and there are 2n bases. Proofs sum n - 1 such products, one per pp1 point. A commitment is better off
+	with msmG1, whose shared buckets beat n table lookups even at w = 10 (about 45ms against 40ms
+	at n = 1024), so commit does not use the tables.
+*/
+
+// fixedBaseTable holds the precomputed multiples of a single base point
+type fixedBaseTable struct {
+	window int
+	// rows[k][d - 1] = d * 2^{window * k} * P for 1 <= d <= 2^{window - 1}
+	rows [][]*bls.PointG1
+}
+
+// newFixedBaseTable precomputes the multiples of p for the given window size in bits
+func newFixedBaseTable(g *bls.G1, p *bls.PointG1, window int) *fixedBaseTable {
+	if window < 1 || window > 16 {
+		panic("window size out of range")
+	}
+	// the same number of windows msmG1 uses, the last one takes the carry of the signed digits
+	t := &fixedBaseTable{window: window, rows: make([][]*bls.PointG1, frBitLen/window+1)}
+	base := g.New().Set(p)
+	for k := range t.rows {
+		row := make([]*bls.PointG1, 1<<(window-1))
+		row[0] = g.New().Set(base)
+		for d := 1; d < len(row); d++ {
+			row[d] = g.New()
+			g.Add(row[d], row[d-1], base)
+		}
+		t.rows[k] = row
+		for j := 0; j < window; j++ {
+			g.Double(base, base)
+		}
+	}
+	return t
+}
+
+// mul sets r = s * P for the base P of the table and returns r, s has to be reduced modulo the group order
+func (t *fixedBaseTable) mul(g *bls.G1, r *bls.PointG1, s *big.Int) *bls.PointG1 {
+	digits := make([]int, len(t.rows))
+	signedDigits(s, t.window, digits)
+	r.Zero()
+	for k, d := range digits {
+		switch {
+		case d > 0:
+			g.Add(r, r, t.rows[k][d-1])
+		case d < 0:
+			g.Sub(r, r, t.rows[k][-d-1])
+		}
+	}
+	return r
+}
+
+// pp1Tables holds a table for every point of the installed pp1, it is nil unless precomputeTables was called
+var pp1Tables []*fixedBaseTable
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the window size in bits, see the table above for the memory it takes
+		4. an optional progress callback
+	It precomputes the tables for every point of pp1, which generateProofSingle uses from then on
+	until other parameters are installed.
+*/
+func precomputeTables(window int, progress progressFunc) {
+	tracker := newProgressTracker(progress, 2*n)
+	tables := make([]*fixedBaseTable, 2*n)
+	for i := 0; i < 2*n; i++ {
+		tables[i] = newFixedBaseTable(engine.G1, pp1Point(i), window)
+		tracker.add(1)
+	}
+	pp1Tables = tables
+}
+
+// pp1Mul sets r = s * pp1[i] with the temporaries of g and returns r, using the precomputed table if there is one
+func pp1Mul(g *bls.G1, r *bls.PointG1, i int, s *big.Int) *bls.PointG1 {
+	if pp1Tables != nil {
+		return pp1Tables[i].mul(g, r, s)
+	}
+	return mulG1(g, r, pp1Point(i), frFromBig(s))
+}
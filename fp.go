@@ -0,0 +1,192 @@
+package main
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+/*
+	Base field arithmetic, just enough to work on the coordinates of go-ethereum's points directly. A
+	coordinate x is stored by go-ethereum in Montgomery form x * 2^384 mod p as six 64 bit limbs, least
+	significant first, and fp uses the very same representation, so (*fp)((*[6]uint64)(&point[0])) reads
+	and writes the X coordinate in place. Like fr.go, but with six limbs.
+*/
+
+// fp is an element of the base field in Montgomery form
+type fp [6]uint64
+
+// the base field modulus p
+var fpP = fp{0xb9feffffffffaaab, 0x1eabfffeb153ffff, 0x6730d2a0f6b0f624, 0x64774b84f38512bf, 0x4b1ba7b6434bacd7, 0x1a0111ea397fe69a}
+
+const (
+	// -p^{-1} mod 2^64
+	fpPInv = 0x89f3fffcfffcfffd
+)
+
+var (
+	// 2^768 mod p, multiplying by it converts into Montgomery form
+	fpR2 = fpLimbs(new(big.Int).Mod(new(big.Int).Lsh(big.NewInt(1), 768), fpModulus))
+	// one in Montgomery form, 2^384 mod p
+	fpOne = fpLimbs(new(big.Int).Mod(new(big.Int).Lsh(big.NewInt(1), 384), fpModulus))
+)
+
+// fpLimbs returns the limbs of 0 <= x < 2^384 without any conversion
+func fpLimbs(x *big.Int) fp {
+	var buf [48]byte
+	x.FillBytes(buf[:])
+	var z fp
+	for i := 0; i < 6; i++ {
+		for _, b := range buf[48-8*(i+1) : 48-8*i] {
+			z[i] = z[i]<<8 | uint64(b)
+		}
+	}
+	return z
+}
+
+// fpFromBig returns x mod p
+func fpFromBig(x *big.Int) fp {
+	if x.Sign() < 0 || x.Cmp(fpModulus) >= 0 {
+		x = new(big.Int).Mod(x, fpModulus)
+	}
+	var z fp
+	z.mul(fpLimbs(x), fpR2)
+	return z
+}
+
+// big returns the value of x in [0, p)
+func (x fp) big() *big.Int {
+	// a multiplication by 1 leaves Montgomery form
+	var y fp
+	y.mul(x, fp{1})
+	words := make([]big.Word, 0, 6)
+	for _, l := range y {
+		words = append(words, big.Word(l))
+	}
+	return new(big.Int).SetBits(words)
+}
+
+func (x fp) isZero() bool {
+	return x[0]|x[1]|x[2]|x[3]|x[4]|x[5] == 0
+}
+
+// reduce subtracts p once if z >= p
+func (z *fp) reduce() {
+	var t fp
+	var b uint64
+	for i := 0; i < 6; i++ {
+		t[i], b = bits.Sub64(z[i], fpP[i], b)
+	}
+	if b == 0 {
+		*z = t
+	}
+}
+
+// add sets z = x + y and returns z
+func (z *fp) add(x, y fp) *fp {
+	// p < 2^381, so the sum does not overflow six limbs
+	var c uint64
+	for i := 0; i < 6; i++ {
+		z[i], c = bits.Add64(x[i], y[i], c)
+	}
+	z.reduce()
+	return z
+}
+
+// sub sets z = x - y and returns z
+func (z *fp) sub(x, y fp) *fp {
+	var b uint64
+	for i := 0; i < 6; i++ {
+		z[i], b = bits.Sub64(x[i], y[i], b)
+	}
+	if b != 0 {
+		var c uint64
+		for i := 0; i < 6; i++ {
+			z[i], c = bits.Add64(z[i], fpP[i], c)
+		}
+	}
+	return z
+}
+
+// mul sets z = x * y and returns z, using the CIOS Montgomery multiplication
+func (z *fp) mul(x, y fp) *fp {
+	var t [8]uint64
+	var c, hi, lo, carry uint64
+	for i := 0; i < 6; i++ {
+		// t += x * y[i]
+		c = 0
+		for j := 0; j < 6; j++ {
+			hi, lo = bits.Mul64(x[j], y[i])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j], c = lo, hi
+		}
+		t[6], carry = bits.Add64(t[6], c, 0)
+		t[7] = carry
+		// t = (t + m * p) / 2^64 with m chosen to clear the lowest limb
+		m := t[0] * fpPInv
+		hi, lo = bits.Mul64(m, fpP[0])
+		_, carry = bits.Add64(lo, t[0], 0)
+		c = hi + carry
+		for j := 1; j < 6; j++ {
+			hi, lo = bits.Mul64(m, fpP[j])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j-1], c = lo, hi
+		}
+		t[5], carry = bits.Add64(t[6], c, 0)
+		t[6] = t[7] + carry
+	}
+	*z = fp{t[0], t[1], t[2], t[3], t[4], t[5]}
+	// p < 2^381 keeps the result below p without a final carry, one subtraction at most
+	z.reduce()
+	return z
+}
+
+// inverse sets z = 1 / x and returns z, x must not be zero. It is only ever called once per batch, so
+// math/big does the work
+func (z *fp) inverse(x fp) *fp {
+	if x.isZero() {
+		panic("inverse of zero")
+	}
+	*z = fpFromBig(new(big.Int).ModInverse(x.big(), fpModulus))
+	return z
+}
+
+// fp2 is an element a[0] + a[1] * u of the quadratic extension with u^2 = -1, stored like go-ethereum's fe2
+type fp2 [2]fp
+
+func (x fp2) isZero() bool {
+	return x[0].isZero() && x[1].isZero()
+}
+
+// mul sets z = x * y and returns z
+func (z *fp2) mul(x, y fp2) *fp2 {
+	var t0, t1, c0, c1 fp
+	t0.mul(x[0], y[0])
+	t1.mul(x[1], y[1])
+	c0.sub(t0, t1)
+	t0.mul(x[0], y[1])
+	t1.mul(x[1], y[0])
+	c1.add(t0, t1)
+	*z = fp2{c0, c1}
+	return z
+}
+
+// inverse sets z = 1 / x and returns z, x must not be zero
+func (z *fp2) inverse(x fp2) *fp2 {
+	// 1 / (a0 + a1 u) = (a0 - a1 u) / (a0^2 + a1^2)
+	var t, s fp
+	t.mul(x[0], x[0])
+	s.mul(x[1], x[1])
+	t.add(t, s)
+	t.inverse(t)
+	var c1 fp
+	c1.sub(c1, x[1])
+	z[0].mul(x[0], t)
+	z[1].mul(c1, t)
+	return z
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+/*
+	Scalar field arithmetic. An element x of Fr, the integers modulo the group order q, is stored in
+	Montgomery form x * 2^256 mod q as four 64 bit limbs, least significant first. Unlike math/big this
+	needs no allocations and no division, so all internal scalar arithmetic uses fr and big.Int is only
+	used where scalars enter or leave the package or are handed to go-ethereum.
+*/
+
+// fr is an element of the scalar field in Montgomery form
+type fr [4]uint64
+
+// the group order q
+var frQ = fr{0xffffffff00000001, 0x53bda402fffe5bfe, 0x3339d80809a1d805, 0x73eda753299d7d48}
+
+const (
+	// -q^{-1} mod 2^64
+	frQInv = 0xfffffffeffffffff
+)
+
+var (
+	frModulus = new(big.Int).SetBytes([]byte{
+		0x73, 0xed, 0xa7, 0x53, 0x29, 0x9d, 0x7d, 0x48, 0x33, 0x39, 0xd8, 0x08, 0x09, 0xa1, 0xd8, 0x05,
+		0x53, 0xbd, 0xa4, 0x02, 0xff, 0xfe, 0x5b, 0xfe, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x01,
+	})
+	// 2^512 mod q, multiplying by it converts into Montgomery form
+	frR2 = frLimbs(new(big.Int).Mod(new(big.Int).Lsh(big.NewInt(1), 512), frModulus))
+	// one in Montgomery form, 2^256 mod q
+	frOne = frLimbs(new(big.Int).Mod(new(big.Int).Lsh(big.NewInt(1), 256), frModulus))
+)
+
+// frLimbs returns the limbs of 0 <= x < 2^256 without any conversion
+func frLimbs(x *big.Int) fr {
+	var buf [32]byte
+	x.FillBytes(buf[:])
+	var z fr
+	for i := 0; i < 4; i++ {
+		for _, b := range buf[32-8*(i+1) : 32-8*i] {
+			z[i] = z[i]<<8 | uint64(b)
+		}
+	}
+	return z
+}
+
+// frFromBig returns x mod q, x may be negative or larger than q
+func frFromBig(x *big.Int) fr {
+	if x.Sign() < 0 || x.Cmp(frModulus) >= 0 {
+		x = new(big.Int).Mod(x, frModulus)
+	}
+	var z fr
+	z.mul(frLimbs(x), frR2)
+	return z
+}
+
+// frFromUint64 returns x as a field element
+func frFromUint64(x uint64) fr {
+	var z fr
+	z.mul(fr{x}, frR2)
+	return z
+}
+
+// big returns the value of x in [0, q)
+func (x fr) big() *big.Int {
+	y := x.plain()
+	var buf [32]byte
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			buf[31-8*i-j] = byte(y[i] >> (8 * j))
+		}
+	}
+	return new(big.Int).SetBytes(buf[:])
+}
+
+// plain returns the limbs of the value of x in [0, q), least significant first
+func (x fr) plain() [4]uint64 {
+	// a multiplication by 1 leaves Montgomery form
+	var y fr
+	y.mul(x, fr{1})
+	return y
+}
+
+func (x fr) isZero() bool {
+	return x[0]|x[1]|x[2]|x[3] == 0
+}
+
+// reduce subtracts q once if z >= q
+func (z *fr) reduce() {
+	var t fr
+	var b uint64
+	t[0], b = bits.Sub64(z[0], frQ[0], 0)
+	t[1], b = bits.Sub64(z[1], frQ[1], b)
+	t[2], b = bits.Sub64(z[2], frQ[2], b)
+	t[3], b = bits.Sub64(z[3], frQ[3], b)
+	if b == 0 {
+		*z = t
+	}
+}
+
+// add sets z = x + y and returns z
+func (z *fr) add(x, y fr) *fr {
+	// q < 2^255, so the sum does not overflow four limbs
+	var c uint64
+	z[0], c = bits.Add64(x[0], y[0], 0)
+	z[1], c = bits.Add64(x[1], y[1], c)
+	z[2], c = bits.Add64(x[2], y[2], c)
+	z[3], _ = bits.Add64(x[3], y[3], c)
+	z.reduce()
+	return z
+}
+
+// sub sets z = x - y and returns z
+func (z *fr) sub(x, y fr) *fr {
+	var b uint64
+	z[0], b = bits.Sub64(x[0], y[0], 0)
+	z[1], b = bits.Sub64(x[1], y[1], b)
+	z[2], b = bits.Sub64(x[2], y[2], b)
+	z[3], b = bits.Sub64(x[3], y[3], b)
+	if b != 0 {
+		var c uint64
+		z[0], c = bits.Add64(z[0], frQ[0], 0)
+		z[1], c = bits.Add64(z[1], frQ[1], c)
+		z[2], c = bits.Add64(z[2], frQ[2], c)
+		z[3], _ = bits.Add64(z[3], frQ[3], c)
+	}
+	return z
+}
+
+// mul sets z = x * y and returns z, using the CIOS Montgomery multiplication
+func (z *fr) mul(x, y fr) *fr {
+	var t [6]uint64
+	var c, hi, lo, carry uint64
+	for i := 0; i < 4; i++ {
+		// t += x * y[i]
+		c = 0
+		for j := 0; j < 4; j++ {
+			hi, lo = bits.Mul64(x[j], y[i])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j], c = lo, hi
+		}
+		t[4], carry = bits.Add64(t[4], c, 0)
+		t[5] = carry
+		// t = (t + m * q) / 2^64 with m chosen to clear the lowest limb
+		m := t[0] * frQInv
+		hi, lo = bits.Mul64(m, frQ[0])
+		_, carry = bits.Add64(lo, t[0], 0)
+		c = hi + carry
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, frQ[j])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j-1], c = lo, hi
+		}
+		t[3], carry = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + carry
+	}
+	*z = fr{t[0], t[1], t[2], t[3]}
+	// the result is below 2q
+	if t[4] != 0 {
+		var b uint64
+		z[0], b = bits.Sub64(z[0], frQ[0], 0)
+		z[1], b = bits.Sub64(z[1], frQ[1], b)
+		z[2], b = bits.Sub64(z[2], frQ[2], b)
+		z[3], _ = bits.Sub64(z[3], frQ[3], b)
+	} else {
+		z.reduce()
+	}
+	return z
+}
+
+// exp sets z = x^e for a non-negative e and returns z
+func (z *fr) exp(x fr, e *big.Int) *fr {
+	res := frOne
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		res.mul(res, res)
+		if e.Bit(i) == 1 {
+			res.mul(res, x)
+		}
+	}
+	*z = res
+	return z
+}
+
+// inverse sets z = 1 / x and returns z, x must not be zero
+func (z *fr) inverse(x fr) *fr {
+	if x.isZero() {
+		panic("inverse of zero")
+	}
+	// x^{q - 2} by Fermat's little theorem
+	return z.exp(x, new(big.Int).Sub(frModulus, big.NewInt(2)))
+}
+
+// frVector converts a vector at the API boundary, reducing every entry modulo q
+func frVector(v []*big.Int) []fr {
+	res := make([]fr, len(v))
+	for i, x := range v {
+		res[i] = frFromBig(x)
+	}
+	return res
+}
+
+// bigVector is the inverse of frVector
+func bigVector(v []fr) []*big.Int {
+	res := make([]*big.Int, len(v))
+	for i, x := range v {
+		res[i] = x.big()
+	}
+	return res
+}
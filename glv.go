@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	GLV scalar multiplication in G1. The map phi(x, y) = (beta * x, y), with beta a cube root of unity
+	in Fp, is an endomorphism of G1 and acts as the multiplication by lambda = z^2 - 1, where z is the
+	BLS parameter. As q = lambda^2 + lambda + 1, any scalar 0 <= k < q splits into k = k1 + k2 * lambda
+	with k1 = k mod lambda and k2 = k div lambda both below 2^128, and
+		k * P = k1 * P + k2 * phi(P)
+	is evaluated with interleaved wNAF over half as many doublings as mulG1 would need otherwise.
+*/
+
+var (
+	// the cube root of unity for which phi(P) = lambda * P
+	glvBeta, _ = new(big.Int).SetString("1a0111ea397fe699ec02408663d4de85aa0d857d89759ad4897d29650fb85f9b409427eb4f49fffd8bfd00000000aaac", 16)
+	// lambda = z^2 - 1
+	glvLambda, _ = new(big.Int).SetString("ac45a4010001a40200000000ffffffff", 16)
+)
+
+// endomorphismG1 sets r = phi(p) = lambda * p and returns r. In Jacobian coordinates phi multiplies X by
+// beta, and multiplying the Montgomery form limbs of X (see affine.go) by beta keeps them in Montgomery form
+func endomorphismG1(r, p *bls.PointG1) *bls.PointG1 {
+	r.Set(p)
+	limbs := (*[6]uint64)(&r[0])
+	words := make([]big.Word, 6)
+	for i := range limbs {
+		words[i] = big.Word(limbs[i])
+	}
+	x := new(big.Int).SetBits(words)
+	x.Mul(x, glvBeta).Mod(x, fpModulus)
+	var buf [48]byte
+	x.FillBytes(buf[:])
+	for i := 0; i < 6; i++ {
+		var w uint64
+		for _, b := range buf[48-8*(i+1) : 48-8*i] {
+			w = w<<8 | uint64(b)
+		}
+		limbs[i] = w
+	}
+	return r
+}
+
+// glvSplit returns the limbs of k1 and k2 with s = k1 + k2 * lambda
+func glvSplit(s fr) ([4]uint64, [4]uint64) {
+	k2, k1 := new(big.Int).QuoRem(s.big(), glvLambda, new(big.Int))
+	return frLimbs(k1), frLimbs(k2)
+}
+
+// mulG1 sets r = s * p and returns r, r may alias p
+func mulG1(g *bls.G1, r, p *bls.PointG1, s fr) *bls.PointG1 {
+	k1, k2 := glvSplit(s)
+	digits1, size := wnafDigits(k1, wnafWidth)
+	digits2, size2 := wnafDigits(k2, wnafWidth)
+	if size2 > size {
+		size = size2
+	}
+	var odd1, neg1, odd2, neg2 [1 << (wnafWidth - 2)]bls.PointG1
+	oddMultiplesG1(g, &odd1, &neg1, p)
+	oddMultiplesG1(g, &odd2, &neg2, endomorphismG1(g.New(), p))
+	r.Zero()
+	for i := size - 1; i >= 0; i-- {
+		g.Double(r, r)
+		addDigitG1(g, r, &odd1, &neg1, digits1[i])
+		addDigitG1(g, r, &odd2, &neg2, digits2[i])
+	}
+	return r
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+
+	pb "PointProofs/proto"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative proto/pointproofs.proto
+
+/*
+	gRPC service of proto/pointproofs.proto, started by "PointProofs grpc", so services written in other
+	languages can use a central prover holding the parameters. It offers the operations of the HTTP API
+	of server.go with entries as 32 byte big endian integers and commitments and proofs as their
+	artifacts. OpenBatch sends the proofs of a batch in groups of one per CPU as they are computed, and
+	VerifyBatch takes the openings of one commitment as a stream and checks them at once with the batch
+	verification of batch.go. Malformed requests fail with codes.InvalidArgument. The generated code in
+	proto/ is checked in, go generate rebuilds it with protoc, protoc-gen-go and protoc-gen-go-grpc.
+*/
+
+// grpcServer implements the service on the installed parameters
+type grpcServer struct {
+	pb.UnimplementedPointProofsServer
+}
+
+// newGRPCServer returns a gRPC server offering the service
+func newGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	pb.RegisterPointProofsServer(s, grpcServer{})
+	return s
+}
+
+// grpcRecover turns a panic of the scheme or the parsers below, which means the request is malformed,
+// into the error of an RPC returning *err
+func grpcRecover(err *error) {
+	if r := recover(); r != nil {
+		*err = status.Error(codes.InvalidArgument, fmt.Sprint(r))
+	}
+}
+
+// grpcEntry parses an entry of 32 bytes
+func grpcEntry(b []byte) *big.Int {
+	if len(b) != scalarSize {
+		panic(fmt.Sprintf("entries have %d bytes, got %d", scalarSize, len(b)))
+	}
+	v := new(big.Int).SetBytes(b)
+	if v.Cmp(frModulus) >= 0 {
+		panic("entry does not lie in the field")
+	}
+	return v
+}
+
+// grpcVector parses a vector of n entries
+func grpcVector(entries [][]byte) []*big.Int {
+	if len(entries) != n {
+		panic(fmt.Sprintf("expected %d entries, got %d", n, len(entries)))
+	}
+	vector := make([]*big.Int, n)
+	for i, e := range entries {
+		vector[i] = grpcEntry(e)
+	}
+	return vector
+}
+
+// grpcValue encodes an entry
+func grpcValue(v *big.Int) []byte {
+	return v.FillBytes(make([]byte, scalarSize))
+}
+
+// grpcArtifact parses an artifact with decode
+func grpcArtifact(b []byte, decode func([]byte) (*bls.PointG1, error)) *bls.PointG1 {
+	p, err := decode(b)
+	if err != nil {
+		panic(err.Error())
+	}
+	return p
+}
+
+// grpcIndices checks the indices and converts them
+func grpcIndices(raw []uint32) []int {
+	indices := make([]int, len(raw))
+	for k, i := range raw {
+		// indices beyond the range of int are out of range as well
+		if i >= n {
+			panic(fmt.Sprintf("index %d out of range", i))
+		}
+		indices[k] = int(i)
+	}
+	return indices
+}
+
+// grpcOpenings parses openings of distinct indices
+func grpcOpenings(raw []*pb.Opening) []Opening {
+	openings := make([]Opening, len(raw))
+	indices := make([]uint32, len(raw))
+	for k, o := range raw {
+		indices[k] = o.GetIndex()
+	}
+	checked := grpcIndices(indices)
+	apiDistinct(checked)
+	for k, i := range checked {
+		openings[k] = Opening{Index: i, Value: grpcEntry(raw[k].GetValue()), Proof: grpcArtifact(raw[k].GetProof(), decodeProof)}
+	}
+	return openings
+}
+
+func (grpcServer) Commit(_ context.Context, req *pb.CommitRequest) (res *pb.CommitResponse, err error) {
+	defer grpcRecover(&err)
+	return &pb.CommitResponse{Commitment: encodeCommitment(commit(grpcVector(req.GetVector())))}, nil
+}
+
+func (grpcServer) Open(_ context.Context, req *pb.OpenRequest) (res *pb.OpenResponse, err error) {
+	defer grpcRecover(&err)
+	vector := grpcVector(req.GetVector())
+	i := grpcIndices([]uint32{req.GetIndex()})[0]
+	proof := ProveSet(vector, []int{i})[0]
+	return &pb.OpenResponse{Index: uint32(i), Value: grpcValue(vector[i]), Proof: encodeProof(proof)}, nil
+}
+
+func (grpcServer) OpenBatch(req *pb.OpenBatchRequest, stream pb.PointProofs_OpenBatchServer) (err error) {
+	defer grpcRecover(&err)
+	vector := grpcVector(req.GetVector())
+	indices := grpcIndices(req.GetIndices())
+	// ProveSet spreads the indices over the CPUs, groups of one per CPU keep them all busy
+	group := runtime.NumCPU()
+	for start := 0; start < len(indices); start += group {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		end := start + group
+		if end > len(indices) {
+			end = len(indices)
+		}
+		proofs := ProveSet(vector, indices[start:end])
+		for k, i := range indices[start:end] {
+			if err := stream.Send(&pb.OpenResponse{Index: uint32(i), Value: grpcValue(vector[i]), Proof: encodeProof(proofs[k])}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (grpcServer) Aggregate(_ context.Context, req *pb.AggregateRequest) (res *pb.AggregateResponse, err error) {
+	defer grpcRecover(&err)
+	com := grpcArtifact(req.GetCommitment(), decodeCommitment)
+	openings := grpcOpenings(req.GetOpenings())
+	return &pb.AggregateResponse{AggregatedProof: encodeAggregatedProof(AggregateSameCommitment(com, openings))}, nil
+}
+
+func (grpcServer) Verify(_ context.Context, req *pb.VerifyRequest) (res *pb.VerifyResponse, err error) {
+	defer grpcRecover(&err)
+	com := grpcArtifact(req.GetCommitment(), decodeCommitment)
+	if len(req.GetValues()) != len(req.GetIndices()) {
+		panic("indices and values differ in length")
+	}
+	indices := grpcIndices(req.GetIndices())
+	apiDistinct(indices)
+	values := make([]*big.Int, len(indices))
+	for k, v := range req.GetValues() {
+		values[k] = grpcEntry(v)
+	}
+	var valid bool
+	if proof := req.GetProof(); len(proof) > 0 && proof[0] == artifactProof {
+		if len(values) != 1 {
+			panic("a proof of a single entry needs exactly one index")
+		}
+		valid = verifySingleProof(com, values[0], grpcArtifact(proof, decodeProof), indices[0])
+	} else {
+		valid = VerifySameCommitment(com, indices, values, grpcArtifact(proof, decodeAggregatedProof))
+	}
+	return &pb.VerifyResponse{Valid: valid}, nil
+}
+
+func (grpcServer) VerifyBatch(stream pb.PointProofs_VerifyBatchServer) (err error) {
+	defer grpcRecover(&err)
+	var com *bls.PointG1
+	var raw []*pb.Opening
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if com == nil {
+			com = grpcArtifact(req.GetCommitment(), decodeCommitment)
+		} else if len(req.GetCommitment()) > 0 {
+			panic("the commitment is set in the first message only")
+		}
+		if req.GetOpening() == nil {
+			panic("message without an opening")
+		}
+		// the indices are distinct, a stream of more than n openings is malformed
+		if len(raw) == n {
+			panic(fmt.Sprintf("more than %d openings", n))
+		}
+		raw = append(raw, req.GetOpening())
+	}
+	if com == nil {
+		panic("empty stream")
+	}
+	return stream.SendAndClose(&pb.VerifyResponse{Valid: VerifyBatch(grpcOpenings(raw), com)})
+}
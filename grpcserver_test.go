@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+
+	pb "PointProofs/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// grpcClient serves the service in memory and returns a client of it
+func grpcClient(t *testing.T) pb.PointProofsClient {
+	lis := bufconn.Listen(1 << 20)
+	srv := newGRPCServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewPointProofsClient(conn)
+}
+
+func TestGRPC(t *testing.T) {
+	f := benchSetup(t)
+	client := grpcClient(t)
+	ctx := context.Background()
+	vector := make([][]byte, n)
+	for i, v := range f.message {
+		vector[i] = grpcValue(v)
+	}
+	committed, err := client.Commit(ctx, &pb.CommitRequest{Vector: vector})
+	if err != nil {
+		t.Fatal(err)
+	}
+	com := committed.GetCommitment()
+
+	indices := make([]uint32, len(f.indices))
+	for k, i := range f.indices {
+		indices[k] = uint32(i)
+	}
+	stream, err := client.OpenBatch(ctx, &pb.OpenBatchRequest{Vector: vector, Indices: indices})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var openings []*pb.Opening
+	for {
+		o, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		openings = append(openings, &pb.Opening{Index: o.GetIndex(), Value: o.GetValue(), Proof: o.GetProof()})
+	}
+	if len(openings) != len(indices) {
+		t.Fatalf("OpenBatch sent %d proofs for %d indices", len(openings), len(indices))
+	}
+	opened, err := client.Open(ctx, &pb.OpenRequest{Vector: vector, Index: indices[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(opened.GetProof()) != string(openings[2].GetProof()) {
+		t.Fatal("Open and OpenBatch disagree")
+	}
+
+	values := make([][]byte, len(openings))
+	for k, o := range openings {
+		values[k] = o.GetValue()
+		res, err := client.Verify(ctx, &pb.VerifyRequest{Commitment: com, Indices: indices[k : k+1], Values: values[k : k+1], Proof: o.GetProof()})
+		if err != nil || !res.GetValid() {
+			t.Fatalf("proof of index %d: %v, %v", indices[k], res, err)
+		}
+	}
+	aggregated, err := client.Aggregate(ctx, &pb.AggregateRequest{Commitment: com, Openings: openings})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := client.Verify(ctx, &pb.VerifyRequest{Commitment: com, Indices: indices, Values: values, Proof: aggregated.GetAggregatedProof()})
+	if err != nil || !res.GetValid() {
+		t.Fatalf("aggregated proof: %v, %v", res, err)
+	}
+	wrong := append([][]byte(nil), values...)
+	wrong[1] = grpcValue(big.NewInt(7))
+	res, err = client.Verify(ctx, &pb.VerifyRequest{Commitment: com, Indices: indices, Values: wrong, Proof: aggregated.GetAggregatedProof()})
+	if err != nil || res.GetValid() {
+		t.Fatalf("aggregated proof of a wrong value: %v, %v", res, err)
+	}
+
+	// VerifyBatch of all openings, then with one wrong value
+	for _, tamper := range []bool{false, true} {
+		batch, err := client.VerifyBatch(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, o := range openings {
+			req := &pb.VerifyBatchRequest{Opening: o}
+			if k == 0 {
+				req.Commitment = com
+			}
+			if tamper && k == 3 {
+				req.Opening = &pb.Opening{Index: o.GetIndex(), Value: grpcValue(big.NewInt(7)), Proof: o.GetProof()}
+			}
+			if err := batch.Send(req); err != nil {
+				t.Fatal(err)
+			}
+		}
+		res, err := batch.CloseAndRecv()
+		if err != nil || res.GetValid() == tamper {
+			t.Fatalf("VerifyBatch, wrong value %v: %v, %v", tamper, res, err)
+		}
+	}
+}
+
+func TestGRPCMalformed(t *testing.T) {
+	benchSetup(t)
+	client := grpcClient(t)
+	ctx := context.Background()
+	if _, err := client.Commit(ctx, &pb.CommitRequest{Vector: [][]byte{{1}}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("short vector: %v", err)
+	}
+	vector := make([][]byte, n)
+	for i := range vector {
+		vector[i] = make([]byte, scalarSize)
+	}
+	if _, err := client.Open(ctx, &pb.OpenRequest{Vector: vector, Index: n}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("out of range index: %v", err)
+	}
+	if _, err := client.Verify(ctx, &pb.VerifyRequest{Commitment: []byte{1, 2, 3}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("malformed commitment: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+)
+
+/*
+	Byte string entries. Messages are vectors of field elements, data of any other kind is mapped to them
+	by hash_to_field of RFC 9380 (section 5) with expand_message_xmd over SHA-256 and L = 48 bytes per
+	element, so every implementation of the RFC maps the same bytes to the same scalars. An EntryEncoder
+	fixes the domain separation tag of one application, and the position of an entry is hashed along with
+	it, so equal bytes at different indices, or in different applications, give unrelated scalars.
+*/
+
+const (
+	// hashToFieldL is L = ceil((ceil(log2(q)) + k) / 8) for the 255 bit q and k = 128
+	hashToFieldL = 48
+	// entryDSTPrefix starts the tag of every EntryEncoder, the application name follows it
+	entryDSTPrefix = "POINTPROOFS-V01-FR_XMD:SHA-256_"
+)
+
+// expandMessageXMD is expand_message_xmd of RFC 9380 section 5.3.1 with SHA-256
+func expandMessageXMD(msg, dst []byte, length int) []byte {
+	if len(dst) > 255 {
+		h := sha256.Sum256(append([]byte("H2C-OVERSIZE-DST-"), dst...))
+		dst = h[:]
+	}
+	ell := (length + sha256.Size - 1) / sha256.Size
+	if ell > 255 || length > 65535 {
+		panic("requested too many bytes")
+	}
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	h := sha256.New()
+	// b_0 = H(Z_pad || msg || l_i_b_str || I2OSP(0, 1) || DST_prime)
+	h.Write(make([]byte, sha256.BlockSize))
+	h.Write(msg)
+	h.Write([]byte{byte(length >> 8), byte(length), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+	// b_1 = H(b_0 || I2OSP(1, 1) || DST_prime), b_i = H(strxor(b_0, b_{i-1}) || I2OSP(i, 1) || DST_prime)
+	res := make([]byte, 0, ell*sha256.Size)
+	prev := make([]byte, sha256.Size)
+	for i := 1; i <= ell; i++ {
+		h.Reset()
+		for j := range prev {
+			prev[j] ^= b0[j]
+		}
+		h.Write(prev)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		prev = h.Sum(nil)
+		res = append(res, prev...)
+	}
+	return res[:length]
+}
+
+// hashToField is hash_to_field of RFC 9380 section 5.2 for the scalar field, m = 1
+func hashToField(msg, dst []byte, count int) []*big.Int {
+	uniform := expandMessageXMD(msg, dst, count*hashToFieldL)
+	res := make([]*big.Int, count)
+	for i := range res {
+		res[i] = new(big.Int).SetBytes(uniform[i*hashToFieldL : (i+1)*hashToFieldL])
+		res[i].Mod(res[i], frModulus)
+	}
+	return res
+}
+
+// EntryEncoder maps byte strings to message entries for one application
+type EntryEncoder struct {
+	dst []byte
+}
+
+// NewEntryEncoder returns the encoder of the application with the given name, which should be unique
+// to it and include a version, e.g. "example.org/accounts-v1"
+func NewEntryEncoder(application string) *EntryEncoder {
+	return &EntryEncoder{dst: []byte(entryDSTPrefix + application)}
+}
+
+// Encode returns the entry for data at the index, hash_to_field of I2OSP(index, 4) || data
+func (e *EntryEncoder) Encode(index int, data []byte) *big.Int {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	msg := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint32(msg, uint32(index))
+	return hashToField(append(msg, data...), e.dst, 1)[0]
+}
+
+// EncodeVector returns the message of n byte string entries, ready for commit
+func (e *EntryEncoder) EncodeVector(entries [][]byte) []*big.Int {
+	if len(entries) != n {
+		panic("wrong array size")
+	}
+	res := make([]*big.Int, n)
+	for i, data := range entries {
+		res[i] = e.Encode(i, data)
+	}
+	return res
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Hiding commitments. commit is deterministic, so anyone can test a guess of the whole message
+	against it. The hiding variant adds a random multiple of a second base h,
+		C = \sum m_i * pp1[i] + r * h
+	where h is hashed to the curve, so nobody knows its discrete logarithm and C reveals nothing about
+	the message. The proof of m_i has to account for r without revealing anything about the other
+	entries, so it is a pair (pi', W) with a fresh random s,
+		pi' = pi_i - s * h,		W = r * g_2^{alpha^{n+1-i}} + s * g_2
+	for the proof pi_i of the message itself, and the verifier checks
+		e(C, g_2^{alpha^{n+1-i}}) = e(pi', g_2) * e(h, W) * g_T^{alpha^{n+1} * m_i}
+	Without s the pair would let anyone test guesses of the other entries, with it (pi', W) is uniform
+	among the pairs satisfying the equation. Hiding proofs do not aggregate with the plain ones.
+*/
+
+const hidingBaseDomain = "PointProofs-hiding-base-v1"
+
+var (
+	hidingBaseOnce  sync.Once
+	hidingBasePoint *bls.PointG1
+)
+
+// hidingBase returns h = map(u_0) + map(u_1) with u_k hashed from hidingBaseDomain to the base field
+// and map the SWU map of go-ethereum, as in the encoding of hash-to-curve
+func hidingBase() *bls.PointG1 {
+	hidingBaseOnce.Do(func() {
+		g := bls.NewG1()
+		h := g.Zero()
+		for k := byte(0); k < 2; k++ {
+			// 512 bits reduced modulo p make u statistically uniform
+			wide := make([]byte, 0, 2*sha256.Size)
+			for j := byte(0); j < 2; j++ {
+				d := sha256.Sum256(append([]byte(hidingBaseDomain), k, j))
+				wide = append(wide, d[:]...)
+			}
+			u := new(big.Int).Mod(new(big.Int).SetBytes(wide), fpModulus)
+			p, err := g.MapToCurve(u.FillBytes(make([]byte, 48)))
+			if err != nil {
+				panic(err)
+			}
+			g.Add(h, h, p)
+		}
+		hidingBasePoint = g.Affine(h)
+	})
+	return hidingBasePoint
+}
+
+// HidingProof proves an entry of a hiding commitment
+type HidingProof struct {
+	Pi *bls.PointG1
+	W  *bls.PointG2
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the message vector
+	It returns the hiding commitment to the message and the randomness r, which the prover needs for
+	every proof and has to keep secret.
+*/
+func CommitHiding(message []*big.Int) (*bls.PointG1, *big.Int) {
+	r := generateBigIntegerArray(1, frModulus)[0]
+	com := commit(message)
+	g := getG1()
+	defer putG1(g)
+	return g.Add(com, com, mulG1(g, g.New(), hidingBase(), frFromBig(r))), r
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1, pp2 (implicitly)
+		3. the message vector
+		4. the randomness of its hiding commitment
+		5. the index to prove
+	It returns a proof of m_index for the hiding commitment, a new one at every call.
+*/
+func ProveHiding(message []*big.Int, r *big.Int, index int) *HidingProof {
+	if r.Sign() < 0 || r.Cmp(frModulus) != -1 {
+		panic("randomness does not lie in the field")
+	}
+	pi := ProveSet(message, []int{index})[0]
+	s := frFromBig(generateBigIntegerArray(1, frModulus)[0])
+	g1, g2 := getG1(), getG2()
+	defer putG1(g1)
+	defer putG2(g2)
+	g1.Sub(pi, pi, mulG1(g1, g1.New(), hidingBase(), s))
+	w := mulG2(g2, g2.New(), pp2[n-index-1], frFromBig(r))
+	g2.Add(w, w, mulG2(g2, g2.New(), g2Generator, s))
+	return &HidingProof{Pi: pi, W: w}
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the hiding commitment
+		4. entry m_i
+		5. the proof of ProveHiding
+		6. index
+	It reports whether the proof opens the commitment to m_i at the index.
+*/
+func VerifyHiding(com *bls.PointG1, entry *big.Int, proof *HidingProof, index int) bool {
+	return profiled("verify_hiding", func() bool {
+		if !(0 <= index && index < n) {
+			panic("out of range index")
+		}
+		// e(C, g_2^{alpha^{n+1-i}}) * e(pi', g_2)^{-1} * e(h, W)^{-1} = g_T^{alpha^{n+1} * m_i}
+		g1s := []*bls.PointG1{com, negG1(proof.Pi), negG1(hidingBase())}
+		g2s := []*bls.PointG2{pp2[n-index-1], g2Generator, new(bls.PointG2).Set(proof.W)}
+		return verifyPairings(g1s, g2s, frFromBig(entry))
+	})
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestHiding(t *testing.T) {
+	f := benchSetup(t)
+	g := getG1()
+	defer putG1(g)
+	com, r := CommitHiding(f.message)
+	again, _ := CommitHiding(f.message)
+	if g.Equal(com, again) || g.Equal(com, f.com) {
+		t.Fatal("hiding commitments to the same message coincide")
+	}
+	for _, i := range []int{0, 500, n - 1} {
+		proof := ProveHiding(f.message, r, i)
+		if !VerifyHiding(com, f.message[i], proof, i) {
+			t.Fatalf("proof of index %d rejected", i)
+		}
+		if VerifyHiding(com, new(big.Int).Add(f.message[i], big.NewInt(1)), proof, i) {
+			t.Fatalf("proof of index %d accepted for a wrong value", i)
+		}
+		if VerifyHiding(again, f.message[i], proof, i) {
+			t.Fatalf("proof of index %d accepted for a commitment with other randomness", i)
+		}
+		if g.Equal(proof.Pi, ProveHiding(f.message, r, i).Pi) {
+			t.Fatalf("proofs of index %d are not randomized", i)
+		}
+	}
+	plain := &HidingProof{Pi: f.proofs[3], W: ProveHiding(f.message, r, f.indices[3]).W}
+	if VerifyHiding(com, f.values[3], plain, f.indices[3]) {
+		t.Fatal("plain proof accepted for a hiding commitment")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Homomorphic arithmetic. Commitments and proofs are linear in the message: with C(m) = \sum m_j pp1[j]
+	and pi_i(m) = \sum_{j != i} m_j pp1[n - i + j],
+		C(a) + C(b) = C(a + b),		s * C(a) = C(s * a)
+	and likewise for the proofs of every index, all vectors taken entrywise modulo the group order. So a
+	commitment to a sum or a difference of committed vectors, e.g. the balances after a block of
+	transfers, follows from the commitments alone, and the proofs of the result from the proofs of the
+	terms at the same index. The methods follow math/big: the receiver is set to the result and returned,
+	and it may be one of the operands.
+*/
+
+// Add sets c to a + b, the commitment to the sum of the vectors of a and b, and returns c
+func (c *Commitment) Add(a, b *Commitment) *Commitment {
+	g := getG1()
+	defer putG1(g)
+	g.Add(c.Point(), a.Point(), b.Point())
+	return c
+}
+
+// Sub sets c to a - b, the commitment to the difference of the vectors of a and b, and returns c
+func (c *Commitment) Sub(a, b *Commitment) *Commitment {
+	g := getG1()
+	defer putG1(g)
+	g.Sub(c.Point(), a.Point(), b.Point())
+	return c
+}
+
+// MulScalar sets c to s * a, the commitment to the vector of a scaled by s, and returns c. s may be
+// negative or exceed the group order, it is reduced
+func (c *Commitment) MulScalar(a *Commitment, s *big.Int) *Commitment {
+	g := getG1()
+	defer putG1(g)
+	mulG1(g, c.Point(), a.Point(), frFromBig(s))
+	return c
+}
+
+// Add sets p to a + b and returns p. For proofs of the same index against commitments C_a and C_b, the
+// result proves the sum of the entries against C_a + C_b
+func (p *Proof) Add(a, b *Proof) *Proof {
+	g := getG1()
+	defer putG1(g)
+	g.Add(p.Point(), a.Point(), b.Point())
+	return p
+}
+
+// Sub sets p to a - b and returns p, the proof of the difference of the entries against C_a - C_b
+func (p *Proof) Sub(a, b *Proof) *Proof {
+	g := getG1()
+	defer putG1(g)
+	g.Sub(p.Point(), a.Point(), b.Point())
+	return p
+}
+
+// MulScalar sets p to s * a and returns p, the proof of s times the entry against s * C_a
+func (p *Proof) MulScalar(a *Proof, s *big.Int) *Proof {
+	g := getG1()
+	defer putG1(g)
+	mulG1(g, p.Point(), a.Point(), frFromBig(s))
+	return p
+}
+
+// LinearCombination returns \sum scalars[k] * coms[k], the commitment to \sum scalars[k] * v_k for the
+// vectors v_k of coms, in a single multi-scalar multiplication
+func LinearCombination(coms []*Commitment, scalars []*big.Int) *Commitment {
+	if len(coms) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	points := make([]*bls.PointG1, len(coms))
+	for k, c := range coms {
+		points[k] = c.Point()
+	}
+	if len(points) == 0 {
+		return (*Commitment)(bls.NewG1().Zero())
+	}
+	return (*Commitment)(backend.MultiExpG1(points, frVector(scalars)))
+}
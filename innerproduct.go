@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Inner product openings. For a public query q the combination pi = \sum_i q_i pi_i of the proofs of all
+	entries proves <m, q> = \sum_i q_i m_i, with the verifier checking
+		e(C, \sum_i q_i g_2^{alpha^{n+1-i}}) = e(pi, g_2) * g_T^{alpha^{n+1} * <m, q>}
+	Unlike AggregateSameCommitment no random scalars are needed: the claim is the single value <m, q>,
+	and a wrong one would need a G1 element with g_1^{alpha^{n+1}} in it, which the parameters lack.
+	As in OpenSubvector the prover folds the query into one multi-scalar multiplication over pp1,
+		pi = \sum_k pp1[k] * \sum_i q_i m_{k - n + i}
+	so a query of weights 1 on a subset proves, say, the total stake of the subset with 48 bytes.
+*/
+
+// Query is a public vector q given by its nonzero entries q[Indices[k]] = Weights[k]
+type Query struct {
+	Indices []int
+	Weights []*big.Int
+}
+
+// DenseQuery returns the Query of the vector q of length n
+func DenseQuery(q []*big.Int) Query {
+	if len(q) != n {
+		panic("wrong array size")
+	}
+	var res Query
+	for i, w := range q {
+		if w.Sign() != 0 {
+			res.Indices = append(res.Indices, i)
+			res.Weights = append(res.Weights, w)
+		}
+	}
+	return res
+}
+
+// check panics unless the query is well formed, the weights may be any integers and are reduced
+func (q Query) check() {
+	if len(q.Indices) != len(q.Weights) {
+		panic("arrays with incorrect length")
+	}
+	for _, i := range q.Indices {
+		if !(0 <= i && i < n) {
+			panic("out of range index")
+		}
+	}
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the message vector
+		4. the query q
+	It returns the inner product <m, q> modulo the group order and the proof of it.
+*/
+func ProveInnerProduct(message []*big.Int, query Query) (*big.Int, *bls.PointG1) {
+	checkVector(message)
+	query.check()
+	m := frVector(message)
+	// scalars[k] = \sum_i q_i m_{k - n + i}
+	scalars := make([]fr, 2*n)
+	var value, product fr
+	for s, i := range query.Indices {
+		w := frFromBig(query.Weights[s])
+		product.mul(m[i], w)
+		value.add(value, product)
+		for j := 0; j < n; j++ {
+			product.mul(m[j], w)
+			scalars[n-i+j].add(scalars[n-i+j], product)
+		}
+	}
+	// the terms j = i fell on pp1[n], the point at infinity
+	return value.big(), currentMSM().MultiExpG1(pp1Range(0, 2*n), scalars)
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the commitment
+		4. the query q
+		5. the claimed inner product v
+		6. the proof of ProveInnerProduct
+	It reports whether the proof shows <m, q> = v for the committed message m.
+*/
+func VerifyInnerProduct(com *bls.PointG1, query Query, value *big.Int, proof *bls.PointG1) bool {
+	return profiled("verify_inner_product", func() bool {
+		query.check()
+		if len(query.Indices) == 0 {
+			// <m, 0> = 0 and its proof is the point at infinity
+			v := frFromBig(value)
+			return v.isZero() && bls.NewG1().IsZero(proof)
+		}
+		bases := make([]*bls.PointG2, len(query.Indices))
+		for k, i := range query.Indices {
+			bases[k] = pp2[n-i-1]
+		}
+		// e(C, \sum_i q_i g_2^{alpha^{n+1-i}}) * e(pi, g_2)^{-1} = g_T^{alpha^{n+1} * v}
+		g1s := []*bls.PointG1{com, negG1(proof)}
+		g2s := []*bls.PointG2{backend.MultiExpG2(bases, frVector(query.Weights)), g2Generator}
+		return verifyPairings(g1s, g2s, frFromBig(value))
+	})
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+/*
+	Asynchronous proving. A Worker takes jobs from a JobQueue, runs up to Concurrency of them at once and
+	publishes a result for each, so a deployment scales by adding workers behind a queue instead of
+	serving every proof within an HTTP request. Jobs and results are JSON in the format of the HTTP API
+	of server.go, with the endpoint as the kind of the job and its answer as the result:
+		{"id": "...", "kind": "open", "vector": [n entries], "indices": [...]}
+		{"id": "...", "kind": "aggregate", "commitment": hex, "openings": [...]}
+		-> {"id": "...", "result": {...}} or {"id": "...", "error": "..."}
+	A malformed job gets a result carrying the error like a failing one. The queue only moves the bytes,
+	MemoryQueue within a process and NATSQueue (jobs_nats.go, built with -tags nats) over NATS.
+*/
+
+// ErrQueueClosed is returned by JobQueue.Receive once the queue is closed and drained
+var ErrQueueClosed = errors.New("queue closed")
+
+// JobDelivery is a job as a queue hands it to a worker
+type JobDelivery struct {
+	// Data is the JSON of the job
+	Data []byte
+	// Publish publishes the JSON of the result
+	Publish func(result []byte) error
+}
+
+// JobQueue delivers jobs to workers
+type JobQueue interface {
+	// Receive blocks until a job arrives, it fails with ErrQueueClosed or the error of ctx
+	Receive(ctx context.Context) (*JobDelivery, error)
+}
+
+// Job is a job in the format described above
+type Job struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	apiRequest
+}
+
+// JobResult is the result of a job
+type JobResult struct {
+	ID     string          `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// jobHandlers are the kinds of jobs, the proving endpoints of server.go
+var jobHandlers = map[string]func(*apiRequest) interface{}{
+	"open":      apiOpen,
+	"aggregate": apiAggregate,
+}
+
+// runJob processes the JSON of a job and returns the result. The handlers panic on malformed input
+// like the endpoints do, which is the error of the result
+func runJob(data []byte) (res JobResult) {
+	var job Job
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&job); err != nil {
+		return JobResult{Error: err.Error()}
+	}
+	res.ID = job.ID
+	handle, ok := jobHandlers[job.Kind]
+	if !ok {
+		res.Error = fmt.Sprintf("unknown kind %q", job.Kind)
+		return res
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			res.Result, res.Error = nil, fmt.Sprint(r)
+		}
+	}()
+	var err error
+	if res.Result, err = json.Marshal(handle(&job.apiRequest)); err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Worker processes the jobs of a queue under the installed parameters
+type Worker struct {
+	Queue JobQueue
+	// Concurrency bounds the jobs processed at once, it defaults to runtime.NumCPU()
+	Concurrency int
+}
+
+// Run processes jobs until the queue is closed, ctx is done or a result cannot be published, and returns
+// once the jobs it started are done. It returns nil for a closed queue and the error otherwise
+func (w *Worker) Run(ctx context.Context) error {
+	concurrency := w.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	slots := make(chan struct{}, concurrency)
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		delivery, err := w.Queue.Receive(ctx)
+		if err != nil {
+			<-slots
+			mu.Lock()
+			if firstErr == nil && !errors.Is(err, ErrQueueClosed) {
+				firstErr = err
+			}
+			mu.Unlock()
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			res := runJob(delivery.Data)
+			// a JobResult always marshals
+			data, _ := json.Marshal(res)
+			if err := delivery.Publish(data); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("publishing the result of job %q: %w", res.ID, err)
+				}
+				mu.Unlock()
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if firstErr == nil && ctx.Err() != nil {
+		// canceled by the caller, a failed publication would have set firstErr
+		return ctx.Err()
+	}
+	return firstErr
+}
+
+// MemoryQueue is a JobQueue within the process, it is safe for concurrent use
+type MemoryQueue struct {
+	jobs      chan *JobDelivery
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMemoryQueue returns a queue holding up to capacity jobs not yet received
+func NewMemoryQueue(capacity int) *MemoryQueue {
+	return &MemoryQueue{jobs: make(chan *JobDelivery, capacity), closed: make(chan struct{})}
+}
+
+// Submit queues the job and returns the channel its result arrives on, it blocks while the queue is full
+func (q *MemoryQueue) Submit(ctx context.Context, job *Job) (<-chan *JobResult, error) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	results := make(chan *JobResult, 1)
+	delivery := &JobDelivery{Data: data, Publish: func(data []byte) error {
+		res := new(JobResult)
+		if err := json.Unmarshal(data, res); err != nil {
+			return err
+		}
+		results <- res
+		return nil
+	}}
+	// a closed queue takes no more jobs, even if there is room
+	select {
+	case <-q.closed:
+		return nil, ErrQueueClosed
+	default:
+	}
+	select {
+	case q.jobs <- delivery:
+		return results, nil
+	case <-q.closed:
+		return nil, ErrQueueClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Receive implements JobQueue, jobs queued before Close are still delivered
+func (q *MemoryQueue) Receive(ctx context.Context) (*JobDelivery, error) {
+	select {
+	case delivery := <-q.jobs:
+		return delivery, nil
+	case <-q.closed:
+		select {
+		case delivery := <-q.jobs:
+			return delivery, nil
+		default:
+			return nil, ErrQueueClosed
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops the queue from taking jobs, workers stop once they received the ones queued
+func (q *MemoryQueue) Close() {
+	q.closeOnce.Do(func() { close(q.closed) })
+}
@@ -0,0 +1,56 @@
+//go:build nats
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nats-io/nats.go"
+)
+
+/*
+	NATSQueue takes jobs from a NATS subject, as a member of a queue group so that NATS hands every job
+	to one worker of the group. A job published with a reply subject, e.g. by nc.Request, gets its
+	result there, other results go to the results subject. Core NATS delivers at most once, a job in
+	flight when a worker dies is lost, and a client sending requests sees that as a timeout.
+*/
+
+// NATSQueue is a JobQueue over NATS
+type NATSQueue struct {
+	nc      *nats.Conn
+	sub     *nats.Subscription
+	results string
+}
+
+// NewNATSQueue subscribes to the jobs of subject in the queue group, publishing results without a reply
+// subject to results
+func NewNATSQueue(nc *nats.Conn, subject, group, results string) (*NATSQueue, error) {
+	sub, err := nc.QueueSubscribeSync(subject, group)
+	if err != nil {
+		return nil, err
+	}
+	return &NATSQueue{nc: nc, sub: sub, results: results}, nil
+}
+
+// Receive implements JobQueue
+func (q *NATSQueue) Receive(ctx context.Context) (*JobDelivery, error) {
+	msg, err := q.sub.NextMsgWithContext(ctx)
+	if errors.Is(err, nats.ErrBadSubscription) || errors.Is(err, nats.ErrConnectionClosed) {
+		return nil, ErrQueueClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &JobDelivery{Data: msg.Data, Publish: func(result []byte) error {
+		if msg.Reply != "" {
+			return msg.Respond(result)
+		}
+		return q.nc.Publish(q.results, result)
+	}}, nil
+}
+
+// Close unsubscribes, workers stop once they received the jobs already delivered
+func (q *NATSQueue) Close() error {
+	return q.sub.Unsubscribe()
+}
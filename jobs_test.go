@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// jobsFixture returns an open and an aggregate job on the fixture
+func jobsFixture(t *testing.T) (*Job, *Job) {
+	f := benchSetup(t)
+	vector := make([]string, n)
+	for i, v := range f.message {
+		vector[i] = v.String()
+	}
+	open := &Job{Kind: "open", apiRequest: apiRequest{Vector: vector, Indices: f.indices}}
+	aggregate := &Job{Kind: "aggregate", apiRequest: apiRequest{Commitment: hex.EncodeToString(encodeCommitment(f.com))}}
+	for k, i := range f.indices {
+		aggregate.Openings = append(aggregate.Openings, apiOpening{Index: i, Value: f.values[k].String(), Proof: hex.EncodeToString(encodeProof(f.proofs[k]))})
+	}
+	return open, aggregate
+}
+
+// TestWorkerConcurrent runs many jobs on a worker at once and compares the results to those of the jobs
+// run one at a time
+func TestWorkerConcurrent(t *testing.T) {
+	open, aggregate := jobsFixture(t)
+	want := make(map[string]string)
+	for _, job := range []*Job{open, aggregate} {
+		data, err := json.Marshal(job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res := runJob(data)
+		if res.Error != "" {
+			t.Fatalf("%s: %s", job.Kind, res.Error)
+		}
+		want[job.Kind] = string(res.Result)
+	}
+	var agg struct {
+		Proof string `json:"aggregated_proof"`
+	}
+	if err := json.Unmarshal([]byte(want["aggregate"]), &agg); err != nil {
+		t.Fatal(err)
+	}
+	verify := &apiRequest{Commitment: aggregate.Commitment, Indices: open.Indices, Proof: agg.Proof}
+	for _, o := range aggregate.Openings {
+		verify.Values = append(verify.Values, o.Value)
+	}
+	if !apiVerify(verify).(map[string]bool)["valid"] {
+		t.Fatal("aggregated proof of the sequential run rejected")
+	}
+
+	queue := NewMemoryQueue(16)
+	worker := &Worker{Queue: queue, Concurrency: 8}
+	done := make(chan error, 1)
+	go func() { done <- worker.Run(context.Background()) }()
+	const jobs = 200
+	results := make([]<-chan *JobResult, jobs)
+	for k := range results {
+		job := *aggregate
+		if k%10 == 0 {
+			job = *open
+		}
+		job.ID = fmt.Sprint(k)
+		ch, err := queue.Submit(context.Background(), &job)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results[k] = ch
+	}
+	queue.Close()
+	for k, ch := range results {
+		res := <-ch
+		kind := "aggregate"
+		if k%10 == 0 {
+			kind = "open"
+		}
+		if res.ID != fmt.Sprint(k) || res.Error != "" || string(res.Result) != want[kind] {
+			t.Errorf("job %d (%s): got %+v", k, kind, res)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkerMalformedJob(t *testing.T) {
+	_, aggregate := jobsFixture(t)
+	job := *aggregate
+	job.Openings = append(job.Openings[:1:1], job.Openings[0])
+	data, err := json.Marshal(&job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := runJob(data); res.Error == "" || res.Result != nil {
+		t.Fatalf("duplicate index accepted: %+v", res)
+	}
+	if res := runJob([]byte(`{"kind": "commit"}`)); res.Error == "" {
+		t.Fatal("unknown kind accepted")
+	}
+}
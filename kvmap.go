@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Key-value maps. A key may be stored at any of the kvMaxProbes positions following the one its hash
+	picks, its probe sequence: Put takes the first free one, so a key only fails to fit once all of them
+	are taken, which with n = 1024 does not happen before the map is about half full. The entry
+	at a position encodes the probe number, the key and the value with the EntryEncoder of the map, so
+	opening the position proves the value of the key. A proof of non-membership opens every position
+	of the probe sequence at once with an aggregated proof, each either zero or holding another key.
+	It does not rely on how the map was filled, Delete may leave holes in the probe sequences of other
+	keys, so it holds against any commitment. Larger key spaces go into a VerkleTree. The commitment
+	follows every change with one scalar multiplication.
+*/
+
+// kvSlotDST separates the hashes picking positions from the entries
+const kvSlotDST = "POINTPROOFS-V01-KV-SLOT_XMD:SHA-256"
+
+// kvMaxProbes is the length of the probe sequence of a key
+const kvMaxProbes = 16
+
+// kvHome returns the position the hash of the key picks, the start of its probe sequence
+func kvHome(key []byte) int {
+	h := hashToField(key, []byte(kvSlotDST), 1)[0]
+	return int(h.Mod(h, big.NewInt(n)).Int64())
+}
+
+// kvSlot returns the position of the key at the given probe of its sequence
+func kvSlot(key []byte, probe int) int {
+	if !(0 <= probe && probe < kvMaxProbes) {
+		panic("out of range probe")
+	}
+	return (kvHome(key) + probe) % n
+}
+
+// kvProbe returns the probe at which the key sits at the position and whether the position belongs to
+// its probe sequence at all
+func kvProbe(key []byte, slot int) (int, bool) {
+	probe := (slot - kvHome(key) + n) % n
+	return probe, probe < kvMaxProbes
+}
+
+// kvEntry returns the entry of key and value at the probe of their sequence, the key is length prefixed
+// so that no other pair of key and value gives the same bytes
+func kvEntry(e *EntryEncoder, key []byte, probe int, value []byte) *big.Int {
+	data := make([]byte, 8, 8+len(key)+len(value))
+	binary.BigEndian.PutUint32(data, uint32(probe))
+	binary.BigEndian.PutUint32(data[4:], uint32(len(key)))
+	data = append(append(data, key...), value...)
+	return e.Encode(kvSlot(key, probe), data)
+}
+
+// KVMap is a committed map from byte string keys to byte string values, it is not safe for concurrent use
+type KVMap struct {
+	encoder *EntryEncoder
+	// keys holds nil at free positions
+	keys    [n][]byte
+	values  [n][]byte
+	message []*big.Int
+	com     *Commitment
+}
+
+// NewKVMap returns the empty map of the application, see NewEntryEncoder
+func NewKVMap(application string) *KVMap {
+	kv := &KVMap{encoder: NewEntryEncoder(application), message: make([]*big.Int, n), com: (*Commitment)(bls.NewG1().Zero())}
+	for i := range kv.message {
+		kv.message[i] = new(big.Int)
+	}
+	return kv
+}
+
+// Commitment returns the commitment to the map, it changes with every Put and Delete
+func (kv *KVMap) Commitment() *bls.PointG1 {
+	return new(bls.PointG1).Set(kv.com.Point())
+}
+
+// position returns the probe of a present key and true, or the first free probe of an absent key
+// and false, -1 if its whole probe sequence is taken
+func (kv *KVMap) position(key []byte) (int, bool) {
+	free := -1
+	for probe := 0; probe < kvMaxProbes; probe++ {
+		slot := kvSlot(key, probe)
+		if kv.keys[slot] == nil {
+			if free < 0 {
+				free = probe
+			}
+		} else if bytes.Equal(kv.keys[slot], key) {
+			return probe, true
+		}
+	}
+	return free, false
+}
+
+// Get returns the value of the key and whether it is present
+func (kv *KVMap) Get(key []byte) ([]byte, bool) {
+	probe, ok := kv.position(key)
+	if !ok {
+		return nil, false
+	}
+	return kv.values[kvSlot(key, probe)], true
+}
+
+// set stores the entry at the position and updates the commitment
+func (kv *KVMap) set(slot int, key, value []byte, entry *big.Int) {
+	kv.com.Update(slot, kv.message[slot], entry)
+	kv.message[slot] = entry
+	kv.keys[slot], kv.values[slot] = key, value
+}
+
+// Put stores the value under the key, it fails if every position of the probe sequence of the key is
+// taken by other keys
+func (kv *KVMap) Put(key, value []byte) error {
+	probe, _ := kv.position(key)
+	if probe < 0 {
+		return fmt.Errorf("all %d positions of the key are taken", kvMaxProbes)
+	}
+	key, value = append([]byte{}, key...), append([]byte{}, value...)
+	kv.set(kvSlot(key, probe), key, value, kvEntry(kv.encoder, key, probe, value))
+	return nil
+}
+
+// Delete removes the key, it does nothing if the key is not present
+func (kv *KVMap) Delete(key []byte) {
+	if probe, ok := kv.position(key); ok {
+		kv.set(kvSlot(key, probe), nil, nil, new(big.Int))
+	}
+}
+
+// KVMembershipProof proves the value of a key
+type KVMembershipProof struct {
+	// Probe is where in its probe sequence the key sits
+	Probe int
+	Proof *bls.PointG1
+}
+
+// ProveMembership returns the value of a present key and the proof of it, it panics if the key is absent
+func (kv *KVMap) ProveMembership(key []byte) ([]byte, *KVMembershipProof) {
+	probe, ok := kv.position(key)
+	if !ok {
+		panic("the key is not present")
+	}
+	slot := kvSlot(key, probe)
+	return kv.values[slot], &KVMembershipProof{Probe: probe, Proof: ProveSet(kv.message, []int{slot})[0]}
+}
+
+// VerifyKVMembership reports whether proof shows that the map of the application with commitment com
+// holds the value under the key
+func VerifyKVMembership(com *bls.PointG1, application string, key, value []byte, proof *KVMembershipProof) bool {
+	if !(0 <= proof.Probe && proof.Probe < kvMaxProbes) {
+		return false
+	}
+	entry := kvEntry(NewEntryEncoder(application), key, proof.Probe, value)
+	return verifySingleProof(com, entry, proof.Proof, kvSlot(key, proof.Probe))
+}
+
+// KVNonMembershipProof proves a key absent: every position of its probe sequence is empty or holds
+// another key
+type KVNonMembershipProof struct {
+	// OtherKeys and OtherValues are the pairs along the probe sequence, nil keys at empty positions
+	OtherKeys, OtherValues [kvMaxProbes][]byte
+	// Proof opens the positions to the entries of these pairs, see AggregateSameCommitment
+	Proof *bls.PointG1
+}
+
+// kvSequence returns the positions of the probe sequence of the key and the entries the non-membership
+// proof claims for them, false if a claimed pair cannot sit at its position
+func kvSequence(e *EntryEncoder, key []byte, proof *KVNonMembershipProof) ([]int, []*big.Int, bool) {
+	slots := make([]int, kvMaxProbes)
+	entries := make([]*big.Int, kvMaxProbes)
+	for j := range slots {
+		slots[j] = kvSlot(key, j)
+		other := proof.OtherKeys[j]
+		if other == nil {
+			entries[j] = new(big.Int)
+			continue
+		}
+		probe, ok := kvProbe(other, slots[j])
+		if !ok || bytes.Equal(other, key) {
+			return nil, nil, false
+		}
+		entries[j] = kvEntry(e, other, probe, proof.OtherValues[j])
+	}
+	return slots, entries, true
+}
+
+// ProveNonMembership returns the proof that the key is absent, it panics if the key is present
+func (kv *KVMap) ProveNonMembership(key []byte) *KVNonMembershipProof {
+	if _, ok := kv.position(key); ok {
+		panic("the key is present")
+	}
+	res := &KVNonMembershipProof{}
+	slots := make([]int, kvMaxProbes)
+	for j := range slots {
+		slots[j] = kvSlot(key, j)
+		res.OtherKeys[j], res.OtherValues[j] = kv.keys[slots[j]], kv.values[slots[j]]
+	}
+	openings := make([]Opening, kvMaxProbes)
+	for j, proof := range ProveSet(kv.message, slots) {
+		openings[j] = Opening{Index: slots[j], Value: kv.message[slots[j]], Proof: proof}
+	}
+	res.Proof = AggregateSameCommitment(kv.Commitment(), openings)
+	return res
+}
+
+// VerifyKVNonMembership reports whether proof shows that the map of the application with commitment com
+// does not hold the key
+func VerifyKVNonMembership(com *bls.PointG1, application string, key []byte, proof *KVNonMembershipProof) bool {
+	slots, entries, ok := kvSequence(NewEntryEncoder(application), key, proof)
+	return ok && VerifySameCommitment(com, slots, entries, proof.Proof)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// TestKVMap fills the map far beyond what one position per key allows, so keys share probe sequences
+func TestKVMap(t *testing.T) {
+	benchSetup(t)
+	const app, keys = "kvmap-test", 300
+	kv := NewKVMap(app)
+	key := func(k int) []byte { return []byte(fmt.Sprintf("key-%d", k)) }
+	displaced := 0
+	for k := 0; k < keys; k++ {
+		if err := kv.Put(key(k), []byte(fmt.Sprint(k))); err != nil {
+			t.Fatalf("key %d: %v", k, err)
+		}
+		if probe, _ := kv.position(key(k)); probe > 0 {
+			displaced++
+		}
+	}
+	if displaced == 0 {
+		t.Fatal("no key left its home position")
+	}
+	for k := 0; k < keys; k++ {
+		if v, ok := kv.Get(key(k)); !ok || string(v) != fmt.Sprint(k) {
+			t.Fatalf("key %d: %q, %v", k, v, ok)
+		}
+	}
+
+	// Delete leaves a hole in the probe sequences running through the position
+	var deleted []byte
+	for k := 0; k < keys && deleted == nil; k++ {
+		probe, _ := kv.position(key(k))
+		slot := kvSlot(key(k), probe)
+		for j := k + 1; j < keys; j++ {
+			if p, _ := kv.position(key(j)); p > 0 && kvSlot(key(j), p-1) == slot {
+				deleted = key(k)
+			}
+		}
+	}
+	if deleted == nil {
+		t.Fatal("no key sits behind another one")
+	}
+	kv.Delete(deleted)
+	com := kv.Commitment()
+	for k := 0; k < keys; k += 7 {
+		if bytes.Equal(key(k), deleted) {
+			continue
+		}
+		value, proof := kv.ProveMembership(key(k))
+		if !VerifyKVMembership(com, app, key(k), value, proof) {
+			t.Fatalf("membership of key %d rejected", k)
+		}
+		if VerifyKVMembership(com, app, key(k), []byte("wrong"), proof) {
+			t.Fatalf("wrong value of key %d accepted", k)
+		}
+		moved := &KVMembershipProof{Probe: (proof.Probe + 1) % kvMaxProbes, Proof: proof.Proof}
+		if VerifyKVMembership(com, app, key(k), value, moved) {
+			t.Fatalf("key %d accepted at another probe", k)
+		}
+	}
+
+	for _, absent := range [][]byte{deleted, []byte("never stored")} {
+		proof := kv.ProveNonMembership(absent)
+		if !VerifyKVNonMembership(com, app, absent, proof) {
+			t.Fatalf("non-membership of %q rejected", absent)
+		}
+	}
+	// a present key cannot be shown absent by leaving its own position out
+	present := key(1)
+	probe, _ := kv.position(present)
+	slot := kvSlot(present, probe)
+	kv.keys[slot] = nil
+	forged := kv.ProveNonMembership(present)
+	kv.keys[slot] = present
+	if VerifyKVNonMembership(com, app, present, forged) {
+		t.Fatal("non-membership of a present key accepted")
+	}
+	forged.OtherKeys[probe] = present
+	if VerifyKVNonMembership(com, app, present, forged) {
+		t.Fatal("non-membership naming the key itself accepted")
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	KZG polynomial commitments on the same parameters. g_1, pp1[0 : n] = g_1^{alpha^1}, ..., g_1^{alpha^n}
+	and pp2[0] = g_2^{alpha} are a KZG setup for polynomials of degree at most n, the gap at alpha^{n+1}
+	is what keeps the degree from going up to 2n. A polynomial p(X) = \sum_i p_i X^i is committed as
+		C = p_0 * g_1 + \sum_{i >= 1} p_i * pp1[i - 1] = g_1^{p(alpha)}
+	and the proof of p(z) = y is the commitment pi to q(X) = (p(X) - y) / (X - z), checked by
+		e(C - y * g_1 + z * pi, g_2) = e(pi, g_2^{alpha})
+	Polynomial and vector commitments share nothing else, a KZG proof does not open a vector commitment
+	or the other way around.
+*/
+
+// kzgMaxDegree is the highest degree the parameters support
+const kzgMaxDegree = n
+
+// kzgBases returns g_1, g_1^{alpha}, ..., g_1^{alpha^d}
+func kzgBases(d int) []*bls.PointG1 {
+	return append([]*bls.PointG1{bls.NewG1().One()}, pp1Range(0, d)...)
+}
+
+// checkPolynomial panics unless the coefficients are field elements of a polynomial of degree at most n
+func checkPolynomial(coefficients []*big.Int) {
+	if len(coefficients) > kzgMaxDegree+1 {
+		panic("the degree exceeds n")
+	}
+	for _, c := range coefficients {
+		if c.Sign() < 0 || c.Cmp(frModulus) != -1 {
+			panic("the coefficient does not lie in the group")
+		}
+	}
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the coefficients p_0, ..., p_d of the polynomial, d <= n
+	It returns the KZG commitment to the polynomial.
+*/
+func KZGCommit(coefficients []*big.Int) *bls.PointG1 {
+	checkPolynomial(coefficients)
+	if len(coefficients) == 0 {
+		return bls.NewG1().Zero()
+	}
+	return currentMSM().MultiExpG1(kzgBases(len(coefficients)-1), frVector(coefficients))
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the coefficients p_0, ..., p_d of the polynomial, d <= n
+		4. the evaluation point z
+	It returns y = p(z) and the proof of it. The quotient q(X) = (p(X) - y) / (X - z) comes from synthetic
+	division, its coefficients are q_{i-1} = p_i + z * q_i from the top down, and the last step gives y.
+*/
+func KZGOpen(coefficients []*big.Int, z *big.Int) (*big.Int, *bls.PointG1) {
+	checkPolynomial(coefficients)
+	if len(coefficients) <= 1 {
+		y := new(big.Int)
+		if len(coefficients) == 1 {
+			y.Set(coefficients[0])
+		}
+		return y, bls.NewG1().Zero()
+	}
+	p := frVector(coefficients)
+	x := frFromBig(z)
+	quotient := make([]fr, len(p)-1)
+	var y fr
+	for i := len(p) - 1; i >= 0; i-- {
+		y.mul(y, x)
+		y.add(y, p[i])
+		if i > 0 {
+			quotient[i-1] = y
+		}
+	}
+	return y.big(), currentMSM().MultiExpG1(kzgBases(len(quotient)-1), quotient)
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the KZG commitment
+		4. the evaluation point z
+		5. the claimed value y
+		6. the proof of KZGOpen
+	It reports whether the proof shows p(z) = y for the committed polynomial p.
+*/
+func KZGVerify(com *bls.PointG1, z, y *big.Int, proof *bls.PointG1) bool {
+	return profiled("kzg_verify", func() bool {
+		// C - y * g_1 + z * pi in one MSM
+		var negY fr
+		negY.sub(negY, frFromBig(y))
+		g := getG1()
+		lhs := backend.MultiExpG1([]*bls.PointG1{com, g.One(), proof}, []fr{frOne, negY, frFromBig(z)})
+		putG1(g)
+		// e(C - y * g_1 + z * pi, g_2) * e(pi, g_2^{alpha})^{-1} = 1 = g_T^{alpha^{n+1} * 0}
+		return verifyPairings([]*bls.PointG1{lhs, negG1(proof)}, []*bls.PointG2{g2Generator, pp2[0]}, fr{})
+	})
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Lagrange (evaluation) basis parameters. A message m is committed as \sum m_i g1^{alpha^{i + 1}},
+	which is g1^{alpha * p(alpha)} for the polynomial p(X) = \sum m_i X^i. Over a domain
+	D = {shift * omega^k : 0 <= k < n}, omega a primitive n-th root of unity, the same commitment equals
+	\sum_k p(shift * omega^k) * g1^{alpha * L_k(alpha)} where L_k are the Lagrange polynomials of D.
+	Publishing L1[k] = g1^{alpha * L_k(alpha)} therefore lets data kept in evaluation form be committed
+	without converting it first. n has to be a power of two.
+*/
+
+// evaluationDomain is the coset shift * <omega> of size n
+type evaluationDomain struct {
+	omega fr
+	shift fr
+}
+
+// newEvaluationDomain returns the domain shift * <omega>, shift = 1 gives the subgroup of n-th roots of unity
+func newEvaluationDomain(shift *big.Int) *evaluationDomain {
+	s := frFromBig(shift)
+	if s.isZero() {
+		panic("the domain shift must not be zero")
+	}
+	return &evaluationDomain{omega: rootOfUnity(n), shift: s}
+}
+
+// scaleByShift multiplies v[i] by shift^{±i} in place
+func (d *evaluationDomain) scaleByShift(v []fr, inverse bool) {
+	s := d.shift
+	if inverse {
+		s.inverse(s)
+	}
+	power := frOne
+	for i := range v {
+		v[i].mul(v[i], power)
+		power.mul(power, s)
+	}
+}
+
+// checkVector panics unless v is a vector of n field elements, the same way commit does
+func checkVector(v []*big.Int) {
+	if len(v) != n {
+		panic("wrong array size")
+	}
+	q := bls.NewG1().Q()
+	for i := 0; i < n; i++ {
+		if v[i].Sign() < 0 || v[i].Cmp(q) != -1 {
+			panic("the message does not lie in the group")
+		}
+	}
+}
+
+// coefficientsToEvaluations turns a message m into its evaluations p(shift * omega^k), 0 <= k < n
+func coefficientsToEvaluations(message []*big.Int, d *evaluationDomain) []*big.Int {
+	checkVector(message)
+	res := frVector(message)
+	d.scaleByShift(res, false)
+	fftScalars(res, d.omega)
+	return bigVector(res)
+}
+
+// evaluationsToCoefficients is the inverse of coefficientsToEvaluations
+func evaluationsToCoefficients(evaluations []*big.Int, d *evaluationDomain) []*big.Int {
+	checkVector(evaluations)
+	res := frVector(evaluations)
+	ifftScalars(res, d.omega)
+	d.scaleByShift(res, true)
+	return bigVector(res)
+}
+
+// LagrangeParams are the G1 parameters in the Lagrange basis of a domain
+type LagrangeParams struct {
+	// Shift identifies the domain shift * <omega>
+	Shift *big.Int
+	// L1[k] = g1^{alpha * L_k(alpha)}
+	L1 [n]*bls.PointG1
+}
+
+/*
+	It takes the domain and returns the Lagrange basis parameters derived from pp.PP1[0 : n].
+	Since commit(m) = \sum_i m_i PP1[i] and m = evaluationsToCoefficients(e), L1 is the inverse
+	FFT of shift^{-i} * PP1[i] carried out in G1, which costs O(n log n) scalar multiplications.
+*/
+func (pp *PublicParams) lagrange(d *evaluationDomain) *LagrangeParams {
+	g := bls.NewG1()
+	var shiftInv fr
+	shiftInv.inverse(d.shift)
+	points := make([]*bls.PointG1, n)
+	power := frOne
+	for i := 0; i < n; i++ {
+		points[i] = g.MulScalar(g.New(), pp.g1Power(i), power.big())
+		power.mul(power, shiftInv)
+	}
+	ifftG1(g, points, d.omega)
+	batchAffineG1(points)
+	lp := &LagrangeParams{Shift: d.shift.big()}
+	copy(lp.L1[:], points)
+	return lp
+}
+
+// domain returns the evaluation domain the parameters were generated for
+func (lp *LagrangeParams) domain() *evaluationDomain {
+	return newEvaluationDomain(lp.Shift)
+}
+
+// Lagrange parameter files are the file header of curve.go with magic "PPLB" || shift || L1[0] || ... || L1[n - 1]
+const lagrangeMagic = "PPLB"
+
+// write serializes the Lagrange parameters
+func (lp *LagrangeParams) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := writeFileHeader(bw, lagrangeMagic, 0); err != nil {
+		return err
+	}
+	if err := writeScalar(bw, lp.Shift); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := writeG1(bw, lp.L1[i]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readLagrangeParams parses parameters written by LagrangeParams.write
+func readLagrangeParams(r io.Reader) (*LagrangeParams, error) {
+	br := bufio.NewReader(r)
+	if _, err := readFileHeader(br, lagrangeMagic); err != nil {
+		return nil, err
+	}
+	lp := &LagrangeParams{}
+	var err error
+	if lp.Shift, err = readScalar(br); err != nil {
+		return nil, err
+	}
+	if lp.Shift.Sign() == 0 {
+		return nil, errors.New("the domain shift must not be zero")
+	}
+	for i := 0; i < n; i++ {
+		if lp.L1[i], err = readG1(br); err != nil {
+			return nil, fmt.Errorf("L1[%d]: %w", i, err)
+		}
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, errors.New("trailing bytes after parameters")
+	}
+	return lp, nil
+}
+
+/*
+	It takes the following arguments:
+		1. the Lagrange parameters of the installed parameters
+		2. the evaluations e_k = p(shift * omega^k) of the message polynomial, 0 <= k < n
+	It returns the commitment to the message with those evaluations, the same point commit returns
+	for evaluationsToCoefficients(evaluations), with one MSM over L1 and no conversion.
+*/
+func (lp *LagrangeParams) Commit(evaluations []*big.Int) *bls.PointG1 {
+	checkVector(evaluations)
+	return currentMSM().MultiExpG1(lp.L1[:], frVector(evaluations))
+}
+
+// evaluationQuery returns the query q_i = x^i with x = shift * omega^k, so <m, q> = p(x) = e_k
+func (lp *LagrangeParams) evaluationQuery(k int) Query {
+	if !(0 <= k && k < n) {
+		panic("out of range index")
+	}
+	d := lp.domain()
+	var x fr
+	x.exp(d.omega, big.NewInt(int64(k)))
+	x.mul(x, d.shift)
+	q := Query{Indices: make([]int, n), Weights: make([]*big.Int, n)}
+	power := frOne
+	for i := 0; i < n; i++ {
+		q.Indices[i] = i
+		q.Weights[i] = power.big()
+		power.mul(power, x)
+	}
+	return q
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the evaluations the commitment of Commit was computed from
+		4. the position k to open
+	It returns the proof of e_k. An evaluation is the inner product of the coefficients with the powers
+	of shift * omega^k, so this is ProveInnerProduct on the coefficients, and verifying it takes an MSM
+	of n points in G2.
+*/
+func (lp *LagrangeParams) ProveEvaluation(evaluations []*big.Int, k int) *bls.PointG1 {
+	q := lp.evaluationQuery(k)
+	_, proof := ProveInnerProduct(evaluationsToCoefficients(evaluations, lp.domain()), q)
+	return proof
+}
+
+// VerifyEvaluation reports whether proof, from ProveEvaluation, opens position k of com to the evaluation
+func (lp *LagrangeParams) VerifyEvaluation(com *bls.PointG1, k int, evaluation *big.Int, proof *bls.PointG1) bool {
+	return VerifyInnerProduct(com, lp.evaluationQuery(k), evaluation, proof)
+}
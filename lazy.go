@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Lazy parameters. A process that only commits to short prefixes or opens a few indices touches a
+	fraction of the 2n powers in G1, yet decoding all of them, with the curve check of every point, is
+	most of the cost of loading a parameter file. Parameters read by readLazyPublicParams keep the G1
+	section encoded and decode every point the first time it is used. Their PP1 stays empty, everything
+	gets the powers through pp1Range and pp1Point, which decode them on the way. G2 is read as usual.
+	An invalid encoding only shows when its point is first used, which panics, so lazily read files
+	should come from a trusted place or have been checked by "params verify" before.
+*/
+
+// lazyG1 holds the encoded powers in G1 and the ones decoded so far
+type lazyG1 struct {
+	mu  sync.Mutex
+	raw []byte
+	// points[i] is the decoding of raw[i * g1Size:(i + 1) * g1Size], nil until it is first used
+	points  [2 * n]*bls.PointG1
+	decoded int
+}
+
+// pp1Lazy is the lazyG1 of the installed parameters, nil if they were read eagerly
+var pp1Lazy *lazyG1
+
+// load decodes the points lo to hi - 1 that have not been decoded yet and returns them
+func (l *lazyG1) load(lo, hi int) []*bls.PointG1 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var g *bls.G1
+	for i := lo; i < hi; i++ {
+		if l.points[i] != nil {
+			continue
+		}
+		if g == nil {
+			g = getG1()
+			defer putG1(g)
+		}
+		p, err := g.FromBytes(l.raw[i*g1Size : (i+1)*g1Size])
+		if err != nil {
+			panic(fmt.Sprintf("pp1[%d]: %v", i, err))
+		}
+		l.points[i] = p
+		l.decoded++
+	}
+	return l.points[lo:hi]
+}
+
+// memory returns the bytes taken by the encoding and the points decoded so far
+func (l *lazyG1) memory() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int64(len(l.raw)) + int64(l.decoded)*pointG1Bytes
+}
+
+// pp1Range returns pp1[lo:hi] of the installed parameters, decoding lazy points first
+func pp1Range(lo, hi int) []*bls.PointG1 {
+	if pp1Lazy != nil {
+		return pp1Lazy.load(lo, hi)
+	}
+	return pp1[lo:hi]
+}
+
+// pp1Point returns pp1[i] of the installed parameters, decoding it first if it is lazy
+func pp1Point(i int) *bls.PointG1 {
+	return pp1Range(i, i+1)[0]
+}
+
+// g1Power returns PP1[i], decoding it first if the parameters are lazy
+func (pp *PublicParams) g1Power(i int) *bls.PointG1 {
+	if pp.lazyPP1 != nil {
+		return pp.lazyPP1.load(i, i+1)[0]
+	}
+	return pp.PP1[i]
+}
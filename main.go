@@ -6,6 +6,9 @@ import (
 	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
 	"log"
 	"math/big"
+	"os"
+	"runtime"
+	"sync"
 )
 
 // constant n which is the length of the vectors in the scheme
@@ -28,6 +31,27 @@ var pp2 [n]*bls.PointG2
 	Note g_T^{alpha ^ {n +1}} can be computed later
 */
 func setup() (*bls.Engine, [2 * n]*bls.PointG1, [n]*bls.PointG2, *big.Int) {
+	return setupWithProgress(nil)
+}
+
+// setupWithProgress is setup reporting its progress to the given callback, which may be nil
+func setupWithProgress(progress progressFunc) (*bls.Engine, [2 * n]*bls.PointG1, [n]*bls.PointG2, *big.Int) {
+	engine, pp, alpha := setupWithOptions(setupOptions{progress: progress})
+	return engine, pp.PP1, pp.PP2, alpha
+}
+
+// setupOptions tunes setupWithOptions and setupStream, the zero value gives the parameters setup() returns
+type setupOptions struct {
+	// progress receives progress reports, it may be nil
+	progress progressFunc
+	// fullG2 additionally generates {g2 ^ {alpha ^ i}} for n + 1 < i <= 2n, see PublicParams.PP2Ext
+	fullG2 bool
+	// swapped marks the parameters as those of the swapped variant, see PublicParams.Swapped
+	swapped bool
+}
+
+// setupWithOptions is setup returning the parameters as PublicParams
+func setupWithOptions(opts setupOptions) (*bls.Engine, *PublicParams, *big.Int) {
 	engine := bls.NewPairingEngine()
 	// alpha is large number and cannot be generated using normal rand.int()
 	// Instead we generate a random byte array and convert it into big.Int and set it modulo the order of the group
@@ -40,34 +64,61 @@ func setup() (*bls.Engine, [2 * n]*bls.PointG1, [n]*bls.PointG2, *big.Int) {
 	temp.SetBytes(buf)
 	alpha := big.NewInt(0)
 	alpha.Mod(temp, engine.G1.Q())
+	// one unit of work per point
+	work := 3 * n
+	if opts.fullG2 {
+		work += n
+	}
+	tracker := newProgressTracker(opts.progress, work)
+	// powers[i] = alpha ^ i
+	powers := make([]fr, 2*n+1)
+	powers[0] = frOne
+	a := frFromBig(alpha)
+	for i := 1; i < len(powers); i++ {
+		powers[i].mul(powers[i-1], a)
+	}
 	// generate array of g1 ^ {alpha ^ i}} for 1 <= i <= 2n except for N + 1
 	var pp1 [2 * n]*bls.PointG1
 	for i := 1; i < 2*n+1; i++ {
 		if i == n+1 {
 			pp1[i-1] = engine.G1.Zero()
 		} else {
-			// compute alpha ^ i
-			temp = big.NewInt(0)
-			temp.Exp(alpha, big.NewInt(int64(i)), engine.G1.Q())
 			c := engine.G1.New()
-			engine.G1.MulScalar(c, engine.G1.One(), temp)
+			engine.G1.MulScalar(c, engine.G1.One(), powers[i].big())
 			pp1[i-1] = c
 		}
+		tracker.add(1)
 	}
 	// generate array of {g2 ^ {alpha ^ i}} for 1 <= i <= n
 	var pp2 [n]*bls.PointG2
 	for i := 0; i < n; i++ {
-		// compute alpha ^ i
-		temp = big.NewInt(0)
-		temp.Exp(alpha, big.NewInt(int64(i+1)), engine.G1.Q())
 		c := engine.G2.New()
-		engine.G2.MulScalar(c, engine.G2.One(), temp)
+		engine.G2.MulScalar(c, engine.G2.One(), powers[i+1].big())
 		pp2[i] = c
+		tracker.add(1)
+	}
+	pp := &PublicParams{PP1: pp1, PP2: pp2, Swapped: opts.swapped}
+	// generate {g2 ^ {alpha ^ i}} for n + 1 <= i <= 2n except for N + 1, only on request
+	if opts.fullG2 {
+		pp.PP2Ext = make([]*bls.PointG2, n)
+		for i := n + 1; i < 2*n+1; i++ {
+			c := engine.G2.New()
+			if i != n+1 {
+				engine.G2.MulScalar(c, engine.G2.One(), powers[i].big())
+			}
+			pp.PP2Ext[i-n-1] = c
+			tracker.add(1)
+		}
 	}
+	// store everything in affine form, see affine.go
+	pp.normalize()
 	// returning the values
-	return engine, pp1, pp2, alpha
+	return engine, pp, alpha
 }
 
+// commitWorkers is the number of goroutines commit uses, at most one per CPU is useful
+var commitWorkers = runtime.NumCPU()
+
 /*
 	It takes the following arguments
 		1. bls.Engine (implicitly)
@@ -76,28 +127,24 @@ func setup() (*bls.Engine, [2 * n]*bls.PointG1, [n]*bls.PointG2, *big.Int) {
 	It output a single group G1 point
 */
 func commit(message []*big.Int) *bls.PointG1 {
-	// Check length of the array
-	if len(message) != n {
-		panic("wrong array size")
-	}
-	// First checking if the message lies in the field, 0 <= vector < p = engine.G1.Q()
-	for i := 0; i < n; i++ {
-		if message[i].Cmp(engine.G1.Q()) != -1 {
-			panic("the message does not lie in the group")
+	return profiled("commit", func() *bls.PointG1 {
+		// Check length of the array
+		if len(message) != n {
+			panic("wrong array size")
 		}
-		if message[i].Cmp(big.NewInt(0)) == -1 {
-			panic("the message does not lie in the group")
+		// First checking if the message lies in the field, 0 <= vector < p = engine.G1.Q()
+		q := engine.G1.Q()
+		for i := 0; i < n; i++ {
+			if message[i].Cmp(q) != -1 {
+				panic("the message does not lie in the group")
+			}
+			if message[i].Cmp(big.NewInt(0)) == -1 {
+				panic("the message does not lie in the group")
+			}
 		}
-	}
-	// res, first set it to zero
-	com := engine.G1.Zero()
-	for i := 0; i < n; i++ {
-		temp := engine.G1.New()
-		engine.G1.MulScalar(temp, pp1[i], message[i])
-		engine.G1.Add(com, com, temp)
-	}
-	// return of the commitment value
-	return com
+		// \sum m_i * pp1[i] in a single multi-scalar multiplication, see backend.go and offload.go
+		return currentMSM().MultiExpG1(pp1Range(0, n), frVector(message))
+	})
 }
 
 /*
@@ -108,36 +155,40 @@ func commit(message []*big.Int) *bls.PointG1 {
 	4. index
 */
 func generateProofSingle(message []*big.Int, index int) *bls.PointG1 {
-	/*
-		// Check length of the array
-		if len(message) != n {
-			panic("wrong array size")
-		}
-		// First checking if the message lies in the field, 0 <= vector < p = engine.G1.Q()
-		for i := 0; i < n; i++ {
-			if message[i].Cmp(engine.G1.Q()) != -1 {
-				panic("the message does not lie in the group")
+	return profiled("prove", func() *bls.PointG1 {
+		/*
+			// Check length of the array
+			if len(message) != n {
+				panic("wrong array size")
 			}
-			if message[i].Cmp(big.NewInt(0)) == -1 {
-				panic("the message does not lie in the group")
+			// First checking if the message lies in the field, 0 <= vector < p = engine.G1.Q()
+			for i := 0; i < n; i++ {
+				if message[i].Cmp(engine.G1.Q()) != -1 {
+					panic("the message does not lie in the group")
+				}
+				if message[i].Cmp(big.NewInt(0)) == -1 {
+					panic("the message does not lie in the group")
+				}
+			}
+			// Making sure in index lies in the boundaries
+			if !(0 <= index && index < n) {
+				panic("out of range index")
+			}
+		*/
+		g := getG1()
+		defer putG1(g)
+		// res, first set it to zero
+		proof := g.Zero()
+		// scratch point reused for every term
+		temp := g.New()
+		for j := 0; j < n; j++ {
+			if j != index {
+				g.Add(proof, proof, pp1Mul(g, temp, n-index+j, message[j]))
 			}
 		}
-		// Making sure in index lies in the boundaries
-		if !(0 <= index && index < n) {
-			panic("out of range index")
-		}
-	*/
-	// res, first set it to zero
-	proof := engine.G1.Zero()
-	for j := 0; j < n; j++ {
-		if j != index {
-			temp := engine.G1.New()
-			engine.G1.MulScalar(temp, pp1[n-index+j], message[j])
-			engine.G1.Add(proof, proof, temp)
-		}
-	}
-	// return of the commitment value
-	return proof
+		// return of the commitment value
+		return proof
+	})
 }
 
 /*
@@ -150,23 +201,14 @@ func generateProofSingle(message []*big.Int, index int) *bls.PointG1 {
 		6. pp1 and pp2 (implicitly)
 */
 func verifySingleProof(com *bls.PointG1, entry *big.Int, proof *bls.PointG1, index int) bool {
-	// Making sure in index lies in the boundaries
-	if !(0 <= index && index < n) {
-		panic("out of range index")
-	}
-	// e(C, g_2^{alpha^{N+1-i}})
-	lhs := engine.AddPair(com, pp2[n-index-1]).Result()
-	engine.Reset()
-	// e(proof, g_2)
-	temp1 := engine.AddPair(proof, engine.G2.One()).Result()
-	engine.Reset()
-	// g_T^{alpha^{n+1}*m_i} = e(g_1^{alpha * m_i}, g_2^{alpha^{n})
-	temp2 := pp1[0]
-	engine.G1.MulScalar(temp2, temp2, entry)
-	rhs := engine.AddPair(temp2, pp2[n-1]).Result()
-	engine.Reset()
-	engine.GT().Mul(rhs, temp1, rhs)
-	return lhs.Equal(rhs)
+	return profiled("verify", func() bool {
+		// Making sure in index lies in the boundaries
+		if !(0 <= index && index < n) {
+			panic("out of range index")
+		}
+		// e(C, g_2^{alpha^{N+1-i}}) * e(proof, g_2)^{-1} = g_T^{alpha^{n+1}*m_i} in a single multi-pairing
+		return verifyPairings([]*bls.PointG1{com, negG1(proof)}, []*bls.PointG2{pp2[n-index-1], g2Generator}, frFromBig(entry))
+	})
 }
 
 /*
@@ -182,11 +224,15 @@ func aggregateProof(proofs []*bls.PointG1, scalars []*big.Int, number int) *bls.
 	if !(len(proofs) == number && len(scalars) == number) {
 		panic("arrays with incorrect length")
 	}
-	res := engine.G1.Zero()
+	// the global engine cannot be shared by aggregations running at the same time
+	g := getG1()
+	defer putG1(g)
+	res := g.Zero()
+	temp := g.New()
 	for i := 0; i < number; i++ {
-		temp := engine.G1.New()
-		engine.G1.MulScalar(temp, proofs[i], scalars[i])
-		engine.G1.Add(res, res, temp)
+		// wNAF needs about a third of the additions of MulScalar, see wnaf.go
+		mulG1(g, temp, proofs[i], frFromBig(scalars[i]))
+		g.Add(res, res, temp)
 	}
 	return res
 }
@@ -204,45 +250,34 @@ func aggregateProof(proofs []*bls.PointG1, scalars []*big.Int, number int) *bls.
 		9. number of messages
 */
 func verifySameCommitmentAggregation(com *bls.PointG1, proof *bls.PointG1, messages []*big.Int, scalars []*big.Int, indices []int, number int) bool {
-	// check if the arrays message, indices, and scalar are of the right size
-	if !(len(messages) == number && len(scalars) == number && len(indices) == number) {
-		panic("arrays with incorrect length")
-	}
-	// Making sure the indices are in the right boundaries
-	for j := 0; j < number; j++ {
-		if !(0 <= indices[j] && indices[j] < n) {
-			panic("out of range index")
+	observeBatch("verify_same", number)
+	return profiled("verify_same", func() bool {
+		// check if the arrays message, indices, and scalar are of the right size
+		if !(len(messages) == number && len(scalars) == number && len(indices) == number) {
+			panic("arrays with incorrect length")
 		}
-	}
-	// First compute \prod g_2^{alpha^{n+1-i}t_i}
-	prod := engine.G2.Zero()
-	for i := 0; i < number; i++ {
-		temp := engine.G2.New()
-		// this fucking line of code took 2 fucking hours to debug :')
-		engine.G2.MulScalar(temp, pp2[n-indices[i]-1], scalars[i])
-		engine.G2.Add(prod, prod, temp)
-	}
-	// compute the left hand side
-	lhs := engine.AddPair(com, prod).Result()
-	engine.Reset()
-	// e(proof, g_2)
-	temp1 := engine.AddPair(proof, engine.G2.One()).Result()
-	engine.Reset()
-	// sum will be equal to \sum m_it_i
-	sum := big.NewInt(0)
-	for i := 0; i < number; i++ {
-		temp := big.NewInt(0)
-		temp.Mul(messages[i], scalars[i])
-		sum.Add(sum, temp)
-	}
-	// g_T^{alpha^{n+1} * sum} = e(g_1^{alpha * m_i * t_i}, g_2^{alpha^{n})
-	temp2 := pp1[0]
-	engine.G1.MulScalar(temp2, temp2, sum)
-	rhs := engine.AddPair(temp2, pp2[n-1]).Result()
-	engine.Reset()
-	engine.GT().Mul(rhs, temp1, rhs)
-	// check if right hand size and left hand sise are equal
-	return lhs.Equal(rhs)
+		// Making sure the indices are in the right boundaries
+		for j := 0; j < number; j++ {
+			if !(0 <= indices[j] && indices[j] < n) {
+				panic("out of range index")
+			}
+		}
+		// First compute \prod g_2^{alpha^{n+1-i}t_i}
+		bases := make([]*bls.PointG2, number)
+		for i := 0; i < number; i++ {
+			// this fucking line of code took 2 fucking hours to debug :')
+			bases[i] = pp2[n-indices[i]-1]
+		}
+		prod := backend.MultiExpG2(bases, frVector(scalars))
+		// sum will be equal to \sum m_it_i, reduced modulo the group order as it is accumulated
+		var sum, product fr
+		for i := 0; i < number; i++ {
+			product.mul(frFromBig(messages[i]), frFromBig(scalars[i]))
+			sum.add(sum, product)
+		}
+		// e(C, prod) * e(proof, g_2)^{-1} = g_T^{alpha^{n+1} * sum} in a single multi-pairing
+		return verifyPairings([]*bls.PointG1{com, negG1(proof)}, []*bls.PointG2{prod, g2Generator}, sum)
+	})
 }
 
 /*
@@ -257,58 +292,76 @@ It takes the following arguments (m is the total number of commitments)
 	8. total number = m
 */
 func verifyCrossCommitmentAggregation(com []*bls.PointG1, proof *bls.PointG1, messages []*[]*big.Int, messageScalars []*[]*big.Int, comScalars []*big.Int, indices []*[]int, number []int, totalNum int) bool {
-	// check if the arrays message, indices, and scalar are of the right size
-	if !(len(com) == totalNum && len(comScalars) == totalNum && len(number) == totalNum) {
-		panic("arrays with incorrect length")
-	}
-	// For the sake of performance we don't check if the indices provided are in the right range
-	for j := 0; j < totalNum; j++ {
-		if !(len(*messages[j]) == number[j] && len(*messageScalars[j]) == number[j] && len(*indices[j]) == number[j]) {
+	return profiled("verify_cross", func() bool {
+		// check if the arrays message, indices, and scalar are of the right size
+		if !(len(com) == totalNum && len(comScalars) == totalNum && len(number) == totalNum) {
 			panic("arrays with incorrect length")
 		}
-	}
-	// computing left hand side
-	// zero is zero in G_t
-	lhs := engine.AddPair(engine.G1.Zero(), engine.G2.New()).Result()
-	engine.Reset()
-	for j := 0; j < totalNum; j++ {
-		prod := engine.G2.Zero()
-		for i := 0; i < number[j]; i++ {
-			temp := engine.G2.New()
-			// this fucking line of code took 2 fucking hours to debug :')
-			engine.G2.MulScalar(temp, pp2[n-(*indices[j])[i]-1], (*messageScalars[j])[i])
-			engine.G2.Add(prod, prod, temp)
+		for j := 0; j < totalNum; j++ {
+			if !(len(*messages[j]) == number[j] && len(*messageScalars[j]) == number[j] && len(*indices[j]) == number[j]) {
+				panic("arrays with incorrect length")
+			}
+			// the indices are checked up front, a panic in one of the workers below could not be recovered
+			for _, index := range *indices[j] {
+				if !(0 <= index && index < n) {
+					panic("out of range index")
+				}
+			}
 		}
-		// compute the left hand side
-		temp := engine.AddPair(com[j], prod).Result()
-		engine.Reset()
-		res := engine.GT().New()
-		engine.GT().Exp(res, temp, comScalars[j])
-		engine.GT().Mul(lhs, res, lhs)
-	}
-	// computing right hand side
-	// e(proof, g_2)
-	temp1 := engine.AddPair(proof, engine.G2.One()).Result()
-	engine.Reset()
-	// sum will be equal to \sum m_{j, i}t_{j, i}t_j'
-	sum := big.NewInt(0)
-	for j := 0; j < totalNum; j++ {
-		for i := 0; i < number[j]; i++ {
-			temp := big.NewInt(0)
-			temp.Mul((*messages[j])[i], (*messageScalars[j])[i])
-			temp.Mul(temp, comScalars[j])
-			sum.Add(sum, temp)
+		total := 0
+		for _, k := range number {
+			total += k
 		}
-	}
-	// g_T^{alpha^{n+1} * sum} = e(g_1^{alpha * m_i * t_i}, g_2^{alpha^{n})
-	temp := pp1[0]
-	engine.G1.MulScalar(temp, temp, sum)
-	rhs := engine.AddPair(temp, pp2[n-1]).Result()
-	engine.Reset()
-	engine.GT().Mul(rhs, temp1, rhs)
-	// check if right hand side and left hand side are equal
-	return lhs.Equal(rhs)
+		observeBatch("verify_cross", total)
+		// computing left hand side \prod e(com_j^{t_j}, prod_j), raising com_j in G1 is cheaper than in G_t.
+		// Folding t_j into the G2 scalars instead saves the G1 multiplication but widens them to full
+		// width: BenchmarkCrossCommitmentScaling has it 70% slower with 128 bit t_{j,i} and only 4%
+		// faster with full width ones, so the G1 scaling stays. The commitments are independent, so they
+		// are spread over one goroutine per CPU
+		a := make([]*bls.PointG1, totalNum+1)
+		b := make([]*bls.PointG2, totalNum+1)
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < runtime.NumCPU(); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				// G1 holds temporaries, every goroutine needs its own
+				g := getG1()
+				defer putG1(g)
+				for j := range next {
+					bases := make([]*bls.PointG2, number[j])
+					for i := 0; i < number[j]; i++ {
+						// this fucking line of code took 2 fucking hours to debug :')
+						bases[i] = pp2[n-(*indices[j])[i]-1]
+					}
+					a[j] = mulG1(g, g.New(), com[j], frFromBig(comScalars[j]))
+					b[j] = backend.MultiExpG2(bases, frVector(*messageScalars[j]))
+				}
+			}()
+		}
+		for j := 0; j < totalNum; j++ {
+			next <- j
+		}
+		close(next)
+		wg.Wait()
+		// computing right hand side, e(proof, g_2) goes in inverted
+		a[totalNum] = negG1(proof)
+		b[totalNum] = g2Generator
+		// sum will be equal to \sum m_{j, i}t_{j, i}t_j', reduced modulo the group order as it is accumulated
+		var sum, product fr
+		for j := 0; j < totalNum; j++ {
+			t := frFromBig(comScalars[j])
+			for i := 0; i < number[j]; i++ {
+				product.mul(frFromBig((*messages[j])[i]), frFromBig((*messageScalars[j])[i]))
+				product.mul(product, t)
+				sum.add(sum, product)
+			}
+		}
+		// check if the product of the pairings is g_T^{alpha^{n+1} * sum}, i.e. right hand side and left hand side are equal
+		return verifyPairings(a, b, sum)
 
+	})
 }
 
 func generateBigIntegerArray(length int, mod *big.Int) []*big.Int {
@@ -332,12 +385,41 @@ func generateBigIntegerArray(length int, mod *big.Int) []*big.Int {
 	return res
 }
 
+// platformMain replaces the command line and the demo on platforms without them, see wasm.go
+var platformMain func()
+
+// configure selects the curve backend and the profile hook from the environment, see cliUsage
+func configure() error {
+	// the curve backend can be chosen without touching the code, see backend.go
+	selectDefaultBackend()
+	if name := os.Getenv("POINTPROOFS_BACKEND"); name != "" {
+		if err := SelectBackend(name); err != nil {
+			return err
+		}
+	}
+	if dir := os.Getenv("POINTPROOFS_PROFILE_DIR"); dir != "" {
+		SetProfileHook(FileProfileHook(dir))
+	}
+	return nil
+}
+
 func main() {
+	if err := configure(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if platformMain != nil {
+		platformMain()
+		return
+	}
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
 	// ******************************************* setup *******************************************
 	eng, arr1, arr2, _ := setup()
 	engine = eng
-	pp1 = arr1
-	pp2 = arr2
+	params := &PublicParams{PP1: arr1, PP2: arr2}
+	params.install()
 	// *************************************** first message ***************************************
 	// number of entries to be aggregated
 	n1 := 2
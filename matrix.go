@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Matrix commitments. A matrix of n columns is committed row by row, the commitment of the matrix is
+	the list of its row commitments. A cell is a single opening of its row, a column is a same-index
+	opening across all rows with ProveSameIndex, one G1 point for the whole column. A full row needs no
+	proof at all: the verifier recommits to it, one MSM, which is cheaper than checking any opening of
+	all n entries.
+*/
+
+// Matrix is the prover state of a matrix commitment
+type Matrix struct {
+	rows [][]*big.Int
+	coms []*bls.PointG1
+}
+
+// CommitMatrix commits to the rows of the matrix, each a vector of n field elements, with CommitMany
+func CommitMatrix(rows [][]*big.Int) *Matrix {
+	return &Matrix{rows: rows, coms: CommitMany(rows)}
+}
+
+// Commitments returns the row commitments, the commitment of the matrix
+func (m *Matrix) Commitments() []*bls.PointG1 {
+	res := make([]*bls.PointG1, len(m.coms))
+	for r, com := range m.coms {
+		res[r] = new(bls.PointG1).Set(com)
+	}
+	return res
+}
+
+// checkRow panics unless the row lies in the matrix
+func (m *Matrix) checkRow(row int) {
+	if !(0 <= row && row < len(m.rows)) {
+		panic("out of range row")
+	}
+}
+
+// OpenCell returns the entry of the matrix at the row and column and the proof of it
+func (m *Matrix) OpenCell(row, column int) (*big.Int, *bls.PointG1) {
+	m.checkRow(row)
+	return new(big.Int).Set(m.rows[row][column]), ProveSet(m.rows[row], []int{column})[0]
+}
+
+// VerifyMatrixCell reports whether proof, from OpenCell, opens the matrix to the value at the row and column
+func VerifyMatrixCell(coms []*bls.PointG1, row, column int, value *big.Int, proof *bls.PointG1) bool {
+	if !(0 <= row && row < len(coms)) {
+		panic("out of range row")
+	}
+	return verifySingleProof(coms[row], value, proof, column)
+}
+
+// OpenRow returns the row, which VerifyMatrixRow checks without a proof
+func (m *Matrix) OpenRow(row int) []*big.Int {
+	m.checkRow(row)
+	return append([]*big.Int{}, m.rows[row]...)
+}
+
+// VerifyMatrixRow reports whether the matrix has the values as its row
+func VerifyMatrixRow(coms []*bls.PointG1, row int, values []*big.Int) bool {
+	if !(0 <= row && row < len(coms)) {
+		panic("out of range row")
+	}
+	return bls.NewG1().Equal(coms[row], commit(values))
+}
+
+// OpenColumn returns the column of the matrix and the single proof of all its entries
+func (m *Matrix) OpenColumn(column int) ([]*big.Int, *bls.PointG1) {
+	return ProveSameIndex(m.coms, m.rows, column)
+}
+
+// VerifyMatrixColumn reports whether proof, from OpenColumn, opens the matrix to the values at the column
+func VerifyMatrixColumn(coms []*bls.PointG1, column int, values []*big.Int, proof *bls.PointG1) bool {
+	return VerifySameIndex(coms, column, values, proof)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"unsafe"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Memory estimates. Parameters for large n take a lot of memory once loaded: every G1 point is 144 bytes
+	in Jacobian form and every G2 point 288, both behind a pointer, and installing them derives the GT
+	table of target.go and, with backends that precompute them, the Miller loop lines of every point of
+	pp2. The estimates count those. They leave out what is only built on request, the fixed-base tables
+	(see fixedbase.go for their size) and the spectrum of ProveAll, as well as the transient memory of
+	the operations themselves.
+*/
+
+// MemoryEstimate is the memory, in bytes, taken by a parameter set for some n
+type MemoryEstimate struct {
+	// PP1 holds the 2n powers in G1
+	PP1 int64
+	// PP2 holds the n powers in G2
+	PP2 int64
+	// PP2Ext holds the optional upper powers in G2, zero if they are not present
+	PP2Ext int64
+	// Target is the table for g_T^{alpha^{n+1}} derived at install
+	Target int64
+	// Lines are the Miller loop lines the selected backend precomputes at install, zero if it does not
+	Lines int64
+	// File is the size of the parameter file, which is also what has to be downloaded
+	File int64
+}
+
+// Total returns the memory taken once the parameters are installed
+func (m MemoryEstimate) Total() int64 {
+	return m.PP1 + m.PP2 + m.PP2Ext + m.Target + m.Lines
+}
+
+const (
+	ptrSize      = int64(unsafe.Sizeof(uintptr(0)))
+	pointG1Bytes = int64(unsafe.Sizeof(bls.PointG1{})) + ptrSize
+	pointG2Bytes = int64(unsafe.Sizeof(bls.PointG2{})) + ptrSize
+)
+
+// EstimateParamsMemory returns the memory taken by parameters for vectors of length n without the optional
+// PP2Ext, which would add another n G2 points, for planning before they are generated or loaded
+func EstimateParamsMemory(n int) MemoryEstimate {
+	if n < 1 {
+		panic("vector length must be positive")
+	}
+	size := int64(n)
+	return MemoryEstimate{
+		PP1:    2 * size * pointG1Bytes,
+		PP2:    size * pointG2Bytes,
+		Target: gtTableBytes(),
+		Lines:  (size + 1) * precomputedLineBytes(),
+		File:   fileHeaderSize + 2*size*g1Size + size*g2Size,
+	}
+}
+
+// MemoryUsage returns the memory taken by the parameters for the compiled in n once installed
+func (pp *PublicParams) MemoryUsage() MemoryEstimate {
+	m := EstimateParamsMemory(n)
+	if pp.lazyPP1 != nil {
+		m.PP1 = pp.lazyPP1.memory()
+	}
+	if pp.PP2Ext != nil {
+		ext := int64(len(pp.PP2Ext))
+		m.PP2Ext = ext * pointG2Bytes
+		m.File += ext * g2Size
+	}
+	return m
+}
+
+// gtTableBytes returns the size of a gtTable, the windows over the bits of the group order
+func gtTableBytes() int64 {
+	windows := int64((frBitLen + gtTableWindow - 1) / gtTableWindow)
+	row := int64(1<<gtTableWindow-1) * (int64(unsafe.Sizeof(bls.E{})) + ptrSize)
+	return windows * (row + int64(unsafe.Sizeof([]*bls.E{})))
+}
+
+// precomputedLineBytes returns the size of the lines of a single G2 point if the backend precomputes them
+func precomputedLineBytes() int64 {
+	lp, ok := backend.(linePrecomputer)
+	if !ok {
+		return 0
+	}
+	return int64(lp.lineSize())
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+/*
+	Prometheus metrics. Every operation run by profiled (see profile.go), which covers committing,
+	proving, aggregating and verifying, is timed by its name, operations that panic, mostly on malformed
+	input, are counted as failures, and verifications are counted by their outcome. The operations over
+	many entries report how many, and the proof caches of Prover and VectorStore their hits and misses:
+		pointproofs_operation_duration_seconds{op}       histogram
+		pointproofs_operation_failures_total{op}         counter
+		pointproofs_verifications_total{op, result}      counter, result is "valid" or "invalid"
+		pointproofs_batch_size{op}                       histogram
+		pointproofs_proof_cache_requests_total{cache, result}
+		                                                 counter, result is "hit" or "miss"
+	MetricsHandler serves them together with the Go runtime and process metrics, "PointProofs serve"
+	at /metrics, and RegisterMetrics adds them to the registry of a service embedding the scheme.
+*/
+
+var (
+	operationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "pointproofs",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of the operations of the scheme.",
+		// 100us to about 100s
+		Buckets: prometheus.ExponentialBuckets(1e-4, 2.5, 16),
+	}, []string{"op"})
+	operationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "pointproofs",
+		Name:      "operation_failures_total",
+		Help:      "Operations that failed, mostly on malformed input.",
+	}, []string{"op"})
+	verificationResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "pointproofs",
+		Name:      "verifications_total",
+		Help:      "Verifications by outcome.",
+	}, []string{"op", "result"})
+	batchSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "pointproofs",
+		Name:      "batch_size",
+		Help:      "Entries, openings or vectors per operation over many of them.",
+		// 1 to 2n
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"op"})
+	proofCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "pointproofs",
+		Name:      "proof_cache_requests_total",
+		Help:      "Lookups of cached proofs by outcome.",
+	}, []string{"cache", "result"})
+)
+
+// metricsCollectors are the metrics above
+var metricsCollectors = []prometheus.Collector{operationSeconds, operationFailures, verificationResults, batchSize, proofCacheRequests}
+
+// metricsRegistry holds the metrics MetricsHandler serves
+var metricsRegistry = prometheus.NewRegistry()
+
+func init() {
+	metricsRegistry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	metricsRegistry.MustRegister(metricsCollectors...)
+}
+
+// RegisterMetrics registers the metrics of the scheme with reg, e.g. prometheus.DefaultRegisterer
+func RegisterMetrics(reg prometheus.Registerer) error {
+	for _, c := range metricsCollectors {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MetricsHandler serves the metrics in the Prometheus exposition format
+func MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
+
+// observeOperation records an operation that started at start, res being its result if it did not fail
+func observeOperation(op string, start time.Time, failed bool, res interface{}) {
+	if failed {
+		operationFailures.WithLabelValues(op).Inc()
+		return
+	}
+	operationSeconds.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	if valid, ok := res.(bool); ok {
+		result := "invalid"
+		if valid {
+			result = "valid"
+		}
+		verificationResults.WithLabelValues(op, result).Inc()
+	}
+}
+
+// observeBatch records the number of entries an operation covers
+func observeBatch(op string, size int) {
+	batchSize.WithLabelValues(op).Observe(float64(size))
+}
+
+// observeProofCache records a lookup in a proof cache
+func observeProofCache(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	proofCacheRequests.WithLabelValues(cache, result).Inc()
+}
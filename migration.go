@@ -0,0 +1,166 @@
+package main
+
+import (
+	"io"
+	"math/big"
+	"sort"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Migration between parameter sets, e.g. from a local setup to the parameters of a ceremony or to a
+	set for longer vectors. The message is recommitted under the new parameters, padded with zeros when
+	they are longer, and a migration proof links the old commitment C to the new one C': it opens both
+	to the same values at a set of positions S, with one aggregated proof per commitment as in
+	fiatshamir.go, and the new one to zero at the padding positions of S. S is either every position
+	of the new parameters or a sample derived from the challenges "i" of a transcript of domain
+	migrationDomain that absorbed
+		"from": fingerprint of the old parameters, "to": fingerprint of the new ones, "C": C, "C'": C',
+		"|S|": |S|
+	each reduced modulo the size N' of the new parameters, skipping repetitions. A sample of s
+	positions misses a message that differs at d of them with probability about (1 - d/N')^s per
+	commitment the prover tries, so it catches mistakes rather than a prover grinding over messages,
+	against whom S has to be every position.
+	Both parameter sets are held by MigrationParams, the scheme of curvescheme.go on BLS12-381 for any
+	size, so neither has to be the size n compiled in and nothing is installed: the globals, the GT
+	table of target.go and the G2 lines stay those of the installed parameters. Proofs of single
+	positions under the new parameters are regenerated, by ProveAll or ProveSet once they are installed
+	in a build for their size.
+*/
+
+// MigrationParams is a parameter set on BLS12-381 of any size, old or new side of a migration
+type MigrationParams = curveParams[*bls.PointG1, *bls.PointG2]
+
+// MigrationParams returns the parameters as the side of a migration, sharing their points
+func (pp *PublicParams) MigrationParams() *MigrationParams {
+	mp := &MigrationParams{curve: bls12381Curve{}, pp1: make([]*bls.PointG1, 2*n), pp2: pp.PP2[:]}
+	for i := range mp.pp1 {
+		mp.pp1[i] = pp.g1Power(i)
+	}
+	return mp
+}
+
+// ReadMigrationParams parses a parameter file of params.go of any size as the side of a migration
+func ReadMigrationParams(r io.Reader) (*MigrationParams, error) {
+	return readCurveParams[*bls.PointG1, *bls.PointG2](r, bls12381Curve{})
+}
+
+// MigrationProof links a commitment under the old parameters to the one of the same message under the new
+type MigrationProof struct {
+	// Values[k] is the entry at the k-th position of migrationIndices, zero past the old size
+	Values []*big.Int
+	// OldProof opens the old commitment to the Values within the old size, it is nil if there are none.
+	// NewProof opens the new commitment to all Values
+	OldProof *bls.PointG1
+	NewProof *bls.PointG1
+}
+
+// migrationIndices derives the positions a migration opens, in increasing order, every position of the
+// new parameters unless 0 < samples < to.Size()
+func migrationIndices(from, to *MigrationParams, oldCom, newCom *bls.PointG1, samples int) []int {
+	size := to.Size()
+	if samples <= 0 || samples >= size {
+		indices := make([]int, size)
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
+	}
+	fromPrint, toPrint := from.Fingerprint(), to.Fingerprint()
+	t := NewTranscript(migrationDomain)
+	t.Append("from", fromPrint[:])
+	t.Append("to", toPrint[:])
+	t.AppendG1("C", oldCom)
+	t.AppendG1("C'", newCom)
+	t.AppendUint32("|S|", uint32(samples))
+	chosen := make(map[int]bool, samples)
+	indices := make([]int, 0, samples)
+	bound := big.NewInt(int64(size))
+	for len(indices) < samples {
+		i := int(new(big.Int).Mod(t.ChallengeScalar("i"), bound).Int64())
+		if !chosen[i] {
+			chosen[i] = true
+			indices = append(indices, i)
+		}
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// oldIndices returns how many of the increasing indices lie within the old parameters
+func oldIndices(from *MigrationParams, indices []int) int {
+	return sort.SearchInts(indices, from.Size())
+}
+
+/*
+	It takes the following arguments:
+		1. the old parameters
+		2. the new parameters, at least as long as the old ones
+		3. vector message, of the size of the old parameters
+		4. the number of positions to sample, 0 for every position
+	It returns the commitment of the message, padded with zeros to the size of the new parameters,
+	under the new parameters and the migration proof linking it to the commitment under the old ones.
+*/
+func Migrate(from, to *MigrationParams, message []*big.Int, samples int) (*bls.PointG1, *MigrationProof) {
+	if from.Size() > to.Size() {
+		panic("the new parameters are shorter than the old ones")
+	}
+	padded := make([]*big.Int, to.Size())
+	copy(padded, message)
+	for i := len(message); i < len(padded); i++ {
+		padded[i] = new(big.Int)
+	}
+	oldCom := from.Commit(message)
+	newCom := to.Commit(padded)
+	indices := migrationIndices(from, to, oldCom, newCom, samples)
+	proof := &MigrationProof{Values: make([]*big.Int, len(indices))}
+	for k, i := range indices {
+		proof.Values[k] = padded[i]
+	}
+	if old := indices[:oldIndices(from, indices)]; len(old) > 0 {
+		proof.OldProof = from.ProveAggregated(oldCom, message, old)
+	}
+	proof.NewProof = to.ProveAggregated(newCom, padded, indices)
+	return newCom, proof
+}
+
+/*
+	It takes the following arguments:
+		1. the old parameters
+		2. the new parameters
+		3. the commitment under the old parameters
+		4. the commitment under the new parameters
+		5. the number of positions sampled, as given to Migrate
+		6. the migration proof
+	It reports whether the proof shows both commitments hold the same values at the derived positions
+	and the new one zeros at those past the old size.
+*/
+func VerifyMigration(from, to *MigrationParams, oldCom, newCom *bls.PointG1, samples int, proof *MigrationProof) bool {
+	if from.Size() > to.Size() || proof.NewProof == nil {
+		return false
+	}
+	indices := migrationIndices(from, to, oldCom, newCom, samples)
+	if len(proof.Values) != len(indices) {
+		return false
+	}
+	for _, v := range proof.Values {
+		if v == nil || v.Sign() < 0 || v.Cmp(frModulus) != -1 {
+			return false
+		}
+	}
+	old := oldIndices(from, indices)
+	for _, v := range proof.Values[old:] {
+		if v.Sign() != 0 {
+			return false
+		}
+	}
+	if old > 0 {
+		if proof.OldProof == nil || !from.VerifyAggregated(oldCom, indices[:old], proof.Values[:old], proof.OldProof) {
+			return false
+		}
+	} else if proof.OldProof != nil {
+		return false
+	}
+	return to.VerifyAggregated(newCom, indices, proof.Values, proof.NewProof)
+}
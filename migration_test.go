@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// TestMigration moves a message from a short local setup to the installed parameters, sampled and in
+// full, and checks nothing gets installed on the way
+func TestMigration(t *testing.T) {
+	benchSetup(t)
+	installed := srsFingerprint
+	from := newCurveParamsFrom[*bls.PointG1, *bls.PointG2](bls12381Curve{}, 64, big.NewInt(12345))
+	to := (&PublicParams{PP1: pp1, PP2: pp2}).MigrationParams()
+	var file bytes.Buffer
+	if err := from.Write(&file); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadMigrationParams(&file)
+	if err != nil || read.Fingerprint() != from.Fingerprint() {
+		t.Fatalf("parameters of size 64 do not round-trip: %v", err)
+	}
+
+	message := generateBigIntegerArray(from.Size(), frModulus)
+	oldCom := from.Commit(message)
+	for _, samples := range []int{16, 0} {
+		newCom, proof := Migrate(from, to, message, samples)
+		if !VerifyMigration(from, to, oldCom, newCom, samples, proof) {
+			t.Fatalf("migration with %d samples rejected", samples)
+		}
+		wrong := *proof
+		wrong.Values = append([]*big.Int(nil), proof.Values...)
+		wrong.Values[0] = new(big.Int).Add(wrong.Values[0], big.NewInt(1))
+		if VerifyMigration(from, to, oldCom, newCom, samples, &wrong) {
+			t.Fatalf("wrong value with %d samples accepted", samples)
+		}
+		if VerifyMigration(from, to, newCom, oldCom, samples, proof) {
+			t.Fatalf("swapped commitments with %d samples accepted", samples)
+		}
+	}
+	if srsFingerprint != installed {
+		t.Fatal("migration changed the installed parameters")
+	}
+
+	// a new commitment with an entry past the old size opens correctly but is no migration
+	padded := make([]*big.Int, to.Size())
+	copy(padded, message)
+	for i := from.Size(); i < len(padded); i++ {
+		padded[i] = new(big.Int)
+	}
+	padded[len(padded)-1] = big.NewInt(1)
+	newCom := to.Commit(padded)
+	indices := migrationIndices(from, to, oldCom, newCom, 0)
+	proof := &MigrationProof{
+		Values:   padded,
+		OldProof: from.ProveAggregated(oldCom, message, indices[:from.Size()]),
+		NewProof: to.ProveAggregated(newCom, padded, indices),
+	}
+	if !to.VerifyAggregated(newCom, indices, padded, proof.NewProof) {
+		t.Fatal("opening of the padded commitment rejected")
+	}
+	if VerifyMigration(from, to, oldCom, newCom, 0, proof) {
+		t.Fatal("nonzero padding accepted")
+	}
+}
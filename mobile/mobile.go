@@ -0,0 +1,312 @@
+// Package mobile verifies PointProofs openings on mobile light clients, for gomobile bind.
+package mobile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	The verifier-only API of the scheme as an importable package, which gomobile bind needs and the main
+	package cannot be. gomobile needs its bind package in the module, go get golang.org/x/mobile/bind:
+		gomobile bind -target android -o pointproofs.aar PointProofs/mobile
+		gomobile bind -target ios -o PointProofs.xcframework PointProofs/mobile
+	A Verifier loads a verifier key file written by "PointProofs params verifier-key" and checks proofs
+	of single entries and aggregated proofs of one commitment, the checks of verifySingleProof and
+	VerifySameCommitment. Only types gomobile binds cross the API: entries are 32 byte big endian
+	integers below the group order, several of them concatenated, indices 4 byte big endian unsigned
+	integers, also concatenated, and commitments and proofs the artifacts of artifacts.go. Malformed
+	input is an error, never a panic, which would take the application down.
+	The package repeats the parts of the main package verification needs, the file header of curve.go,
+	the artifacts and the transcript of transcript.go on SHA-256, the default, for the keys of builds
+	with n = N on BLS12-381. mobile_test.go in the main package keeps the two in agreement.
+*/
+
+const (
+	// N is the length of the committed vectors
+	N = 1024
+	// EntrySize is the size of an entry
+	EntrySize = 32
+	// IndexSize is the size of an index
+	IndexSize = 4
+	// ArtifactSize is the size of a commitment or proof
+	ArtifactSize = 1 + fingerprintSize + g1Size
+)
+
+const (
+	fingerprintSize = 8
+	g1Size          = 96
+	g2Size          = 192
+
+	verifierKeyMagic  = "PPVK"
+	fileHeaderVersion = 3
+	curveBLS12381     = 1
+
+	artifactCommitment      = 1
+	artifactProof           = 2
+	artifactAggregatedProof = 3
+
+	transcriptPrefix     = "PointProofs-transcript-v1"
+	transcriptHashName   = "SHA-256"
+	sameCommitmentDomain = "same-commitment-aggregation"
+	transcriptAppend     = 1
+	transcriptChallenge  = 2
+)
+
+// Verifier checks proofs under a verifier key, it is safe for concurrent use
+type Verifier struct {
+	mu          sync.Mutex
+	fingerprint [fingerprintSize]byte
+	// g1 is pp1[0] = g1^alpha
+	g1  *bls.PointG1
+	pp2 [N]*bls.PointG2
+	// g1Ops and g2Ops hold temporaries, guarded by mu
+	g1Ops *bls.G1
+	g2Ops *bls.G2
+}
+
+// NewVerifier parses a verifier key file, it checks the points lie on the curve
+func NewVerifier(key []byte) (*Verifier, error) {
+	v := &Verifier{g1Ops: bls.NewG1(), g2Ops: bls.NewG2()}
+	r := bytes.NewReader(key)
+	if err := readFileHeader(r); err != nil {
+		return nil, err
+	}
+	if want := fingerprintSize + g1Size + N*g2Size; r.Len() != want {
+		return nil, fmt.Errorf("expected %d bytes after the header, got %d", want, r.Len())
+	}
+	// the length is checked, the reads below cannot fail
+	buf := make([]byte, g2Size)
+	io.ReadFull(r, v.fingerprint[:])
+	io.ReadFull(r, buf[:g1Size])
+	var err error
+	if v.g1, err = v.g1Ops.FromBytes(buf[:g1Size]); err != nil {
+		return nil, fmt.Errorf("pp1[0]: %w", err)
+	}
+	for i := 0; i < N; i++ {
+		io.ReadFull(r, buf)
+		if v.pp2[i], err = v.g2Ops.FromBytes(buf); err != nil {
+			return nil, fmt.Errorf("pp2[%d]: %w", i, err)
+		}
+	}
+	return v, nil
+}
+
+// readFileHeader checks the header of curve.go for a verifier key of n = N on BLS12-381
+func readFileHeader(r io.Reader) error {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	if string(header[:4]) != verifierKeyMagic {
+		return errors.New("bad magic, not a " + verifierKeyMagic + " file")
+	}
+	version := int(header[4])
+	if version < 1 || version > fileHeaderVersion {
+		return fmt.Errorf("unsupported version %d", version)
+	}
+	// curve and flags, as far as the version has them, then n
+	rest := make([]byte, version-1+4)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	if version >= 2 && rest[0] != curveBLS12381 {
+		return fmt.Errorf("the key is for curve %d, only BLS12-381 is supported", rest[0])
+	}
+	if size := binary.BigEndian.Uint32(rest[version-1:]); size != N {
+		return fmt.Errorf("the key is for n = %d, this package uses n = %d", size, N)
+	}
+	return nil
+}
+
+// Fingerprint returns the fingerprint of the parameters the key belongs to
+func (v *Verifier) Fingerprint() []byte {
+	return append([]byte(nil), v.fingerprint[:]...)
+}
+
+// VerifyProof checks the proof of a single entry, value being m_index of the committed vector
+func (v *Verifier) VerifyProof(commitment []byte, index int, value []byte, proof []byte) (bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	com, err := v.decodeArtifact(artifactCommitment, commitment)
+	if err != nil {
+		return false, fmt.Errorf("commitment: %w", err)
+	}
+	pi, err := v.decodeArtifact(artifactProof, proof)
+	if err != nil {
+		return false, fmt.Errorf("proof: %w", err)
+	}
+	if !(0 <= index && index < N) {
+		return false, fmt.Errorf("index %d out of range", index)
+	}
+	m, err := v.entry(value)
+	if err != nil {
+		return false, err
+	}
+	// e(C, g_2^{alpha^{N-i}}) * e(proof, g_2)^{-1} * e(g_1^{alpha * m_i}, g_2^{alpha^N})^{-1} = 1
+	return v.check(com, v.pp2[N-index-1], pi, m), nil
+}
+
+// VerifyAggregated checks an aggregated proof of the entries at the indices, with the aggregation
+// scalars of AggregateSameCommitment. indices holds the distinct indices and values the entries in the
+// same order, which is otherwise arbitrary
+func (v *Verifier) VerifyAggregated(commitment []byte, indices []byte, values []byte, proof []byte) (bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	com, err := v.decodeArtifact(artifactCommitment, commitment)
+	if err != nil {
+		return false, fmt.Errorf("commitment: %w", err)
+	}
+	pi, err := v.decodeArtifact(artifactAggregatedProof, proof)
+	if err != nil {
+		return false, fmt.Errorf("aggregated proof: %w", err)
+	}
+	if len(indices)%IndexSize != 0 || len(values)%EntrySize != 0 || len(indices)/IndexSize != len(values)/EntrySize {
+		return false, errors.New("indices and values differ in length")
+	}
+	count := len(indices) / IndexSize
+	idx := make([]int, count)
+	ms := make([]*big.Int, count)
+	seen := make(map[int]bool, count)
+	for k := range idx {
+		i := binary.BigEndian.Uint32(indices[k*IndexSize:])
+		if i >= N {
+			return false, fmt.Errorf("index %d out of range", i)
+		}
+		idx[k] = int(i)
+		if seen[idx[k]] {
+			return false, fmt.Errorf("index %d given twice", i)
+		}
+		seen[idx[k]] = true
+		if ms[k], err = v.entry(values[k*EntrySize : (k+1)*EntrySize]); err != nil {
+			return false, err
+		}
+	}
+	scalars := v.scalars(com, idx, ms)
+	// \prod g_2^{alpha^{N+1-i} t_i} and \sum m_i t_i
+	prod, term := v.g2Ops.Zero(), v.g2Ops.New()
+	sum, product := new(big.Int), new(big.Int)
+	q := v.g1Ops.Q()
+	for k, i := range idx {
+		v.g2Ops.MulScalar(term, v.pp2[N-i-1], scalars[k])
+		v.g2Ops.Add(prod, prod, term)
+		sum.Add(sum, product.Mul(ms[k], scalars[k]))
+	}
+	return v.check(com, prod, pi, sum.Mod(sum, q)), nil
+}
+
+// check reports whether e(com, base) * e(proof, g_2)^{-1} = g_T^{alpha^{N+1} * x}
+func (v *Verifier) check(com *bls.PointG1, base *bls.PointG2, proof *bls.PointG1, x *big.Int) bool {
+	e := bls.NewPairingEngine()
+	rhs := v.g1Ops.New()
+	v.g1Ops.MulScalar(rhs, v.g1, x)
+	e.AddPair(com, base)
+	e.AddPairInv(proof, v.g2Ops.One())
+	// g_T^{alpha^{N+1}} = e(g_1^alpha, g_2^{alpha^N})
+	e.AddPairInv(rhs, v.pp2[N-1])
+	return e.Check()
+}
+
+// decodeArtifact parses kind || fingerprint || point and checks the point lies in the subgroup
+func (v *Verifier) decodeArtifact(kind byte, data []byte) (*bls.PointG1, error) {
+	if len(data) != ArtifactSize {
+		return nil, fmt.Errorf("expected %d bytes, got %d", ArtifactSize, len(data))
+	}
+	if data[0] != kind {
+		return nil, fmt.Errorf("expected artifact of kind %d, got %d", kind, data[0])
+	}
+	if !bytes.Equal(data[1:1+fingerprintSize], v.fingerprint[:]) {
+		return nil, fmt.Errorf("artifact was produced under parameters %x, the key is for %x", data[1:1+fingerprintSize], v.fingerprint)
+	}
+	p, err := v.g1Ops.FromBytes(data[1+fingerprintSize:])
+	if err != nil {
+		return nil, err
+	}
+	if !v.g1Ops.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in the correct subgroup")
+	}
+	return p, nil
+}
+
+// entry parses an entry
+func (v *Verifier) entry(b []byte) (*big.Int, error) {
+	if len(b) != EntrySize {
+		return nil, fmt.Errorf("entries have %d bytes, got %d", EntrySize, len(b))
+	}
+	m := new(big.Int).SetBytes(b)
+	if m.Cmp(v.g1Ops.Q()) >= 0 {
+		return nil, errors.New("entry does not lie in the field")
+	}
+	return m, nil
+}
+
+// scalars derives the aggregation scalars t_i as sameCommitmentScalars of fiatshamir.go does, res[k]
+// belonging to indices[k]
+func (v *Verifier) scalars(com *bls.PointG1, indices []int, values []*big.Int) []*big.Int {
+	order := make([]int, len(indices))
+	for k := range order {
+		order[k] = k
+	}
+	sort.Slice(order, func(a, b int) bool { return indices[order[a]] < indices[order[b]] })
+	t := newTranscript(sameCommitmentDomain)
+	t.append("C", v.g1Ops.ToBytes(com))
+	t.appendUint32("|S|", uint32(len(indices)))
+	for _, k := range order {
+		t.appendUint32("i", uint32(indices[k]))
+		t.append("m_i", values[k].FillBytes(make([]byte, EntrySize)))
+	}
+	res := make([]*big.Int, len(indices))
+	q := v.g1Ops.Q()
+	for _, k := range order {
+		res[k] = t.challenge("t", q)
+	}
+	return res
+}
+
+// transcript is the Transcript of transcript.go on SHA-256
+type transcript struct {
+	state []byte
+}
+
+func newTranscript(domain string) *transcript {
+	t := &transcript{state: []byte(transcriptPrefix)}
+	t.writeBytes([]byte(transcriptHashName))
+	t.writeBytes([]byte(domain))
+	return t
+}
+
+// writeBytes absorbs len(b) || b
+func (t *transcript) writeBytes(b []byte) {
+	t.state = binary.BigEndian.AppendUint32(t.state, uint32(len(b)))
+	t.state = append(t.state, b...)
+}
+
+func (t *transcript) append(label string, data []byte) {
+	t.state = append(t.state, transcriptAppend)
+	t.writeBytes([]byte(label))
+	t.writeBytes(data)
+}
+
+func (t *transcript) appendUint32(label string, x uint32) {
+	t.append(label, binary.BigEndian.AppendUint32(nil, x))
+}
+
+// challenge returns H(d || 0x00) || H(d || 0x01) modulo q for the digest d of the state
+func (t *transcript) challenge(label string, q *big.Int) *big.Int {
+	t.state = append(t.state, transcriptChallenge)
+	t.writeBytes([]byte(label))
+	d := sha256.Sum256(t.state)
+	h0 := sha256.Sum256(append(d[:], 0))
+	h1 := sha256.Sum256(append(d[:], 1))
+	wide := append(h0[:], h1[:]...)
+	return new(big.Int).Mod(new(big.Int).SetBytes(wide), q)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"PointProofs/mobile"
+)
+
+// TestMobileVerifier checks the verifier of the mobile package against the proofs and aggregations of
+// the scheme, the package repeats the parts of it verification needs
+func TestMobileVerifier(t *testing.T) {
+	f := benchSetup(t)
+	if mobile.N != n || mobile.EntrySize != scalarSize || mobile.ArtifactSize != 1+fingerprintSize+g1Size {
+		t.Fatal("the constants of the mobile package differ")
+	}
+	vk := &VerifierKey{Fingerprint: srsFingerprint, G1: pp1Point(0), PP2: pp2}
+	var key bytes.Buffer
+	if err := vk.write(&key); err != nil {
+		t.Fatal(err)
+	}
+	v, err := mobile.NewVerifier(key.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(v.Fingerprint(), srsFingerprint[:]) {
+		t.Fatal("fingerprint differs")
+	}
+	com := encodeCommitment(f.com)
+	entry := func(v *big.Int) []byte { return v.FillBytes(make([]byte, scalarSize)) }
+	for k, i := range f.indices {
+		proof := encodeProof(f.proofs[k])
+		if ok, err := v.VerifyProof(com, i, entry(f.values[k]), proof); err != nil || !ok {
+			t.Fatalf("proof of index %d: %v, %v", i, ok, err)
+		}
+		wrong := new(big.Int).Add(f.values[k], big.NewInt(1))
+		if ok, err := v.VerifyProof(com, i, entry(wrong), proof); err != nil || ok {
+			t.Fatalf("proof of index %d for a wrong value: %v, %v", i, ok, err)
+		}
+	}
+
+	openings := make([]Opening, len(f.indices))
+	for k, i := range f.indices {
+		openings[k] = Opening{Index: i, Value: f.values[k], Proof: f.proofs[k]}
+	}
+	aggregated := encodeAggregatedProof(AggregateSameCommitment(f.com, openings))
+	// the claims in reverse order, the scalars do not depend on it
+	var indices, values []byte
+	for k := len(f.indices) - 1; k >= 0; k-- {
+		indices = binary.BigEndian.AppendUint32(indices, uint32(f.indices[k]))
+		values = append(values, entry(f.values[k])...)
+	}
+	if ok, err := v.VerifyAggregated(com, indices, values, aggregated); err != nil || !ok {
+		t.Fatalf("aggregated proof: %v, %v", ok, err)
+	}
+	wrong := append([]byte(nil), values...)
+	wrong[len(wrong)-1] ^= 1
+	if ok, err := v.VerifyAggregated(com, indices, wrong, aggregated); err != nil || ok {
+		t.Fatalf("aggregated proof for a wrong value: %v, %v", ok, err)
+	}
+
+	// malformed input is an error
+	if _, err := v.VerifyAggregated(com, indices[:4], values, aggregated); err == nil {
+		t.Fatal("indices and values of different lengths accepted")
+	}
+	if _, err := v.VerifyProof(aggregated, f.indices[0], entry(f.values[0]), encodeProof(f.proofs[0])); err == nil {
+		t.Fatal("aggregated proof accepted as a commitment")
+	}
+	other := append([]byte(nil), com...)
+	other[1] ^= 1
+	if _, err := v.VerifyProof(other, f.indices[0], entry(f.values[0]), encodeProof(f.proofs[0])); err == nil {
+		t.Fatal("commitment of other parameters accepted")
+	}
+	if _, err := v.VerifyProof(com, n, entry(f.values[0]), encodeProof(f.proofs[0])); err == nil {
+		t.Fatal("out of range index accepted")
+	}
+	if _, err := mobile.NewVerifier(key.Bytes()[:key.Len()-1]); err == nil {
+		t.Fatal("truncated key accepted")
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Multi-scalar multiplication \sum scalars[i] * points[i] with Pippenger's bucket method. The scalars
+	are cut into windows of c bits, recoded to signed digits in [-2^{c-1}, 2^{c-1}] so only 2^{c-1}
+	buckets are needed per window. In every window each point is added to (or subtracted from) the bucket
+	of its digit, and the buckets are summed up as \sum_d d * bucket_d with two running sums. The windows
+	are then combined by c doublings each, from the most significant one down. For m points this costs
+	about (255 / c) * (m + 2^c) additions instead of the 255 doublings and 128 additions per point of
+	MulScalar.
+*/
+
+// msmWindow returns the window size in bits for a multi-scalar multiplication of the given size
+func msmWindow(size int) int {
+	if size < 32 {
+		return 3
+	}
+	// roughly log2(size) - 3 balances the m additions against the 2^c bucket additions per window
+	return bits.Len(uint(size)) - 3
+}
+
+// signedDigits recodes s < 2^{c * len(digits) - 1} into signed c bit digits, least significant first
+func signedDigits(s *big.Int, c int, digits []int) {
+	words := s.Bits()
+	carry := 0
+	for w := range digits {
+		d := scalarWindow(words, w*c, c) + carry
+		carry = 0
+		if d > 1<<(c-1) {
+			d -= 1 << c
+			carry = 1
+		}
+		digits[w] = d
+	}
+}
+
+// scalarWindow returns the c bits of the number given by its little endian words starting at bit start
+func scalarWindow(words []big.Word, start, c int) int {
+	idx, shift := start/bits.UintSize, start%bits.UintSize
+	if idx >= len(words) {
+		return 0
+	}
+	v := uint(words[idx]) >> shift
+	if shift+c > bits.UintSize && idx+1 < len(words) {
+		v |= uint(words[idx+1]) << (bits.UintSize - shift)
+	}
+	return int(v & (1<<c - 1))
+}
+
+// msmG1 returns \sum scalars[i] * points[i], the scalars have to be reduced modulo the group order
+func msmG1(g *bls.G1, points []*bls.PointG1, scalars []*big.Int) *bls.PointG1 {
+	if len(points) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	c := msmWindow(len(points))
+	return msmG1Digits(g, nil, points, recodeScalars(scalars, c), c)
+}
+
+// recodeScalars returns the signed c bit digits of every scalar the way msmG1Digits expects them
+func recodeScalars(scalars []*big.Int, c int) [][]int {
+	return new(scratch).recode(scalars, c)
+}
+
+// msmG1Digits is msmG1 for scalars already recoded by recodeScalars, so several sums over the same
+// scalars share the recoding. The buckets come from s, or are allocated if it is nil
+func msmG1Digits(g *bls.G1, s *scratch, points []*bls.PointG1, digits [][]int, c int) *bls.PointG1 {
+	if len(digits) == 0 {
+		return g.Zero()
+	}
+	windows := len(digits[0])
+	if s == nil {
+		s = new(scratch)
+	}
+	buckets := s.bucketsFor(c)
+	res := g.Zero()
+	running, sum := g.New(), g.New()
+	for w := windows - 1; w >= 0; w-- {
+		for k := 0; k < c; k++ {
+			g.Double(res, res)
+		}
+		for j := range buckets {
+			buckets[j].Zero()
+		}
+		for i, p := range points {
+			d := digits[i][w]
+			switch {
+			case d > 0:
+				g.Add(buckets[d-1], buckets[d-1], p)
+			case d < 0:
+				g.Sub(buckets[-d-1], buckets[-d-1], p)
+			}
+		}
+		// sum = \sum_d d * buckets[d - 1]
+		running.Zero()
+		sum.Zero()
+		for j := len(buckets) - 1; j >= 0; j-- {
+			g.Add(running, running, buckets[j])
+			g.Add(sum, sum, running)
+		}
+		g.Add(res, res, sum)
+	}
+	return res
+}
+
+/*
+	It takes the following arguments:
+		1. the points
+		2. the scalars, reduced modulo the group order
+		3. the number of goroutines to split the work across
+	It returns msmG1 of the input. Every goroutine sums up a contiguous chunk with its own G1, which holds
+	temporaries and cannot be shared, and the partial sums are added at the end.
+*/
+func parallelMSMG1(points []*bls.PointG1, scalars []*big.Int, workers int) *bls.PointG1 {
+	if len(points) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	if workers > len(points) {
+		workers = len(points)
+	}
+	if workers <= 1 {
+		g := getG1()
+		defer putG1(g)
+		return msmG1(g, points, scalars)
+	}
+	chunk := (len(points) + workers - 1) / workers
+	partial := make([]*bls.PointG1, 0, workers)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for lo := 0; lo < len(points); lo += chunk {
+		hi := lo + chunk
+		if hi > len(points) {
+			hi = len(points)
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			g := getG1()
+			sum := msmG1(g, points[lo:hi], scalars[lo:hi])
+			putG1(g)
+			mu.Lock()
+			partial = append(partial, sum)
+			mu.Unlock()
+		}(lo, hi)
+	}
+	wg.Wait()
+	g := getG1()
+	defer putG1(g)
+	res := g.Zero()
+	for _, p := range partial {
+		g.Add(res, res, p)
+	}
+	return res
+}
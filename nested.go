@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Nested aggregation. Every shard aggregates its openings across its commitments into one proof pi_b
+	with AggregateCrossCommitment, and the proofs of the shards are aggregated once more,
+		pi = \sum_b rho_b pi_b
+	with rho_b the challenges "rho" of a transcript of domain nestedDomain that absorbed "|B|": the number
+	of shards, then for every shard "m": its number of commitments and every commitment with its openings
+	as in fiatshamir.go. Both the shards and their commitments are absorbed in canonical order, a shard
+	comes before another if the concatenated keys of its commitments in canonical order are smaller, so
+	the claims can be handed over in any order. The verifier derives the rho_b itself and checks the product of the equations of
+	the shards raised to them, the way verifyCrossBatch does with random ones, so a top-level proof is
+	one G1 point for any number of shards and the shards need not be checked one by one.
+*/
+
+// CrossClaim is what a cross-commitment aggregation proves: Commitments[j] opens to Values[j] at Indices[j]
+type CrossClaim struct {
+	Commitments []*bls.PointG1
+	Indices     [][]int
+	Values      [][]*big.Int
+}
+
+// nestedScalars derives rho_b of the claims of the shards
+func nestedScalars(claims []CrossClaim) []fr {
+	g := getG1()
+	defer putG1(g)
+	orders := make([][]int, len(claims))
+	keys := make([][]byte, len(claims))
+	for b, c := range claims {
+		if !(len(c.Indices) == len(c.Commitments) && len(c.Values) == len(c.Commitments)) {
+			panic("arrays with incorrect length")
+		}
+		for j := range c.Commitments {
+			checkOpened(c.Indices[j], c.Values[j])
+		}
+		orders[b] = commitmentOrder(c.Commitments, c.Indices, c.Values)
+		for _, j := range orders[b] {
+			keys[b] = append(keys[b], openedKey(g, c.Commitments[j], c.Indices[j], c.Values[j])...)
+		}
+	}
+	order := keyOrder(keys)
+	t := NewTranscript(nestedDomain)
+	t.AppendUint32("|B|", uint32(len(claims)))
+	for _, b := range order {
+		c := claims[b]
+		t.AppendUint32("m", uint32(len(c.Commitments)))
+		for _, j := range orders[b] {
+			appendOpened(t, c.Commitments[j], c.Indices[j], c.Values[j])
+		}
+	}
+	return frVector(challengesInOrder(t, "rho", order))
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. the claims of the shards
+		3. the proofs of AggregateCrossCommitment for them
+	It returns the top-level proof of all claims, which VerifyNested checks.
+*/
+func AggregateNested(claims []CrossClaim, proofs []*bls.PointG1) *bls.PointG1 {
+	if len(claims) != len(proofs) {
+		panic("arrays with incorrect length")
+	}
+	if len(claims) == 0 {
+		return bls.NewG1().Zero()
+	}
+	return backend.MultiExpG1(proofs, nestedScalars(claims))
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the claims of the shards, in any order
+		4. the proof of AggregateNested
+	It reports whether the proof shows every claim. An empty set of claims is valid.
+*/
+func VerifyNested(claims []CrossClaim, proof *bls.PointG1) bool {
+	return profiled("verify_nested", func() bool {
+		if len(claims) == 0 {
+			return true
+		}
+		rho := nestedScalars(claims)
+		transcripts := make([]*crossTranscript, len(claims))
+		for b, c := range claims {
+			transcripts[b] = newCrossTranscript(c.Commitments, c.Indices, c.Values, nil)
+		}
+		return verifyCrossCombination(transcripts, rho, proof)
+	})
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	MSM offloading. Commitments and proofs are large multi-scalar multiplications over slices of pp1,
+	exactly the workload GPU libraries accelerate. SetMSM hands them to any MSM, without touching the rest
+	of the scheme. Such libraries are usually reached through cgo with flat buffers, which RawMSM adapts
+	to: it encodes the points and scalars as plain byte arrays and decodes the result, so an adapter for a
+	GPU library is a single function, e.g. for a cgo binding
+		SetMSM(RawMSM{Name: "gpu", Func: func(points, scalars []byte, count int) ([]byte, error) {
+			out := make([]byte, g1Size)
+			if C.gpu_msm_g1((*C.uint8_t)(&out[0]), (*C.uint8_t)(&points[0]), (*C.uint8_t)(&scalars[0]), C.size_t(count)) != 0 {
+				return nil, errors.New("gpu msm failed")
+			}
+			return out, nil
+		}})
+	cpuMSM in offload_test.go is such a function running on the CPU, a working example of the buffers.
+*/
+
+// MSM computes the multi-scalar multiplications of commit and the provers
+type MSM interface {
+	// MultiExpG1 returns \sum scalars[i] * points[i]
+	MultiExpG1(points []*bls.PointG1, scalars []fr) *bls.PointG1
+}
+
+// msmOffload is the MSM set by SetMSM, nil leaves the work to the backend
+var msmOffload MSM
+
+// SetMSM makes commit, CommitStream and ProveSet use m, nil switches back to the backend of backend.go
+func SetMSM(m MSM) {
+	msmOffload = m
+}
+
+// currentMSM returns the MSM to use, the backend unless one was set
+func currentMSM() MSM {
+	if msmOffload != nil {
+		return msmOffload
+	}
+	return backend
+}
+
+/*
+	RawMSM adapts a function on flat buffers to MSM. Func receives
+		1. count points as uncompressed affine x || y, big endian, g1Size bytes each (the point at infinity
+		   is all zeros), the encoding of writeG1
+		2. count scalars in [0, q) as 32 byte little endian integers
+		3. count
+	and returns the sum in the encoding of the points. A failing Func or a malformed result panics, as
+	there is no way to carry on without the commitment or proof.
+*/
+type RawMSM struct {
+	Name string
+	Func func(points, scalars []byte, count int) ([]byte, error)
+}
+
+func (m RawMSM) MultiExpG1(points []*bls.PointG1, scalars []fr) *bls.PointG1 {
+	if len(points) != len(scalars) {
+		panic("arrays with incorrect length")
+	}
+	g := getG1()
+	defer putG1(g)
+	p := make([]byte, 0, len(points)*g1Size)
+	for _, point := range points {
+		p = append(p, g.ToBytes(point)...)
+	}
+	s := make([]byte, len(scalars)*scalarSize)
+	for i, x := range scalars {
+		limbs := x.plain()
+		for j := 0; j < scalarSize; j++ {
+			s[i*scalarSize+j] = byte(limbs[j/8] >> (8 * (j % 8)))
+		}
+	}
+	out, err := m.Func(p, s, len(points))
+	if err == nil && len(out) != g1Size {
+		err = errors.New("result of the wrong size")
+	}
+	if err != nil {
+		panic(fmt.Sprintf("msm %s: %v", m.Name, err))
+	}
+	res, err := g.FromBytes(out)
+	if err != nil {
+		panic(fmt.Sprintf("msm %s: %v", m.Name, err))
+	}
+	return res
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Parameter files have the following layout:
+		1. the file header of curve.go with magic "PPSR"
+		2. pp1[0], ..., pp1[2n - 1] as uncompressed G1 points (pp1[n] is the point at infinity)
+		3. pp2[0], ..., pp2[n - 1] as uncompressed G2 points
+		4. only if the header has paramsFlagFullG2 set, PP2Ext[0], ..., PP2Ext[n - 1] as uncompressed G2 points
+	The header flag paramsFlagSwapped marks parameters of the swapped variant, see swapped.go.
+*/
+const (
+	paramsMagic = "PPSR"
+	// header flag announcing the optional PP2Ext section
+	paramsFlagFullG2 = 1
+	// header flag selecting the swapped variant, commitments in G2
+	paramsFlagSwapped = 2
+	// size of a parameter file for the compiled in n without the optional section
+	paramsFileSize = fileHeaderSize + 2*n*g1Size + n*g2Size
+	// size of a parameter file with every optional section
+	paramsMaxFileSize = paramsFileSize + n*g2Size
+)
+
+// PublicParams holds the public parameters, whether they come from setup, the DKG or a file
+type PublicParams struct {
+	// PP1[i-1] = {g1 ^ {alpha ^ i}} for 1 <= i <= 2n except for N + 1, PP1[n] = 0
+	PP1 [2 * n]*bls.PointG1
+	// PP2[i-1] = {g2 ^ {alpha ^ i}} for 1 <= i <= n
+	PP2 [n]*bls.PointG2
+	// PP2Ext[i-n-1] = {g2 ^ {alpha ^ i}} for n + 2 <= i <= 2n and PP2Ext[0] = 0, mirroring the hole in PP1.
+	// It is nil unless requested at setup (setupOptions.fullG2), so the default footprint stays the same
+	PP2Ext []*bls.PointG2
+	// Swapped selects the variant of swapped.go. It only changes which functions accept the parameters,
+	// but being part of the fingerprint it keeps artifacts of the two variants apart
+	Swapped bool
+	// lazyPP1 holds the encoded G1 powers of parameters read by readLazyPublicParams, whose PP1 is empty
+	lazyPP1 *lazyG1
+}
+
+// pp2Ext is PublicParams.PP2Ext of the installed parameters, nil if they do not have it
+var pp2Ext []*bls.PointG2
+
+// size of the parameter fingerprint embedded in every serialized artifact
+const fingerprintSize = 8
+
+// srsFingerprint identifies the installed parameters, it is set by install
+var srsFingerprint [fingerprintSize]byte
+
+// fingerprint returns a short hash of the parameters, two parameter sets share it only by accident
+func (pp *PublicParams) fingerprint() [fingerprintSize]byte {
+	h := sha256.New()
+	h.Write([]byte("PointProofs-SRS-fingerprint"))
+	// writing into a hash cannot fail
+	_ = pp.write(h)
+	var res [fingerprintSize]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
+// install makes the parameters the ones used implicitly by commit, the provers and the verifiers
+func (pp *PublicParams) install() {
+	pp.installAs(pp.fingerprint())
+}
+
+// installAs is install with the given fingerprint, for parameters that are only the part of a set
+// some process needs, see VerifierKey
+func (pp *PublicParams) installAs(fingerprint [fingerprintSize]byte) {
+	if engine == nil {
+		engine = bls.NewPairingEngine()
+	}
+	pp1 = pp.PP1
+	pp2 = pp.PP2
+	pp2Ext = pp.PP2Ext
+	swappedGroups = pp.Swapped
+	pp1Lazy = pp.lazyPP1
+	// the tables belong to the previous parameters
+	pp1Tables = nil
+	pp1SpectrumMu.Lock()
+	pp1Spectrum = nil
+	pp1SpectrumMu.Unlock()
+	commitBasesMu.Lock()
+	pp1CommitBases = nil
+	commitBasesMu.Unlock()
+	gtTarget = pp.target()
+	precomputeVerifierLines()
+	srsFingerprint = fingerprint
+}
+
+// write serializes the parameters in the file format described above
+func (pp *PublicParams) write(w io.Writer) error {
+	if pp.PP2Ext != nil && len(pp.PP2Ext) != n {
+		return errors.New("PP2Ext must hold n points")
+	}
+	var flags byte
+	if pp.PP2Ext != nil {
+		flags |= paramsFlagFullG2
+	}
+	if pp.Swapped {
+		flags |= paramsFlagSwapped
+	}
+	bw := bufio.NewWriter(w)
+	if err := writeFileHeader(bw, paramsMagic, flags); err != nil {
+		return err
+	}
+	if pp.lazyPP1 != nil {
+		// the encoding read is the one writeG1 produces, FromBytes rejects everything else
+		if _, err := bw.Write(pp.lazyPP1.raw); err != nil {
+			return err
+		}
+	}
+	for i := 0; pp.lazyPP1 == nil && i < 2*n; i++ {
+		if err := writeG1(bw, pp.PP1[i]); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < n; i++ {
+		if err := writeG2(bw, pp.PP2[i]); err != nil {
+			return err
+		}
+	}
+	for _, p := range pp.PP2Ext {
+		if err := writeG2(bw, p); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readPublicParams parses parameters written by write, it checks the points lie on the curve but
+// neither their subgroups nor that they are well-formed powers (see checkSubgroups and checkPowers)
+func readPublicParams(r io.Reader) (*PublicParams, error) {
+	return readParams(r, false)
+}
+
+// readLazyPublicParams is readPublicParams for parameters whose G1 powers are decoded on first use,
+// see lazy.go. The curve checks of those points happen then
+func readLazyPublicParams(r io.Reader) (*PublicParams, error) {
+	return readParams(r, true)
+}
+
+func readParams(r io.Reader, lazy bool) (*PublicParams, error) {
+	br := bufio.NewReader(r)
+	flags, err := readFileHeader(br, paramsMagic)
+	if err != nil {
+		return nil, err
+	}
+	pp := &PublicParams{Swapped: flags&paramsFlagSwapped != 0}
+	if lazy {
+		pp.lazyPP1 = &lazyG1{raw: make([]byte, 2*n*g1Size)}
+		if _, err := io.ReadFull(br, pp.lazyPP1.raw); err != nil {
+			return nil, fmt.Errorf("pp1: %w", err)
+		}
+	}
+	for i := 0; !lazy && i < 2*n; i++ {
+		if pp.PP1[i], err = readG1(br); err != nil {
+			return nil, fmt.Errorf("pp1[%d]: %w", i, err)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if pp.PP2[i], err = readG2(br); err != nil {
+			return nil, fmt.Errorf("pp2[%d]: %w", i, err)
+		}
+	}
+	if flags&paramsFlagFullG2 != 0 {
+		pp.PP2Ext = make([]*bls.PointG2, n)
+		for i := 0; i < n; i++ {
+			if pp.PP2Ext[i], err = readG2(br); err != nil {
+				return nil, fmt.Errorf("pp2ext[%d]: %w", i, err)
+			}
+		}
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, errors.New("trailing bytes after parameters")
+	}
+	return pp, nil
+}
+
+// marshal returns the parameters in the file format
+func (pp *PublicParams) marshal() []byte {
+	var buf bytes.Buffer
+	buf.Grow(paramsFileSize)
+	// writing into a bytes.Buffer cannot fail
+	_ = pp.write(&buf)
+	return buf.Bytes()
+}
+
+/*
+	It runs the same setup as setup(), but every power is written to w in the parameter file format
+	as soon as it is computed instead of being kept around, so memory use does not depend on n.
+	It takes the following arguments:
+		1. the writer receiving the parameter file
+		2. the setup options, for progress reports and the optional full G2 powers
+	Unlike setup it does not return alpha, which is discarded once the last power is written.
+*/
+func setupStream(w io.Writer, opts setupOptions) error {
+	g1, g2 := bls.NewG1(), bls.NewG2()
+	q := g1.Q()
+	alpha := big.NewInt(0)
+	for alpha.Sign() == 0 {
+		alpha = generateBigIntegerArray(1, q)[0]
+	}
+	// alpha is useless to anyone once we are done, even if we return early
+	defer alpha.SetInt64(0)
+	work := 3 * n
+	var flags byte
+	if opts.fullG2 {
+		work += n
+		flags |= paramsFlagFullG2
+	}
+	if opts.swapped {
+		flags |= paramsFlagSwapped
+	}
+	tracker := newProgressTracker(opts.progress, work)
+	bw := bufio.NewWriter(w)
+	if err := writeFileHeader(bw, paramsMagic, flags); err != nil {
+		return err
+	}
+	a := frFromBig(alpha)
+	// power runs through alpha^i, only the current point is ever held in memory
+	power := frOne
+	defer func() { a, power = fr{}, fr{} }()
+	c := g1.New()
+	for i := 1; i < 2*n+1; i++ {
+		power.mul(power, a)
+		if i == n+1 {
+			c.Zero()
+		} else {
+			g1.MulScalar(c, g1.One(), power.big())
+		}
+		if err := writeG1(bw, c); err != nil {
+			return err
+		}
+		tracker.add(1)
+	}
+	power = frOne
+	c2 := g2.New()
+	for i := 1; i < n+1; i++ {
+		power.mul(power, a)
+		g2.MulScalar(c2, g2.One(), power.big())
+		if err := writeG2(bw, c2); err != nil {
+			return err
+		}
+		tracker.add(1)
+	}
+	if opts.fullG2 {
+		for i := n + 1; i < 2*n+1; i++ {
+			power.mul(power, a)
+			if i == n+1 {
+				c2.Zero()
+			} else {
+				g2.MulScalar(c2, g2.One(), power.big())
+			}
+			if err := writeG2(bw, c2); err != nil {
+				return err
+			}
+			tracker.add(1)
+		}
+	}
+	return bw.Flush()
+}
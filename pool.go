@@ -0,0 +1,31 @@
+package main
+
+import (
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// G1 and G2 instances carry scratch field elements and cannot be shared between goroutines, but they
+// are costly to allocate per point. Code running outside the global engine borrows them from these pools,
+// and so does every operation that may run at the same time as another one, e.g. in the handlers of
+// server.go or the workers of jobs.go. The global engine is left to setup and the command line.
+var (
+	g1Pool = sync.Pool{New: func() interface{} { return bls.NewG1() }}
+	g2Pool = sync.Pool{New: func() interface{} { return bls.NewG2() }}
+	// enginePool holds pairing engines, which keep the pairs added to them until the check
+	enginePool = sync.Pool{New: func() interface{} { return bls.NewPairingEngine() }}
+)
+
+func getG1() *bls.G1 { return g1Pool.Get().(*bls.G1) }
+
+func putG1(g *bls.G1) { g1Pool.Put(g) }
+
+func getG2() *bls.G2 { return g2Pool.Get().(*bls.G2) }
+
+func putG2(g *bls.G2) { g2Pool.Put(g) }
+
+func getEngine() *bls.Engine { return enginePool.Get().(*bls.Engine) }
+
+// putEngine drops the pairs of a check that did not run, e.g. because a backend other than go-ethereum's did
+func putEngine(e *bls.Engine) { enginePool.Put(e.Reset()) }
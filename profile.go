@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+	Profiling. Every heavy operation (commit, the provers and the verifiers) runs under the pprof label
+	pointproofs=<operation>, which goroutines started inside inherit, so CPU profiles of a service can be
+	broken down by operation, e.g. go tool pprof -tagfocus pointproofs=commit. The labels replace those of
+	the calling goroutine while the operation runs, the caller's are back once it returns.
+	A ProfileHook additionally runs around every such operation, FileProfileHook is one that writes a CPU
+	and a heap profile per call. The operations also feed the metrics of metrics.go.
+*/
+
+// ProfileHook is called when an operation starts and the function it returns when it ends
+type ProfileHook func(op string) (stop func())
+
+var (
+	profileMu   sync.Mutex
+	profileHook ProfileHook
+)
+
+// SetProfileHook installs h around every heavy operation, nil removes it
+func SetProfileHook(h ProfileHook) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	profileHook = h
+}
+
+// profiled runs f under the label of op and the profile hook, and records it in the metrics
+func profiled[T any](op string, f func() T) T {
+	start := time.Now()
+	var res T
+	failed := true
+	defer func() {
+		observeOperation(op, start, failed, res)
+	}()
+	profileMu.Lock()
+	h := profileHook
+	profileMu.Unlock()
+	if h != nil {
+		if stop := h(op); stop != nil {
+			defer stop()
+		}
+	}
+	pprof.Do(context.Background(), pprof.Labels("pointproofs", op), func(context.Context) {
+		res = f()
+	})
+	failed = false
+	return res
+}
+
+/*
+	FileProfileHook returns a ProfileHook writing <dir>/<op>-<k>.cpu.pprof and <dir>/<op>-<k>.heap.pprof
+	for the k-th operation it sees. Go runs a single CPU profile at a time, so operations overlapping one
+	being profiled only get their heap profile. Errors go to stderr, profiling must not break the scheme.
+*/
+func FileProfileHook(dir string) ProfileHook {
+	var count int64
+	return func(op string) func() {
+		base := filepath.Join(dir, fmt.Sprintf("%s-%d", op, atomic.AddInt64(&count, 1)))
+		cpu, err := os.Create(base + ".cpu.pprof")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		} else if err := pprof.StartCPUProfile(cpu); err != nil {
+			cpu.Close()
+			os.Remove(cpu.Name())
+			cpu = nil
+		}
+		return func() {
+			if cpu != nil {
+				pprof.StopCPUProfile()
+				cpu.Close()
+			}
+			heap, err := os.Create(base + ".heap.pprof")
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return
+			}
+			defer heap.Close()
+			if err := pprof.WriteHeapProfile(heap); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}
+	}
+}
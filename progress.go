@@ -0,0 +1,51 @@
+package main
+
+import (
+	"time"
+)
+
+// progressFunc receives progress reports of long running operations, percent is in [0, 100]
+// and eta is the estimated time left based on the throughput so far
+type progressFunc func(percent float64, eta time.Duration)
+
+// progressTracker counts units of work and reports every whole percent to a progressFunc
+type progressTracker struct {
+	report progressFunc
+	total  int
+	done   int
+	start  time.Time
+	// last whole percent that has been reported
+	reported int
+}
+
+// newProgressTracker returns a tracker for total units of work, report may be nil
+func newProgressTracker(report progressFunc, total int) *progressTracker {
+	t := &progressTracker{report: report, total: total, start: time.Now(), reported: -1}
+	t.add(0)
+	return t
+}
+
+// add marks k more units as done, it calls the report function at most once per whole percent
+func (t *progressTracker) add(k int) {
+	if t.report == nil {
+		return
+	}
+	t.done += k
+	if t.done > t.total {
+		t.done = t.total
+	}
+	percent := 100.0
+	if t.total > 0 {
+		percent = 100 * float64(t.done) / float64(t.total)
+	}
+	if int(percent) == t.reported {
+		return
+	}
+	t.reported = int(percent)
+	var eta time.Duration
+	if t.done > 0 {
+		elapsed := time.Since(t.start)
+		eta = time.Duration(float64(elapsed) * float64(t.total-t.done) / float64(t.done))
+	}
+	t.report(percent, eta)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	An all-proofs table: the message, its commitment and the proofs of all n indices, kept up to date
+	as entries change. A change of m_j by delta moves every proof pi_i, i != j, by delta * pp1[n - i + j],
+	n scalar multiplications in all instead of running ProveAll again. For a batch of k changes the
+	moves add up to
+		pi_i += \sum_j delta_j * pp1[n - i + j]
+	which is the proof of index i for the message of the deltas. Past proofTableFFTThreshold changed
+	entries that is cheaper to get from a single ProveAll over the deltas than from k * n scalar
+	multiplications.
+*/
+
+// proofTableFFTThreshold is the number of changed entries from which Apply runs ProveAll over the
+// deltas, a change costs about n scalar multiplications and ProveAll about as much as 14 changes
+const proofTableFFTThreshold = 14
+
+// ProofTable holds a message together with its commitment and all n proofs
+type ProofTable struct {
+	message []*big.Int
+	com     *bls.PointG1
+	proofs  []*bls.PointG1
+}
+
+// NewProofTable commits to a copy of message and generates all its proofs
+func NewProofTable(message []*big.Int) *ProofTable {
+	checkVector(message)
+	t := &ProofTable{message: make([]*big.Int, n)}
+	for i := range message {
+		t.message[i] = new(big.Int).Set(message[i])
+	}
+	t.com = commit(t.message)
+	t.proofs = ProveAll(t.message)
+	return t
+}
+
+// Entry returns a copy of m_index
+func (t *ProofTable) Entry(index int) *big.Int {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	return new(big.Int).Set(t.message[index])
+}
+
+// Commitment returns a copy of the commitment to the current message
+func (t *ProofTable) Commitment() *bls.PointG1 {
+	return new(bls.PointG1).Set(t.com)
+}
+
+// Proof returns a copy of the proof of m_index for the current message
+func (t *ProofTable) Proof(index int) *bls.PointG1 {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	return new(bls.PointG1).Set(t.proofs[index])
+}
+
+// Apply changes the message by the updates and brings the commitment and all proofs up to date
+func (t *ProofTable) Apply(updates []Update) {
+	// the deltas per index, an index may appear more than once
+	deltas := make(map[int]fr)
+	for _, u := range updates {
+		if !(0 <= u.Index && u.Index < n) {
+			panic("out of range index")
+		}
+		d := deltas[u.Index]
+		d.add(d, frFromBig(u.Delta))
+		deltas[u.Index] = d
+	}
+	for j, d := range deltas {
+		if d.isZero() {
+			delete(deltas, j)
+		}
+	}
+	if len(deltas) == 0 {
+		return
+	}
+	ApplyUpdates((*Commitment)(t.com), updates)
+	g := getG1()
+	defer putG1(g)
+	if len(deltas) >= proofTableFFTThreshold {
+		message := make([]*big.Int, n)
+		for i := range message {
+			message[i] = new(big.Int)
+		}
+		for j, d := range deltas {
+			message[j] = d.big()
+		}
+		for i, p := range ProveAll(message) {
+			g.Add(t.proofs[i], t.proofs[i], p)
+		}
+	} else {
+		temp := g.New()
+		for j, d := range deltas {
+			s := d.big()
+			for i := 0; i < n; i++ {
+				if i != j {
+					g.Add(t.proofs[i], t.proofs[i], pp1Mul(g, temp, n-i+j, s))
+				}
+			}
+		}
+	}
+	for j, d := range deltas {
+		var m fr
+		m.add(frFromBig(t.message[j]), d)
+		t.message[j] = m.big()
+	}
+}
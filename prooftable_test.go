@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestProofTable applies a batch below and one above proofTableFFTThreshold and checks sampled proofs
+// against proofs of the updated message
+func TestProofTable(t *testing.T) {
+	f := benchSetup(t)
+	table := NewProofTable(f.message)
+	message := append([]*big.Int(nil), f.message...)
+	g := getG1()
+	defer putG1(g)
+	for _, size := range []int{3, proofTableFFTThreshold + 2} {
+		updates := make([]Update, size)
+		for k := range updates {
+			updates[k] = Update{Index: (37 * (k + size)) % n, Delta: big.NewInt(int64(k + 1))}
+		}
+		table.Apply(updates)
+		for _, u := range updates {
+			message[u.Index] = new(big.Int).Mod(new(big.Int).Add(message[u.Index], u.Delta), frModulus)
+		}
+		com := table.Commitment()
+		if !g.Equal(com, commit(message)) {
+			t.Fatalf("batch of %d: commitment differs from the commitment to the message", size)
+		}
+		for _, i := range []int{0, updates[0].Index, updates[1].Index + 1, n - 1} {
+			if table.Entry(i).Cmp(message[i]) != 0 {
+				t.Fatalf("batch of %d: entry %d differs", size, i)
+			}
+			if !g.Equal(table.Proof(i), generateProofSingle(message, i)) {
+				t.Fatalf("batch of %d: proof of index %d differs from generateProofSingle", size, i)
+			}
+			if !verifySingleProof(com, message[i], table.Proof(i), i) {
+				t.Fatalf("batch of %d: proof of index %d rejected", size, i)
+			}
+			if verifySingleProof(com, new(big.Int).Add(message[i], big.NewInt(1)), table.Proof(i), i) {
+				t.Fatalf("batch of %d: proof of index %d accepted for a wrong value", size, i)
+			}
+		}
+	}
+}
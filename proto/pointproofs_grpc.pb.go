@@ -0,0 +1,356 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: pointproofs.proto
+
+package pointproofspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PointProofs_Commit_FullMethodName      = "/pointproofs.v1.PointProofs/Commit"
+	PointProofs_Open_FullMethodName        = "/pointproofs.v1.PointProofs/Open"
+	PointProofs_OpenBatch_FullMethodName   = "/pointproofs.v1.PointProofs/OpenBatch"
+	PointProofs_Aggregate_FullMethodName   = "/pointproofs.v1.PointProofs/Aggregate"
+	PointProofs_Verify_FullMethodName      = "/pointproofs.v1.PointProofs/Verify"
+	PointProofs_VerifyBatch_FullMethodName = "/pointproofs.v1.PointProofs/VerifyBatch"
+)
+
+// PointProofsClient is the client API for PointProofs service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PointProofsClient interface {
+	Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error)
+	Open(ctx context.Context, in *OpenRequest, opts ...grpc.CallOption) (*OpenResponse, error)
+	OpenBatch(ctx context.Context, in *OpenBatchRequest, opts ...grpc.CallOption) (PointProofs_OpenBatchClient, error)
+	Aggregate(ctx context.Context, in *AggregateRequest, opts ...grpc.CallOption) (*AggregateResponse, error)
+	Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
+	VerifyBatch(ctx context.Context, opts ...grpc.CallOption) (PointProofs_VerifyBatchClient, error)
+}
+
+type pointProofsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPointProofsClient(cc grpc.ClientConnInterface) PointProofsClient {
+	return &pointProofsClient{cc}
+}
+
+func (c *pointProofsClient) Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error) {
+	out := new(CommitResponse)
+	err := c.cc.Invoke(ctx, PointProofs_Commit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pointProofsClient) Open(ctx context.Context, in *OpenRequest, opts ...grpc.CallOption) (*OpenResponse, error) {
+	out := new(OpenResponse)
+	err := c.cc.Invoke(ctx, PointProofs_Open_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pointProofsClient) OpenBatch(ctx context.Context, in *OpenBatchRequest, opts ...grpc.CallOption) (PointProofs_OpenBatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PointProofs_ServiceDesc.Streams[0], PointProofs_OpenBatch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pointProofsOpenBatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PointProofs_OpenBatchClient interface {
+	Recv() (*OpenResponse, error)
+	grpc.ClientStream
+}
+
+type pointProofsOpenBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *pointProofsOpenBatchClient) Recv() (*OpenResponse, error) {
+	m := new(OpenResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *pointProofsClient) Aggregate(ctx context.Context, in *AggregateRequest, opts ...grpc.CallOption) (*AggregateResponse, error) {
+	out := new(AggregateResponse)
+	err := c.cc.Invoke(ctx, PointProofs_Aggregate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pointProofsClient) Verify(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	err := c.cc.Invoke(ctx, PointProofs_Verify_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pointProofsClient) VerifyBatch(ctx context.Context, opts ...grpc.CallOption) (PointProofs_VerifyBatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PointProofs_ServiceDesc.Streams[1], PointProofs_VerifyBatch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pointProofsVerifyBatchClient{stream}
+	return x, nil
+}
+
+type PointProofs_VerifyBatchClient interface {
+	Send(*VerifyBatchRequest) error
+	CloseAndRecv() (*VerifyResponse, error)
+	grpc.ClientStream
+}
+
+type pointProofsVerifyBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *pointProofsVerifyBatchClient) Send(m *VerifyBatchRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *pointProofsVerifyBatchClient) CloseAndRecv() (*VerifyResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(VerifyResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PointProofsServer is the server API for PointProofs service.
+// All implementations must embed UnimplementedPointProofsServer
+// for forward compatibility
+type PointProofsServer interface {
+	Commit(context.Context, *CommitRequest) (*CommitResponse, error)
+	Open(context.Context, *OpenRequest) (*OpenResponse, error)
+	OpenBatch(*OpenBatchRequest, PointProofs_OpenBatchServer) error
+	Aggregate(context.Context, *AggregateRequest) (*AggregateResponse, error)
+	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
+	VerifyBatch(PointProofs_VerifyBatchServer) error
+	mustEmbedUnimplementedPointProofsServer()
+}
+
+// UnimplementedPointProofsServer must be embedded to have forward compatible implementations.
+type UnimplementedPointProofsServer struct {
+}
+
+func (UnimplementedPointProofsServer) Commit(context.Context, *CommitRequest) (*CommitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
+}
+func (UnimplementedPointProofsServer) Open(context.Context, *OpenRequest) (*OpenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Open not implemented")
+}
+func (UnimplementedPointProofsServer) OpenBatch(*OpenBatchRequest, PointProofs_OpenBatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method OpenBatch not implemented")
+}
+func (UnimplementedPointProofsServer) Aggregate(context.Context, *AggregateRequest) (*AggregateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Aggregate not implemented")
+}
+func (UnimplementedPointProofsServer) Verify(context.Context, *VerifyRequest) (*VerifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Verify not implemented")
+}
+func (UnimplementedPointProofsServer) VerifyBatch(PointProofs_VerifyBatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method VerifyBatch not implemented")
+}
+func (UnimplementedPointProofsServer) mustEmbedUnimplementedPointProofsServer() {}
+
+// UnsafePointProofsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PointProofsServer will
+// result in compilation errors.
+type UnsafePointProofsServer interface {
+	mustEmbedUnimplementedPointProofsServer()
+}
+
+func RegisterPointProofsServer(s grpc.ServiceRegistrar, srv PointProofsServer) {
+	s.RegisterService(&PointProofs_ServiceDesc, srv)
+}
+
+func _PointProofs_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PointProofsServer).Commit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PointProofs_Commit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PointProofsServer).Commit(ctx, req.(*CommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PointProofs_Open_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OpenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PointProofsServer).Open(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PointProofs_Open_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PointProofsServer).Open(ctx, req.(*OpenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PointProofs_OpenBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(OpenBatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PointProofsServer).OpenBatch(m, &pointProofsOpenBatchServer{stream})
+}
+
+type PointProofs_OpenBatchServer interface {
+	Send(*OpenResponse) error
+	grpc.ServerStream
+}
+
+type pointProofsOpenBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *pointProofsOpenBatchServer) Send(m *OpenResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _PointProofs_Aggregate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PointProofsServer).Aggregate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PointProofs_Aggregate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PointProofsServer).Aggregate(ctx, req.(*AggregateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PointProofs_Verify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PointProofsServer).Verify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PointProofs_Verify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PointProofsServer).Verify(ctx, req.(*VerifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PointProofs_VerifyBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PointProofsServer).VerifyBatch(&pointProofsVerifyBatchServer{stream})
+}
+
+type PointProofs_VerifyBatchServer interface {
+	SendAndClose(*VerifyResponse) error
+	Recv() (*VerifyBatchRequest, error)
+	grpc.ServerStream
+}
+
+type pointProofsVerifyBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *pointProofsVerifyBatchServer) SendAndClose(m *VerifyResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *pointProofsVerifyBatchServer) Recv() (*VerifyBatchRequest, error) {
+	m := new(VerifyBatchRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PointProofs_ServiceDesc is the grpc.ServiceDesc for PointProofs service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PointProofs_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pointproofs.v1.PointProofs",
+	HandlerType: (*PointProofsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Commit",
+			Handler:    _PointProofs_Commit_Handler,
+		},
+		{
+			MethodName: "Open",
+			Handler:    _PointProofs_Open_Handler,
+		},
+		{
+			MethodName: "Aggregate",
+			Handler:    _PointProofs_Aggregate_Handler,
+		},
+		{
+			MethodName: "Verify",
+			Handler:    _PointProofs_Verify_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "OpenBatch",
+			Handler:       _PointProofs_OpenBatch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "VerifyBatch",
+			Handler:       _PointProofs_VerifyBatch_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pointproofs.proto",
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	All n proofs at once, following Feist and Khovratovich. The proof for index i is
+		pi_i = \sum_j m_j pp1[n - i + j]
+	(the term j = i falls on the point at infinity pp1[n]), so pi_i = c[n - i] for the correlation
+	c[k] = \sum_j m_j pp1[k + j], 1 <= k <= n. Reversing the message turns the correlation into a cyclic
+	convolution of length 2n, and since k + j < 2n it does not wrap around:
+		c = IFFT(FFT(pp1) * FFT(m')),	m'[0] = m_0, m'[2n - j] = m_j for 1 <= j < n
+	where FFT(pp1) is carried out in the exponent. It only depends on the parameters and is cached.
+	This takes about n log(2n) scalar multiplications for the inverse FFT and 2n for the products,
+	instead of the n^2 of generating every proof on its own. n has to be a power of two.
+*/
+
+var (
+	pp1SpectrumMu sync.Mutex
+	// pp1Spectrum is FFT(pp1) over the 2n-th roots of unity, computed by the first ProveAll and dropped
+	// by install
+	pp1Spectrum []*bls.PointG1
+)
+
+// getPP1Spectrum returns FFT(pp1), computing it on first use and reporting that work to tracker
+func getPP1Spectrum(g *bls.G1, omega fr, tracker *progressTracker) []*bls.PointG1 {
+	pp1SpectrumMu.Lock()
+	defer pp1SpectrumMu.Unlock()
+	if pp1Spectrum != nil {
+		return pp1Spectrum
+	}
+	spectrum := make([]*bls.PointG1, 2*n)
+	copy(spectrum, pp1Range(0, 2*n))
+	fftG1(g, spectrum, omega)
+	pp1Spectrum = spectrum
+	tracker.add(2 * n)
+	return spectrum
+}
+
+// ProofSet holds the proofs for all n indices of a message, ProofSet[i] being the proof for index i
+type ProofSet []*bls.PointG1
+
+// ProveAll returns the proofs for all n indices, proofs[i] being the one generateProofSingle(message, i) returns
+func ProveAll(message []*big.Int) ProofSet {
+	return profiled("prove_all", func() ProofSet {
+		return generateAllProofs(message, nil)
+	})
+}
+
+/*
+	A proof set is written as an artifact of kind 8, the header of artifacts.go followed by the n proofs
+	in compressed form and in the order of the indices. Since n is compiled in there is no count and a
+	set always takes 1 + fingerprintSize + 48n bytes, half of what n proof artifacts would.
+*/
+
+// Write serializes the proofs to w, it panics unless there are n of them
+func (s ProofSet) Write(w io.Writer) error {
+	if len(s) != n {
+		panic("proof set must hold n proofs")
+	}
+	bw := bufio.NewWriter(w)
+	if err := writeArtifactHeader(bw, artifactProofSet); err != nil {
+		return err
+	}
+	for _, proof := range s {
+		if err := writeCompressedG1(bw, proof); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadProofSet parses a proof set written by ProofSet.Write under the installed parameters. Every proof
+// is checked to lie in the correct subgroup, not to be a valid proof
+func ReadProofSet(r io.Reader) (ProofSet, error) {
+	br := bufio.NewReader(r)
+	if err := readArtifactHeader(br, artifactProofSet); err != nil {
+		return nil, err
+	}
+	s := make(ProofSet, n)
+	for i := range s {
+		proof, err := readCompressedG1(br)
+		if err != nil {
+			return nil, fmt.Errorf("proof %d: %w", i, err)
+		}
+		s[i] = proof
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, errors.New("trailing bytes after proof set")
+	}
+	return s, nil
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. vector message
+		4. an optional progress callback
+	It returns the proofs for all n indices, proofs[i] being the one generateProofSingle(message, i) returns
+*/
+func generateAllProofs(message []*big.Int, progress progressFunc) []*bls.PointG1 {
+	checkVector(message)
+	g := bls.NewG1()
+	omega := rootOfUnity(2 * n)
+	// one unit of work per point of every pass over 2n points
+	work := 4 * n
+	pp1SpectrumMu.Lock()
+	if pp1Spectrum == nil {
+		work += 2 * n
+	}
+	pp1SpectrumMu.Unlock()
+	tracker := newProgressTracker(progress, work)
+	spectrum := getPP1Spectrum(g, omega, tracker)
+	reversed := make([]fr, 2*n)
+	reversed[0] = frFromBig(message[0])
+	for j := 1; j < n; j++ {
+		reversed[2*n-j] = frFromBig(message[j])
+	}
+	fftScalars(reversed, omega)
+	// the 1 / 2n of the inverse FFT is folded into the products
+	var sizeInv, s, omegaInv fr
+	sizeInv.inverse(frFromUint64(2 * n))
+	c := make([]*bls.PointG1, 2*n)
+	for k := range c {
+		s.mul(reversed[k], sizeInv)
+		c[k] = mulG1(g, g.New(), spectrum[k], s)
+		tracker.add(1)
+	}
+	fftG1(g, c, *omegaInv.inverse(omega))
+	tracker.add(2 * n)
+	proofs := make([]*bls.PointG1, n)
+	for i := 0; i < n; i++ {
+		proofs[i] = c[n-i]
+	}
+	return proofs
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. vector message
+		4. the indices to prove
+	It returns proofs[k] = generateProofSingle(message, indices[k]). Every proof is a multi-scalar
+	multiplication of the message with a different window of pp1, so the message is recoded to signed
+	digits once and the proofs are spread over one goroutine per CPU. For most of the indices ProveAll
+	is cheaper. With an MSM set by SetMSM every proof is one call to it instead.
+*/
+func ProveSet(message []*big.Int, indices []int) []*bls.PointG1 {
+	observeBatch("prove_set", len(indices))
+	return profiled("prove_set", func() []*bls.PointG1 {
+		checkVector(message)
+		for _, i := range indices {
+			if !(0 <= i && i < n) {
+				panic("out of range index")
+			}
+		}
+		proofs := make([]*bls.PointG1, len(indices))
+		if msmOffload != nil {
+			scalars := frVector(message)
+			for k, i := range indices {
+				// the term of the index itself falls on pp1[n], the point at infinity
+				proofs[k] = msmOffload.MultiExpG1(pp1Range(n-i, 2*n-i), scalars)
+			}
+			return proofs
+		}
+		c := msmWindow(n)
+		digits := recodeScalars(message, c)
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < runtime.NumCPU(); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				// G1 holds temporaries, every goroutine needs its own, and its buckets serve all its proofs
+				g := getG1()
+				defer putG1(g)
+				var s scratch
+				for k := range next {
+					// the term of the index itself falls on pp1[n], the point at infinity
+					i := indices[k]
+					proofs[k] = msmG1Digits(g, &s, pp1Range(n-i, 2*n-i), digits, c)
+				}
+			}()
+		}
+		for k := range indices {
+			next <- k
+		}
+		close(next)
+		wg.Wait()
+		return proofs
+	})
+}
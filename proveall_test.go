@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"sync"
+	"testing"
+)
+
+// TestProveAll compares ProveAll with generateProofSingle on a sample of the indices, a full comparison
+// takes n^2 scalar multiplications
+func TestProveAll(t *testing.T) {
+	f := benchSetup(t)
+	proofs := ProveAll(f.message)
+	if len(proofs) != n {
+		t.Fatalf("ProveAll returned %d proofs", len(proofs))
+	}
+	g := getG1()
+	defer putG1(g)
+	for _, i := range []int{0, 1, 2, 511, 512, n - 2, n - 1} {
+		if !g.Equal(proofs[i], generateProofSingle(f.message, i)) {
+			t.Fatalf("proof of index %d differs from generateProofSingle", i)
+		}
+		if !verifySingleProof(f.com, f.message[i], proofs[i], i) {
+			t.Fatalf("proof of index %d rejected", i)
+		}
+		wrong := new(big.Int).Add(f.message[i], big.NewInt(1))
+		if verifySingleProof(f.com, wrong, proofs[i], i) {
+			t.Fatalf("proof of index %d accepted for a wrong value", i)
+		}
+	}
+	if verifySingleProof(f.com, f.message[3], proofs[4], 3) {
+		t.Fatal("proof of index 4 accepted for index 3")
+	}
+
+	var buf bytes.Buffer
+	if err := proofs.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadProofSet(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range proofs {
+		if !g.Equal(read[i], proofs[i]) {
+			t.Fatalf("proof %d read back differs", i)
+		}
+	}
+	if _, err := ReadProofSet(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err == nil {
+		t.Fatal("truncated proof set accepted")
+	}
+}
+
+// TestProveAllConcurrent runs ProveAll from several goroutines on parameters without a cached spectrum,
+// go test -race checks they share its computation safely
+func TestProveAllConcurrent(t *testing.T) {
+	f := benchSetup(t)
+	// as install leaves it
+	pp1SpectrumMu.Lock()
+	pp1Spectrum = nil
+	pp1SpectrumMu.Unlock()
+	results := make([]ProofSet, 3)
+	var wg sync.WaitGroup
+	for k := range results {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			results[k] = ProveAll(f.message)
+		}(k)
+	}
+	wg.Wait()
+	g := getG1()
+	defer putG1(g)
+	for k, proofs := range results {
+		for _, i := range []int{0, 700, n - 1} {
+			if !g.Equal(proofs[i], generateProofSingle(f.message, i)) {
+				t.Fatalf("goroutine %d: proof of index %d differs from generateProofSingle", k, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"container/list"
+	"math/big"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	A Prover owns a mutable message and serves proofs for it. Computing a proof is a multi-scalar
+	multiplication over the whole message, so proofs of frequently requested indices are kept in an LRU
+	cache keyed by the version of the message and the index. Every change of an entry starts a new version.
+	The proof of index i covers every entry but m_i, so a change of m_j invalidates all cached proofs except
+	the one of j, which is carried over to the new version.
+	Cache misses are served from the signed digits of the message, which the Prover keeps in its scratch
+	space and updates entry by entry, so neither the recoding nor its memory is paid again per proof.
+*/
+
+// proofKey identifies a proof of one index in one version of the message
+type proofKey struct {
+	version uint64
+	index   int
+}
+
+type proofEntry struct {
+	key   proofKey
+	proof *bls.PointG1
+}
+
+// proofCache is a least recently used cache of proofs, it is not safe for concurrent use
+type proofCache struct {
+	capacity int
+	// most recently used first
+	order   *list.List
+	entries map[proofKey]*list.Element
+}
+
+func newProofCache(capacity int) *proofCache {
+	return &proofCache{capacity: capacity, order: list.New(), entries: make(map[proofKey]*list.Element)}
+}
+
+func (c *proofCache) get(key proofKey) (*bls.PointG1, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*proofEntry).proof, true
+}
+
+func (c *proofCache) put(key proofKey, proof *bls.PointG1) {
+	if c.capacity <= 0 {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*proofEntry).proof = proof
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&proofEntry{key, proof})
+	if c.order.Len() > c.capacity {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.entries, last.Value.(*proofEntry).key)
+	}
+}
+
+// invalidate drops every proof of the given version except the one of keep, which moves to the next version
+func (c *proofCache) invalidate(version uint64, keep int) {
+	kept, ok := c.entries[proofKey{version, keep}]
+	c.order.Init()
+	c.entries = make(map[proofKey]*list.Element)
+	if ok {
+		c.put(proofKey{version + 1, keep}, kept.Value.(*proofEntry).proof)
+	}
+}
+
+// Prover serves proofs for a message that can change over time, it is safe for concurrent use
+type Prover struct {
+	mu      sync.Mutex
+	message []*big.Int
+	version uint64
+	cache   *proofCache
+	// the message recoded for msmG1Digits with windows of window bits, and the buckets for it
+	window  int
+	digits  [][]int
+	scratch scratch
+}
+
+// NewProver returns a Prover for a copy of message that caches up to cacheSize proofs
+func NewProver(message []*big.Int, cacheSize int) *Prover {
+	checkVector(message)
+	m := make([]*big.Int, n)
+	for i := range message {
+		m[i] = new(big.Int).Set(message[i])
+	}
+	p := &Prover{message: m, cache: newProofCache(cacheSize), window: msmWindow(n)}
+	p.digits = p.scratch.recode(m, p.window)
+	return p
+}
+
+// Version returns the version of the message, it starts at 0 and grows by one with every change
+func (p *Prover) Version() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.version
+}
+
+// Entry returns a copy of m_index
+func (p *Prover) Entry(index int) *big.Int {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return new(big.Int).Set(p.message[index])
+}
+
+// Prove returns the proof of m_index for the current message, from the cache if possible
+func (p *Prover) Prove(index int) *bls.PointG1 {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := proofKey{p.version, index}
+	proof, ok := p.cache.get(key)
+	observeProofCache("prover", ok)
+	if !ok {
+		proof = p.prove(index)
+		p.cache.put(key, proof)
+	}
+	// callers must not be able to change the cached point
+	return new(bls.PointG1).Set(proof)
+}
+
+// Set changes m_index to value, starting a new version of the message
+func (p *Prover) Set(index int, value *big.Int) {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	if value.Sign() < 0 || value.Cmp(frModulus) != -1 {
+		panic("the message does not lie in the group")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.message[index] = new(big.Int).Set(value)
+	signedDigits(value, p.window, p.digits[index])
+	p.cache.invalidate(p.version, index)
+	p.version++
+}
+
+// prove computes the proof of m_index like ProveSet, reusing the digits and buckets of p
+func (p *Prover) prove(index int) *bls.PointG1 {
+	if msmOffload != nil {
+		return ProveSet(p.message, []int{index})[0]
+	}
+	return profiled("prove", func() *bls.PointG1 {
+		g := getG1()
+		defer putG1(g)
+		// the term of the index itself falls on pp1[n], the point at infinity
+		return msmG1Digits(g, &p.scratch, pp1Range(n-index, 2*n-index), p.digits, p.window)
+	})
+}
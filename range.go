@@ -0,0 +1,135 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Openings of a contiguous range [a, b), e.g. of records serialized into consecutive entries. With
+	independent scalars OpenSubvector spends (b - a) * n field multiplications on the scalars of its MSM.
+	Here the proofs are aggregated with the powers t_i = t^i of a single challenge t, the challenge "t" of
+	a transcript of domain rangeDomain that absorbed
+		"C": C, "a": a, "b": b, then "m_i": m_i for a <= i < b
+	Wrong entries still fail with overwhelming probability, their errors make a non-zero polynomial in t
+	of degree below n. In the scalars of the MSM the powers factor out,
+		\sum_{i=a}^{b-1} t^i m_{k-n+i} = t^{n-k} \sum_{j=k-n+a}^{k-n+b-1} t^j m_j = t^{n-k} (P_{k-n+b} - P_{k-n+a})
+	with the prefix sums P_x = \sum_{j<x} t^j m_j (clamped to 0 <= x <= n), so all 2n scalars take O(n)
+	field multiplications whatever the length of the range.
+*/
+
+// checkRange panics unless 0 <= a <= b <= n
+func checkRange(a, b int) {
+	if !(0 <= a && a <= b && b <= n) {
+		panic("out of range index")
+	}
+}
+
+// rangeChallenge derives t from the claim that com holds values in [a, b)
+func rangeChallenge(com *bls.PointG1, a, b int, values []*big.Int) fr {
+	checkRange(a, b)
+	if len(values) != b-a {
+		panic("arrays with incorrect length")
+	}
+	t := NewTranscript(rangeDomain)
+	t.AppendG1("C", com)
+	t.AppendUint32("a", uint32(a))
+	t.AppendUint32("b", uint32(b))
+	for _, v := range values {
+		t.AppendScalar("m_i", v)
+	}
+	return frFromBig(t.ChallengeScalar("t"))
+}
+
+// rangePowers returns t^i for a <= i < b
+func rangePowers(t fr, a, b int) []fr {
+	powers := make([]fr, b-a)
+	var power fr
+	power.exp(t, big.NewInt(int64(a)))
+	for k := range powers {
+		powers[k] = power
+		power.mul(power, t)
+	}
+	return powers
+}
+
+// clampIndex returns x clamped to [0, n]
+func clampIndex(x int) int {
+	if x < 0 {
+		return 0
+	}
+	if x > n {
+		return n
+	}
+	return x
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the message vector
+		4. the range [a, b) to open
+	It returns the proof that the message holds message[a:b] in the range, checked by VerifyRange.
+	The challenge depends on the commitment, which it computes once more.
+*/
+func OpenRange(message []*big.Int, a, b int) *bls.PointG1 {
+	return profiled("open_range", func() *bls.PointG1 {
+		checkVector(message)
+		checkRange(a, b)
+		if a == b {
+			return bls.NewG1().Zero()
+		}
+		t := rangeChallenge(commit(message), a, b, message[a:b])
+		m := frVector(message)
+		prefix := make([]fr, n+1)
+		power, product := frOne, fr{}
+		for j := 0; j < n; j++ {
+			product.mul(power, m[j])
+			prefix[j+1].add(prefix[j], product)
+			power.mul(power, t)
+		}
+		// scalars[k] = t^{n-k} (P_{k-n+b} - P_{k-n+a}), factor runs through t^{n-k} starting from power = t^n
+		var tInv fr
+		tInv.inverse(t)
+		scalars := make([]fr, 2*n)
+		factor := power
+		for k := range scalars {
+			scalars[k].sub(prefix[clampIndex(k-n+b)], prefix[clampIndex(k-n+a)])
+			scalars[k].mul(scalars[k], factor)
+			factor.mul(factor, tInv)
+		}
+		return currentMSM().MultiExpG1(pp1Range(0, 2*n), scalars)
+	})
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the commitment
+		4. the range [a, b)
+		5. the values in the range, values[k] being the entry a + k
+		6. the proof of OpenRange
+	It reports whether the proof opens the commitment to the values in the range. An empty range is valid.
+*/
+func VerifyRange(com *bls.PointG1, a, b int, values []*big.Int, proof *bls.PointG1) bool {
+	return profiled("verify_range", func() bool {
+		checkRange(a, b)
+		if a == b {
+			return len(values) == 0
+		}
+		for _, v := range values {
+			if v.Sign() < 0 || v.Cmp(frModulus) != -1 {
+				panic("the message does not lie in the group")
+			}
+		}
+		powers := rangePowers(rangeChallenge(com, a, b, values), a, b)
+		indices := make([]int, b-a)
+		for k := range indices {
+			indices[k] = a + k
+		}
+		return verifySameCommitmentAggregation(com, proof, values, bigVector(powers), indices, b-a)
+	})
+}
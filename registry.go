@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Registries of BLS public keys, e.g. the validators of a committee. Keys are G1 points as in the
+	minimal-pubkey-size variant used by Ethereum, and the key at position i enters the message as
+		EntryEncoder(application).Encode(i, compressed key)
+	so a position holds a key rather than a bare scalar, and the registry of one application cannot be
+	read as that of another. Unused positions hold 0. A key entry is serialized as the big endian uint32
+	position followed by the 48 byte compressed key.
+*/
+
+// registryEntrySize is the size of a serialized key entry
+const registryEntrySize = 4 + g1CompressedSize
+
+// RegistryEntry is a public key and its position in a registry
+type RegistryEntry struct {
+	Index int
+	Key   *bls.PointG1
+}
+
+// checkKey panics unless the key is a point of the subgroup other than the point at infinity
+func checkKey(key *bls.PointG1) {
+	g := getG1()
+	defer putG1(g)
+	if g.IsZero(key) || !g.InCorrectSubgroup(key) {
+		panic("invalid public key")
+	}
+}
+
+// registryScalar returns the message entry of the key at the index
+func registryScalar(e *EntryEncoder, index int, key *bls.PointG1) *big.Int {
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer cannot fail
+	_ = writeCompressedG1(&buf, key)
+	return e.Encode(index, buf.Bytes())
+}
+
+// Registry is a commitment to up to n public keys, it is not safe for concurrent use
+type Registry struct {
+	encoder *EntryEncoder
+	keys    []*bls.PointG1
+	message []*big.Int
+	com     *Commitment
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the name of the application, see NewEntryEncoder
+		4. the keys, at most n of them, keys[i] taking position i
+	It returns the registry of the keys.
+*/
+func CommitRegistry(application string, keys []*bls.PointG1) *Registry {
+	if len(keys) > n {
+		panic("too many keys")
+	}
+	r := &Registry{encoder: NewEntryEncoder(application), message: make([]*big.Int, n)}
+	for i := range r.message {
+		r.message[i] = new(big.Int)
+	}
+	for i, key := range keys {
+		checkKey(key)
+		r.message[i] = registryScalar(r.encoder, i, key)
+		r.keys = append(r.keys, new(bls.PointG1).Set(key))
+	}
+	r.com = (*Commitment)(commit(r.message))
+	return r
+}
+
+// Size returns the number of keys in the registry
+func (r *Registry) Size() int {
+	return len(r.keys)
+}
+
+// Commitment returns the commitment to the registry
+func (r *Registry) Commitment() *bls.PointG1 {
+	return new(bls.PointG1).Set(r.com.Point())
+}
+
+// Key returns the key at the index
+func (r *Registry) Key(index int) *bls.PointG1 {
+	if !(0 <= index && index < len(r.keys)) {
+		panic("out of range index")
+	}
+	return new(bls.PointG1).Set(r.keys[index])
+}
+
+// Add registers the key at the next free position, which it returns, with one scalar multiplication
+func (r *Registry) Add(key *bls.PointG1) int {
+	if len(r.keys) == n {
+		panic("the registry is full")
+	}
+	checkKey(key)
+	i := len(r.keys)
+	entry := registryScalar(r.encoder, i, key)
+	r.com.Update(i, r.message[i], entry)
+	r.message[i] = entry
+	r.keys = append(r.keys, new(bls.PointG1).Set(key))
+	return i
+}
+
+// ProveMembership returns the entry of the key at the index and the proof that the registry holds it
+func (r *Registry) ProveMembership(index int) (RegistryEntry, *bls.PointG1) {
+	entry := RegistryEntry{Index: index, Key: r.Key(index)}
+	return entry, ProveSet(r.message, []int{index})[0]
+}
+
+// VerifyRegistryMembership reports whether the proof shows the registry of the application holds the
+// entry's key at its position
+func VerifyRegistryMembership(com *bls.PointG1, application string, entry RegistryEntry, proof *bls.PointG1) bool {
+	checkKey(entry.Key)
+	return verifySingleProof(com, registryScalar(NewEntryEncoder(application), entry.Index, entry.Key), proof, entry.Index)
+}
+
+// encodeRegistryEntry serializes a key entry
+func encodeRegistryEntry(entry RegistryEntry) []byte {
+	if !(0 <= entry.Index && entry.Index < n) {
+		panic("out of range index")
+	}
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer cannot fail
+	_ = binary.Write(&buf, binary.BigEndian, uint32(entry.Index))
+	_ = writeCompressedG1(&buf, entry.Key)
+	return buf.Bytes()
+}
+
+// decodeRegistryEntry parses a key entry, rejecting keys a registry would not accept
+func decodeRegistryEntry(data []byte) (RegistryEntry, error) {
+	if len(data) != registryEntrySize {
+		return RegistryEntry{}, fmt.Errorf("expected %d bytes, got %d", registryEntrySize, len(data))
+	}
+	index := binary.BigEndian.Uint32(data)
+	if index >= n {
+		return RegistryEntry{}, fmt.Errorf("index %d out of range", index)
+	}
+	key, err := readCompressedG1(bytes.NewReader(data[4:]))
+	if err != nil {
+		return RegistryEntry{}, fmt.Errorf("key: %w", err)
+	}
+	g := getG1()
+	defer putG1(g)
+	if g.IsZero(key) {
+		return RegistryEntry{}, errors.New("key is the point at infinity")
+	}
+	return RegistryEntry{Index: int(index), Key: key}, nil
+}
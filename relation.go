@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Linear relations. C_3 commits to a * v_1 + b * v_2 for public a and b exactly when
+		D = C_3 - a * C_1 - b * C_2
+	commits to the zero vector. For plain commitments that is D = 0, which anyone can check from the
+	commitments without a proof. For hiding commitments D = rho * h with rho = r_3 - a * r_1 - b * r_2,
+	and the prover shows it knows rho with a Schnorr proof for the base h: R = k * h for a random k,
+	the challenge e of a transcript over C_1, C_2, C_3, a, b and R, and z = k + e * rho, checked as
+	z * h = R + e * D. Since nobody knows the logarithm of h to the base of the parameters, a prover
+	knowing rho knows that the vectors cancel. Neither rho nor anything about the vectors is revealed.
+*/
+
+// CheckLinearRelation reports whether c3 commits to a * v1 + b * v2 for the vectors v1, v2 of c1, c2
+func CheckLinearRelation(c1, c2, c3 *Commitment, a, b *big.Int) bool {
+	d := LinearCombination([]*Commitment{c1, c2, c3}, []*big.Int{new(big.Int).Neg(a), new(big.Int).Neg(b), big.NewInt(1)})
+	return bls.NewG1().IsZero(d.Point())
+}
+
+// LinearRelationProof proves a linear relation between hiding commitments
+type LinearRelationProof struct {
+	R *bls.PointG1
+	Z *big.Int
+}
+
+// linearRelationDifference returns D = c3 - a * c1 - b * c2
+func linearRelationDifference(c1, c2, c3 *bls.PointG1, a, b *big.Int) *bls.PointG1 {
+	coms := []*Commitment{(*Commitment)(c1), (*Commitment)(c2), (*Commitment)(c3)}
+	return LinearCombination(coms, []*big.Int{new(big.Int).Neg(a), new(big.Int).Neg(b), big.NewInt(1)}).Point()
+}
+
+// linearRelationChallenge derives the challenge of the Schnorr proof
+func linearRelationChallenge(c1, c2, c3 *bls.PointG1, a, b *big.Int, r *bls.PointG1) fr {
+	t := NewTranscript(linearRelationDomain)
+	t.AppendG1("C_1", c1)
+	t.AppendG1("C_2", c2)
+	t.AppendG1("C_3", c3)
+	t.AppendScalar("a", new(big.Int).Mod(a, frModulus))
+	t.AppendScalar("b", new(big.Int).Mod(b, frModulus))
+	t.AppendG1("R", r)
+	return frFromBig(t.ChallengeScalar("e"))
+}
+
+/*
+	It takes the following arguments:
+		1. the hiding commitments C_1, C_2, C_3 of CommitHiding
+		2. the public scalars a and b
+		3. the randomness r_1, r_2, r_3 of the commitments
+	It returns a proof that C_3 commits to a * v_1 + b * v_2. If the relation does not hold the proof
+	does not verify.
+*/
+func ProveHidingLinearRelation(c1, c2, c3 *bls.PointG1, a, b, r1, r2, r3 *big.Int) *LinearRelationProof {
+	// rho = r_3 - a * r_1 - b * r_2
+	var rho, term fr
+	rho = frFromBig(r3)
+	term.mul(frFromBig(a), frFromBig(r1))
+	rho.sub(rho, term)
+	term.mul(frFromBig(b), frFromBig(r2))
+	rho.sub(rho, term)
+	k := frFromBig(generateBigIntegerArray(1, frModulus)[0])
+	g := getG1()
+	defer putG1(g)
+	r := mulG1(g, g.New(), hidingBase(), k)
+	e := linearRelationChallenge(c1, c2, c3, a, b, r)
+	// z = k + e * rho
+	var z fr
+	z.mul(e, rho)
+	z.add(z, k)
+	return &LinearRelationProof{R: r, Z: z.big()}
+}
+
+// VerifyHidingLinearRelation reports whether proof shows that the hiding commitment c3 commits to
+// a * v1 + b * v2 for the vectors v1, v2 of the hiding commitments c1, c2
+func VerifyHidingLinearRelation(c1, c2, c3 *bls.PointG1, a, b *big.Int, proof *LinearRelationProof) bool {
+	if proof.Z.Sign() < 0 || proof.Z.Cmp(frModulus) != -1 {
+		return false
+	}
+	d := linearRelationDifference(c1, c2, c3, a, b)
+	e := linearRelationChallenge(c1, c2, c3, a, b, proof.R)
+	g := getG1()
+	defer putG1(g)
+	// z * h = R + e * D
+	lhs := mulG1(g, g.New(), hidingBase(), frFromBig(proof.Z))
+	rhs := mulG1(g, g.New(), d, e)
+	g.Add(rhs, rhs, proof.R)
+	return g.Equal(lhs, rhs)
+}
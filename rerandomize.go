@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math/big"
+)
+
+/*
+	Rerandomization. Adding delta * h to a hiding commitment, for a fresh random delta, gives a commitment
+	to the same message with randomness r + delta that nobody without delta can link to the first one.
+	Whoever holds a proof needs delta to follow: with B_i = g_2^{alpha^{n+1-i}} the proof (pi', W) of
+	entry i becomes
+		(pi' - s * h, W + delta * B_i + s * g_2)
+	for a fresh s, so the new proof cannot be linked to the old one either. delta is the update token
+	handed to the proof holders over a private channel. A plain commitment turns into a hiding one with
+	randomness delta the same way, its proofs pi taking the form (pi, 0) of hiding proofs with r = s = 0.
+*/
+
+// RerandomizeToken is what the holders of proofs need to follow a rerandomization
+type RerandomizeToken struct {
+	Delta *big.Int
+}
+
+/*
+	It takes the following arguments:
+		1. the hiding commitment, changed in place
+	It turns the commitment into an unlinkable commitment to the same message and returns the token for
+	the proof holders.
+*/
+func (c *Commitment) Rerandomize() *RerandomizeToken {
+	delta := generateBigIntegerArray(1, frModulus)[0]
+	g := getG1()
+	defer putG1(g)
+	p := c.Point()
+	g.Add(p, p, mulG1(g, g.New(), hidingBase(), frFromBig(delta)))
+	return &RerandomizeToken{Delta: delta}
+}
+
+// Randomness returns the randomness of the rerandomized commitment for the randomness r it had before
+func (t *RerandomizeToken) Randomness(r *big.Int) *big.Int {
+	res := new(big.Int).Add(r, t.Delta)
+	return res.Mod(res, frModulus)
+}
+
+// Rerandomize turns the hiding proof of the entry at the index into a proof of the same entry for
+// the commitment rerandomized with the token, in place
+func (p *HidingProof) Rerandomize(t *RerandomizeToken, index int) {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	s := frFromBig(generateBigIntegerArray(1, frModulus)[0])
+	g1, g2 := getG1(), getG2()
+	defer putG1(g1)
+	defer putG2(g2)
+	g1.Sub(p.Pi, p.Pi, mulG1(g1, g1.New(), hidingBase(), s))
+	g2.Add(p.W, p.W, mulG2(g2, g2.New(), pp2[n-index-1], frFromBig(t.Delta)))
+	g2.Add(p.W, p.W, mulG2(g2, g2.New(), g2Generator, s))
+}
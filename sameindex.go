@@ -0,0 +1,116 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Same-index openings. Opening position i of C_1, ..., C_m is the cross-commitment aggregation of m
+	single openings, and since all of them are at the same index both sides collapse. With the weights
+	w_j = t'_j * t_{j,i} of fiatshamir.go the proof is
+		pi = \sum_j w_j pi_{j,i} = \sum_{k != i} (\sum_j w_j m_{j,k}) * pp1[n - i + k]
+	a single MSM of the folded message, and the verifier checks
+		e(\sum_j w_j C_j, g_2^{alpha^{n+1-i}}) = e(pi, g_2) * g_T^{alpha^{n+1} \sum_j w_j m_{j,i}}
+	with one MSM in G1 and two pairings however many commitments there are. The proof is the one
+	AggregateCrossCommitment gives, so VerifyCrossCommitment accepts it as well.
+*/
+
+// sameIndexWeights returns w_j = t'_j * t_{j,i} of the openings of coms at the index to values
+func sameIndexWeights(coms []*bls.PointG1, index int, values []*big.Int) []fr {
+	if len(coms) != len(values) {
+		panic("arrays with incorrect length")
+	}
+	t := newCrossTranscript(coms, sameIndexIndices(len(coms), index), sameIndexValues(values), nil)
+	w := make([]fr, len(coms))
+	for j := range w {
+		w[j].mul(frFromBig(t.ComScalars[j]), frFromBig(t.MessageScalars[j][0]))
+	}
+	return w
+}
+
+// sameIndexIndices returns the claim of m openings at the index in the form of VerifyCrossCommitment
+func sameIndexIndices(m, index int) [][]int {
+	res := make([][]int, m)
+	for j := range res {
+		res[j] = []int{index}
+	}
+	return res
+}
+
+// sameIndexValues returns one opened value per commitment in the form of VerifyCrossCommitment
+func sameIndexValues(values []*big.Int) [][]*big.Int {
+	res := make([][]*big.Int, len(values))
+	for j, v := range values {
+		res[j] = []*big.Int{v}
+	}
+	return res
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the commitments and the messages they commit to
+		4. the index to open
+	It returns the entries of the messages at the index and a single proof of all of them.
+*/
+func ProveSameIndex(coms []*bls.PointG1, messages [][]*big.Int, index int) ([]*big.Int, *bls.PointG1) {
+	if len(coms) != len(messages) {
+		panic("arrays with incorrect length")
+	}
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	values := make([]*big.Int, len(messages))
+	for j, m := range messages {
+		checkVector(m)
+		values[j] = m[index]
+	}
+	return values, profiled("prove_same_index", func() *bls.PointG1 {
+		w := sameIndexWeights(coms, index, values)
+		// folded[k] = \sum_j w_j m_{j,k}
+		folded := make([]fr, n)
+		var product fr
+		for j, m := range messages {
+			for k, x := range m {
+				product.mul(frFromBig(x), w[j])
+				folded[k].add(folded[k], product)
+			}
+		}
+		// the term of the index itself falls on pp1[n], the point at infinity
+		return currentMSM().MultiExpG1(pp1Range(n-index, 2*n-index), folded)
+	})
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the commitments
+		4. the index
+		5. the entries of the committed messages at the index
+		6. the proof of ProveSameIndex
+	It reports whether the proof opens every commitment to its value at the index.
+*/
+func VerifySameIndex(coms []*bls.PointG1, index int, values []*big.Int, proof *bls.PointG1) bool {
+	return profiled("verify_same_index", func() bool {
+		if !(0 <= index && index < n) {
+			panic("out of range index")
+		}
+		if len(coms) == 0 {
+			return len(values) == 0
+		}
+		w := sameIndexWeights(coms, index, values)
+		var sum, product fr
+		for j, v := range values {
+			product.mul(frFromBig(v), w[j])
+			sum.add(sum, product)
+		}
+		// e(\sum_j w_j C_j, g_2^{alpha^{n+1-i}}) * e(pi, g_2)^{-1} = g_T^{alpha^{n+1} \sum_j w_j m_{j,i}}
+		g1s := []*bls.PointG1{backend.MultiExpG1(coms, w), negG1(proof)}
+		g2s := []*bls.PointG2{pp2[n-index-1], g2Generator}
+		return verifyPairings(g1s, g2s, sum)
+	})
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/big"
+	"math/bits"
+)
+
+/*
+	Vector commitment schemes behind a common interface, so that an application or a benchmark can swap
+	PointProofs for a Merkle tree by changing the scheme it was handed. Openings cross the interface as
+	SchemeOpening, the Opening of batch.go with the proof serialized: Opening holds a G1 point, which a
+	Merkle path is not, so the proof and the commitment travel as bytes. PointProofs uses the artifacts
+	of artifacts.go, the Merkle tree its root and the sibling hashes of the path to the leaf.
+	SchemeOpening.Opening and Opening.SchemeOpening convert between the two for PointProofs, so its
+	openings go on to AggregateSameCommitment and the other functions taking Opening.
+	The Merkle tree is a binary SHA-256 tree over the n entries, with leaves
+		H(0x00 || index as uint32 || entry as 32 bytes) and inner nodes H(0x01 || left || right)
+	so a proof takes log2(n) hashes. n has to be a power of two. Hashes friendlier to circuits, e.g.
+	Poseidon, fit the same layout but are not implemented here.
+*/
+
+// SchemeOpening is an Opening whose proof is serialized by the scheme it belongs to
+type SchemeOpening struct {
+	Index int
+	Value *big.Int
+	Proof []byte
+}
+
+// SchemeOpening returns the opening as PointProofsScheme opens and verifies it
+func (o Opening) SchemeOpening() SchemeOpening {
+	return SchemeOpening{Index: o.Index, Value: o.Value, Proof: encodeProof(o.Proof)}
+}
+
+// Opening decodes an opening of PointProofsScheme, checking the proof lies in the correct subgroup
+func (o SchemeOpening) Opening() (Opening, error) {
+	proof, err := decodeProof(o.Proof)
+	if err != nil {
+		return Opening{}, err
+	}
+	return Opening{Index: o.Index, Value: o.Value, Proof: proof}, nil
+}
+
+// VectorCommitment is a vector commitment scheme for messages of n field elements
+type VectorCommitment interface {
+	// Name identifies the scheme
+	Name() string
+	// Commit returns the commitment to the message
+	Commit(message []*big.Int) []byte
+	// Open returns the opening of the entry at the index
+	Open(message []*big.Int, index int) SchemeOpening
+	// Verify reports whether the opening shows the committed message has its value at its index
+	Verify(com []byte, opening SchemeOpening) bool
+}
+
+var (
+	// PointProofsScheme is the scheme of this package under the installed parameters
+	PointProofsScheme VectorCommitment = pointProofsScheme{}
+	// MerkleScheme is the SHA-256 Merkle tree above
+	MerkleScheme VectorCommitment = merkleScheme{}
+)
+
+type pointProofsScheme struct{}
+
+func (pointProofsScheme) Name() string { return "pointproofs" }
+
+func (pointProofsScheme) Commit(message []*big.Int) []byte {
+	return encodeCommitment(commit(message))
+}
+
+func (pointProofsScheme) Open(message []*big.Int, index int) SchemeOpening {
+	checkVector(message)
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	o := Opening{Index: index, Value: message[index], Proof: ProveSet(message, []int{index})[0]}
+	return o.SchemeOpening()
+}
+
+func (pointProofsScheme) Verify(com []byte, opening SchemeOpening) bool {
+	c, err := decodeCommitment(com)
+	if err != nil {
+		return false
+	}
+	o, err := opening.Opening()
+	if err != nil {
+		return false
+	}
+	return verifySingleProof(c, o.Value, o.Proof, o.Index)
+}
+
+type merkleScheme struct{}
+
+func (merkleScheme) Name() string { return "merkle-sha256" }
+
+// merkleLeaf returns the leaf hash of the entry at the index
+func merkleLeaf(index int, value *big.Int) [sha256.Size]byte {
+	buf := make([]byte, 1+4+scalarSize)
+	buf[1], buf[2], buf[3], buf[4] = byte(index>>24), byte(index>>16), byte(index>>8), byte(index)
+	value.FillBytes(buf[5:])
+	return sha256.Sum256(buf)
+}
+
+// merkleNode returns the hash of the inner node with the given children
+func merkleNode(left, right [sha256.Size]byte) [sha256.Size]byte {
+	buf := make([]byte, 0, 1+2*sha256.Size)
+	buf = append(append(append(buf, 1), left[:]...), right[:]...)
+	return sha256.Sum256(buf)
+}
+
+// merkleLevels returns the levels of the tree of the message, the leaves first and the root last
+func merkleLevels(message []*big.Int) [][][sha256.Size]byte {
+	checkVector(message)
+	if n&(n-1) != 0 {
+		panic("n has to be a power of two")
+	}
+	level := make([][sha256.Size]byte, n)
+	for i, m := range message {
+		level[i] = merkleLeaf(i, m)
+	}
+	levels := [][][sha256.Size]byte{level}
+	for len(level) > 1 {
+		next := make([][sha256.Size]byte, len(level)/2)
+		for k := range next {
+			next[k] = merkleNode(level[2*k], level[2*k+1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+func (merkleScheme) Commit(message []*big.Int) []byte {
+	levels := merkleLevels(message)
+	root := levels[len(levels)-1][0]
+	return root[:]
+}
+
+func (merkleScheme) Open(message []*big.Int, index int) SchemeOpening {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	levels := merkleLevels(message)
+	var proof bytes.Buffer
+	for k, level := range levels[:len(levels)-1] {
+		sibling := level[(index>>k)^1]
+		proof.Write(sibling[:])
+	}
+	return SchemeOpening{Index: index, Value: message[index], Proof: proof.Bytes()}
+}
+
+func (merkleScheme) Verify(com []byte, opening SchemeOpening) bool {
+	index, value, proof := opening.Index, opening.Value, opening.Proof
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	depth := bits.TrailingZeros(uint(n))
+	if len(com) != sha256.Size || len(proof) != depth*sha256.Size || value == nil || value.Sign() < 0 || value.Cmp(frModulus) != -1 {
+		return false
+	}
+	node := merkleLeaf(index, value)
+	for k := 0; k < depth; k++ {
+		var sibling [sha256.Size]byte
+		copy(sibling[:], proof[k*sha256.Size:])
+		if index&1 == 0 {
+			node = merkleNode(node, sibling)
+		} else {
+			node = merkleNode(sibling, node)
+		}
+		index >>= 1
+	}
+	return bytes.Equal(node[:], com)
+}
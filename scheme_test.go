@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+// TestSchemes runs both schemes of scheme.go through the interface and hands PointProofs openings on
+// to the functions taking Opening
+func TestSchemes(t *testing.T) {
+	f := benchSetup(t)
+	for _, scheme := range []VectorCommitment{PointProofsScheme, MerkleScheme} {
+		com := scheme.Commit(f.message)
+		for _, i := range []int{0, 100, n - 1} {
+			opening := scheme.Open(f.message, i)
+			if opening.Index != i || opening.Value.Cmp(f.message[i]) != 0 {
+				t.Fatalf("%s: opening of index %d holds index %d", scheme.Name(), i, opening.Index)
+			}
+			if !scheme.Verify(com, opening) {
+				t.Fatalf("%s: opening of index %d rejected", scheme.Name(), i)
+			}
+			wrong := opening
+			wrong.Value = new(big.Int).Add(opening.Value, big.NewInt(1))
+			if scheme.Verify(com, wrong) {
+				t.Fatalf("%s: wrong value at index %d accepted", scheme.Name(), i)
+			}
+			moved := opening
+			moved.Index = (i + 1) % n
+			if scheme.Verify(com, moved) {
+				t.Fatalf("%s: opening of index %d accepted at another index", scheme.Name(), i)
+			}
+		}
+	}
+
+	var openings []Opening
+	for _, i := range f.indices {
+		o, err := PointProofsScheme.Open(f.message, i).Opening()
+		if err != nil {
+			t.Fatal(err)
+		}
+		openings = append(openings, o)
+	}
+	values := make([]*big.Int, len(openings))
+	for k, o := range openings {
+		values[k] = o.Value
+	}
+	if !VerifySameCommitment(f.com, f.indices, values, AggregateSameCommitment(f.com, openings)) {
+		t.Fatal("aggregate of scheme openings rejected")
+	}
+	if _, err := MerkleScheme.Open(f.message, 0).Opening(); err == nil {
+		t.Fatal("Merkle path decoded as a PointProofs proof")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Scratch space. The largest temporaries of the hot loops are the signed digits of the scalars of a
+	multi-scalar multiplication, 37 words per scalar at n = 1024, about 300 KB per proof, and its buckets.
+	A long lived Prover or Verifier keeps them in a scratch and reuses them from call to call, one off
+	calls pass nil and allocate as before.
+*/
+
+// scratch holds reusable temporaries, it is not safe for concurrent use
+type scratch struct {
+	// backing array of digits
+	flat   []int
+	digits [][]int
+	// backing array of buckets
+	storage []bls.PointG1
+	buckets []*bls.PointG1
+}
+
+// recode returns the signed c bit digits of every scalar the way msmG1Digits expects them, in memory
+// owned by s that stays valid until the next call
+func (s *scratch) recode(scalars []*big.Int, c int) [][]int {
+	// one extra window takes the carry of the signed recoding
+	windows := frBitLen/c + 1
+	if cap(s.flat) < len(scalars)*windows {
+		s.flat = make([]int, len(scalars)*windows)
+	}
+	if cap(s.digits) < len(scalars) {
+		s.digits = make([][]int, len(scalars))
+	}
+	s.flat = s.flat[:len(scalars)*windows]
+	s.digits = s.digits[:len(scalars)]
+	for i, x := range scalars {
+		s.digits[i] = s.flat[i*windows : (i+1)*windows]
+		signedDigits(x, c, s.digits[i])
+	}
+	return s.digits
+}
+
+// bucketsFor returns the 2^{c-1} buckets of a window of c bits, their values are left over from earlier use
+func (s *scratch) bucketsFor(c int) []*bls.PointG1 {
+	size := 1 << (c - 1)
+	if len(s.buckets) != size {
+		s.storage = make([]bls.PointG1, size)
+		s.buckets = make([]*bls.PointG1, size)
+		for j := range s.buckets {
+			s.buckets[j] = &s.storage[j]
+		}
+	}
+	return s.buckets
+}
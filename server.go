@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	HTTP/JSON API, started by "PointProofs serve". Every endpoint takes a POST with a JSON object and
+	answers with one, or with status 400 and {"error": "..."} when the request is malformed. Entries are
+	decimal or 0x prefixed hexadecimal strings as in the vector files of the CLI, commitments and proofs
+	the hexadecimal encoding of their artifacts (see artifacts.go).
+		POST /commit     {"vector": [n entries]}                   -> {"commitment": hex}
+		POST /open       {"vector": [n entries], "indices": [...]}  -> {"openings": [{"index", "value", "proof"}]}
+		POST /aggregate  {"commitment": hex, "openings": [...]}     -> {"aggregated_proof": hex}
+		POST /verify     {"commitment": hex, "indices": [...], "values": [...], "proof": hex}
+		                                                            -> {"valid": bool}
+	/aggregate uses the scalars of AggregateSameCommitment, and /verify accepts either the proof of a
+	single entry or an aggregated proof. GET /metrics serves the Prometheus metrics of metrics.go.
+	Requests are handled at the same time, the operations they run take their temporaries from the pools
+	of pool.go rather than the global engine.
+	For example
+		curl -d '{"vector": ["1", "2", ...]}' localhost:8080/commit
+*/
+
+// apiMaxBody bounds the size of a request, enough for a vector and as many openings in any encoding
+const apiMaxBody = 512 * n
+
+type apiOpening struct {
+	Index int    `json:"index"`
+	Value string `json:"value"`
+	Proof string `json:"proof"`
+}
+
+type apiRequest struct {
+	Vector     []string     `json:"vector"`
+	Indices    []int        `json:"indices"`
+	Values     []string     `json:"values"`
+	Commitment string       `json:"commitment"`
+	Proof      string       `json:"proof"`
+	Openings   []apiOpening `json:"openings"`
+}
+
+// apiVector parses a vector of n entries
+func apiVector(entries []string) []*big.Int {
+	if len(entries) != n {
+		panic(fmt.Sprintf("expected %d entries, got %d", n, len(entries)))
+	}
+	vector := make([]*big.Int, n)
+	for i, e := range entries {
+		vector[i] = apiEntry(e)
+	}
+	return vector
+}
+
+// apiEntry parses an entry
+func apiEntry(s string) *big.Int {
+	v, err := parseEntry(s)
+	if err != nil {
+		panic(err.Error())
+	}
+	return v
+}
+
+// apiIndex checks an index
+func apiIndex(i int) int {
+	if !(0 <= i && i < n) {
+		panic(fmt.Sprintf("index %d out of range", i))
+	}
+	return i
+}
+
+// apiArtifact parses the hexadecimal encoding of an artifact with decode
+func apiArtifact(s string, decode func([]byte) (*bls.PointG1, error)) *bls.PointG1 {
+	data, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		panic(err.Error())
+	}
+	p, err := decode(data)
+	if err != nil {
+		panic(err.Error())
+	}
+	return p
+}
+
+// apiDistinct panics unless the indices are distinct
+func apiDistinct(indices []int) {
+	seen := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		if seen[apiIndex(i)] {
+			panic(fmt.Sprintf("index %d given twice", i))
+		}
+		seen[i] = true
+	}
+}
+
+func apiCommit(req *apiRequest) interface{} {
+	return map[string]string{"commitment": hex.EncodeToString(encodeCommitment(commit(apiVector(req.Vector))))}
+}
+
+func apiOpen(req *apiRequest) interface{} {
+	vector := apiVector(req.Vector)
+	for _, i := range req.Indices {
+		apiIndex(i)
+	}
+	proofs := ProveSet(vector, req.Indices)
+	openings := make([]apiOpening, len(proofs))
+	for k, i := range req.Indices {
+		openings[k] = apiOpening{Index: i, Value: vector[i].String(), Proof: hex.EncodeToString(encodeProof(proofs[k]))}
+	}
+	return map[string][]apiOpening{"openings": openings}
+}
+
+func apiAggregate(req *apiRequest) interface{} {
+	com := apiArtifact(req.Commitment, decodeCommitment)
+	openings := make([]Opening, len(req.Openings))
+	indices := make([]int, len(req.Openings))
+	for k, o := range req.Openings {
+		indices[k] = o.Index
+		openings[k] = Opening{Index: o.Index, Value: apiEntry(o.Value), Proof: apiArtifact(o.Proof, decodeProof)}
+	}
+	apiDistinct(indices)
+	return map[string]string{"aggregated_proof": hex.EncodeToString(encodeAggregatedProof(AggregateSameCommitment(com, openings)))}
+}
+
+func apiVerify(req *apiRequest) interface{} {
+	com := apiArtifact(req.Commitment, decodeCommitment)
+	if len(req.Values) != len(req.Indices) {
+		panic("indices and values differ in length")
+	}
+	apiDistinct(req.Indices)
+	values := make([]*big.Int, len(req.Values))
+	for k, v := range req.Values {
+		values[k] = apiEntry(v)
+	}
+	var valid bool
+	if proof, err := hex.DecodeString(strings.TrimPrefix(req.Proof, "0x")); err == nil && len(proof) > 0 && proof[0] == artifactProof {
+		if len(values) != 1 {
+			panic("a proof of a single entry needs exactly one index")
+		}
+		valid = verifySingleProof(com, values[0], apiArtifact(req.Proof, decodeProof), req.Indices[0])
+	} else {
+		valid = VerifySameCommitment(com, req.Indices, values, apiArtifact(req.Proof, decodeAggregatedProof))
+	}
+	return map[string]bool{"valid": valid}
+}
+
+// apiFail answers with the status and {"error": msg}
+func apiFail(w http.ResponseWriter, status int, msg string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// apiEndpoint turns a handler of parsed requests into an http.HandlerFunc. The scheme and the parsers
+// above panic on malformed input, which here is the client's fault and answered with status 400
+func apiEndpoint(handle func(*apiRequest) interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost {
+			apiFail(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+		var req apiRequest
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBody))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			apiFail(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				apiFail(w, http.StatusBadRequest, fmt.Sprint(r))
+			}
+		}()
+		res := handle(&req)
+		_ = json.NewEncoder(w).Encode(res)
+	}
+}
+
+// newAPIHandler returns the handler of the endpoints above, for the installed parameters
+// newAPIServer returns the server of the API at addr. The timeouts keep slow or idle clients from
+// holding connections, a request of apiMaxBody bytes takes well under ReadTimeout on any usable link
+func newAPIServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           newAPIHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+		IdleTimeout:       2 * time.Minute,
+	}
+}
+
+func newAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/commit", apiEndpoint(apiCommit))
+	mux.Handle("/open", apiEndpoint(apiOpen))
+	mux.Handle("/aggregate", apiEndpoint(apiAggregate))
+	mux.Handle("/verify", apiEndpoint(apiVerify))
+	mux.Handle("/metrics", MetricsHandler())
+	return mux
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// apiPost posts req to the endpoint of srv and decodes the answer into res, returning the status
+func apiPost(t *testing.T, srv *httptest.Server, endpoint string, req interface{}, res interface{}) int {
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Error(err)
+		return 0
+	}
+	resp, err := srv.Client().Post(srv.URL+endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Error(err)
+		return 0
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		t.Error(err)
+	}
+	return resp.StatusCode
+}
+
+// TestAPIConcurrent verifies and aggregates from many clients at once, every valid proof has to be
+// accepted, every wrong value rejected and every aggregation has to match the one computed alone
+func TestAPIConcurrent(t *testing.T) {
+	f := benchSetup(t)
+	srv := httptest.NewServer(newAPIHandler())
+	defer srv.Close()
+	com := hex.EncodeToString(encodeCommitment(f.com))
+	aggregate := &apiRequest{Commitment: com}
+	for k, i := range f.indices {
+		aggregate.Openings = append(aggregate.Openings, apiOpening{Index: i, Value: f.values[k].String(), Proof: hex.EncodeToString(encodeProof(f.proofs[k]))})
+	}
+	want := apiAggregate(aggregate).(map[string]string)["aggregated_proof"]
+	values := make([]string, len(f.values))
+	for k, v := range f.values {
+		values[k] = v.String()
+	}
+	wrong := append([]string(nil), values...)
+	wrong[3] = "7"
+
+	var wg sync.WaitGroup
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < 10; r++ {
+				k := (w + r) % len(f.indices)
+				var verified map[string]bool
+				single := &apiRequest{Commitment: com, Indices: f.indices[k : k+1], Values: values[k : k+1], Proof: aggregate.Openings[k].Proof}
+				if status := apiPost(t, srv, "/verify", single, &verified); status != http.StatusOK || !verified["valid"] {
+					t.Errorf("proof of index %d: status %d, %v", f.indices[k], status, verified)
+				}
+				var aggregated map[string]string
+				if status := apiPost(t, srv, "/aggregate", aggregate, &aggregated); status != http.StatusOK || aggregated["aggregated_proof"] != want {
+					t.Errorf("aggregation: status %d, %v", status, aggregated)
+				}
+				verify := &apiRequest{Commitment: com, Indices: f.indices, Values: values, Proof: want}
+				if r%2 == 1 {
+					verify.Values = wrong
+				}
+				if status := apiPost(t, srv, "/verify", verify, &verified); status != http.StatusOK || verified["valid"] != (r%2 == 0) {
+					t.Errorf("aggregated proof, wrong value %v: status %d, %v", r%2 == 1, status, verified)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+func TestAPIMalformed(t *testing.T) {
+	benchSetup(t)
+	srv := httptest.NewServer(newAPIHandler())
+	defer srv.Close()
+	var res map[string]string
+	if status := apiPost(t, srv, "/commit", &apiRequest{Vector: []string{"1", "2"}}, &res); status != http.StatusBadRequest || res["error"] == "" {
+		t.Fatalf("short vector: status %d, %v", status, res)
+	}
+}
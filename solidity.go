@@ -0,0 +1,301 @@
+package main
+
+import (
+	"encoding/hex"
+	"io"
+	"math/big"
+	"math/bits"
+	"text/template"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+	"golang.org/x/crypto/sha3"
+)
+
+/*
+	Verification on Ethereum. WriteSolidityVerifier generates a contract checking single openings and
+	openings aggregated by AggregateSameCommitment with the BLS12-381 precompiles of EIP-2537 as
+	activated in Prague (G1MSM at 0x0c, G2MSM at 0x0e, PAIRING_CHECK at 0x0f), and the calldata
+	functions below encode the calls to it. Points travel in the encoding of EIP-2537, 128 bytes in G1
+	and 256 in G2. The contract checks
+		e(C, pp2[n-1-i]) * e(pi, -g2) * e((q - m_i) * pp1[0], pp2[n-1]) = 1
+	for a single opening, the equation of verifySingleProof with e(pp1[0], pp2[n-1]) = g_T^{alpha^{n+1}},
+	and for an aggregated one the same with \sum t_i pp2[n-1-i] and \sum t_i m_i. It derives the t_i
+	itself on the Keccak-256 transcript described in transcript.go, so aggregated proofs for the
+	contract are made after SetTranscriptHash(TranscriptKeccak256).
+	pp2 is far beyond the size limit of a contract, which holds the root of a Merkle tree over it
+	instead: leaf k is keccak256 of pp2[k], an inner node keccak256(left || right). The key of index i
+	passed in a call is pp2[n-1-i] followed by the siblings of its leaf from the bottom up, which the
+	contract checks against the root. The indices of an aggregated call are in increasing order.
+*/
+
+// solidityVerifierTemplate is the contract, the transcript part mirrors sameCommitmentScalars
+var solidityVerifierTemplate = template.Must(template.New("verifier").Parse(`// SPDX-License-Identifier: MIT
+// Code generated by PointProofs for n = {{.N}}. DO NOT EDIT.
+pragma solidity ^0.8.20;
+
+/// @notice Verifies openings of PointProofs commitments with the BLS12-381 precompiles of EIP-2537.
+/// Points are in the encoding of EIP-2537, keys are pp2[N-1-index] followed by their Merkle path.
+contract {{.Name}} {
+    uint256 internal constant N = {{.N}};
+    uint256 internal constant DEPTH = {{.Depth}};
+    uint256 internal constant KEY_SIZE = 256 + 32 * DEPTH;
+    // the group order and 2^256 mod Q
+    uint256 internal constant Q = 0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001;
+    uint256 internal constant R = 0x1824b159acc5056f998c4fefecbc4ff55884b7fa0003480200000001fffffffe;
+    bytes32 internal constant PP2_ROOT = 0x{{.Root}};
+
+    address internal constant G1_MSM = address(0x0c);
+    address internal constant G2_MSM = address(0x0e);
+    address internal constant PAIRING_CHECK = address(0x0f);
+
+    // pp1[0] = g1^alpha, pp2[N-1] = g2^(alpha^N) and -g2
+    bytes internal constant PP1_FIRST = hex"{{.PP1First}}";
+    bytes internal constant PP2_LAST = hex"{{.PP2Last}}";
+    bytes internal constant NEG_G2 = hex"{{.NegG2}}";
+
+    /// @notice Reports whether proof opens com to value at index
+    function verify(bytes calldata com, uint256 index, uint256 value, bytes calldata proof, bytes calldata key) external view returns (bool) {
+        require(index < N && value < Q, "out of range");
+        checkKey(index, key);
+        return check(com, bytes(key[:256]), value, proof);
+    }
+
+    /// @notice Reports whether the aggregated proof opens com to values at indices, in increasing order
+    function verifyAggregated(bytes calldata com, uint256[] calldata indices, uint256[] calldata values, bytes calldata proof, bytes[] calldata keys) external view returns (bool) {
+        require(indices.length > 0 && values.length == indices.length && keys.length == indices.length, "lengths differ");
+        for (uint256 k = 0; k < indices.length; k++) {
+            require(indices[k] < N && values[k] < Q, "out of range");
+            require(k == 0 || indices[k - 1] < indices[k], "indices not increasing");
+            checkKey(indices[k], keys[k]);
+        }
+        uint256[] memory t = challenges(com, indices, values);
+        uint256 x = 0;
+        bytes memory terms;
+        for (uint256 k = 0; k < t.length; k++) {
+            x = addmod(x, mulmod(t[k], values[k], Q), Q);
+            terms = bytes.concat(terms, keys[k][:256], bytes32(t[k]));
+        }
+        return check(com, msm(G2_MSM, terms, 256), x, proof);
+    }
+
+    function checkKey(uint256 index, bytes calldata key) internal pure {
+        require(key.length == KEY_SIZE, "bad key");
+        bytes32 node = keccak256(key[:256]);
+        uint256 position = N - 1 - index;
+        for (uint256 d = 0; d < DEPTH; d++) {
+            bytes32 sibling = bytes32(key[256 + 32 * d:288 + 32 * d]);
+            if ((position >> d) & 1 == 0) {
+                node = keccak256(abi.encodePacked(node, sibling));
+            } else {
+                node = keccak256(abi.encodePacked(sibling, node));
+            }
+        }
+        require(node == PP2_ROOT, "key not in pp2");
+    }
+
+    function check(bytes calldata com, bytes memory key, uint256 value, bytes calldata proof) internal view returns (bool) {
+        require(com.length == 128 && proof.length == 128, "bad point");
+        bytes memory shifted = msm(G1_MSM, bytes.concat(PP1_FIRST, bytes32((Q - value) % Q)), 128);
+        (bool ok, bytes memory out) = PAIRING_CHECK.staticcall(bytes.concat(com, key, proof, NEG_G2, shifted, PP2_LAST));
+        return ok && out.length == 32 && uint256(bytes32(out)) == 1;
+    }
+
+    function msm(address precompile, bytes memory input, uint256 size) internal view returns (bytes memory) {
+        (bool ok, bytes memory out) = precompile.staticcall(input);
+        require(ok && out.length == size, "MSM failed");
+        return out;
+    }
+
+    function challenges(bytes calldata com, uint256[] calldata indices, uint256[] calldata values) internal pure returns (uint256[] memory t) {
+        require(com.length == 128, "bad point");
+        bytes memory state = abi.encodePacked(bytes("PointProofs-transcript-v1"), uint32(10), bytes("Keccak-256"), uint32({{.DomainLength}}), bytes("{{.Domain}}"));
+        state = absorb(state, "C", bytes.concat(com[16:64], com[80:128]));
+        state = absorb(state, "|S|", abi.encodePacked(uint32(indices.length)));
+        for (uint256 k = 0; k < indices.length; k++) {
+            state = absorb(state, "i", abi.encodePacked(uint32(indices[k])));
+            state = absorb(state, "m_i", abi.encodePacked(values[k]));
+        }
+        t = new uint256[](indices.length);
+        for (uint256 k = 0; k < t.length; k++) {
+            (state, t[k]) = challenge(state, "t");
+        }
+    }
+
+    function absorb(bytes memory state, bytes memory label, bytes memory data) internal pure returns (bytes memory) {
+        return abi.encodePacked(state, uint8(1), uint32(label.length), label, uint32(data.length), data);
+    }
+
+    function challenge(bytes memory state, bytes memory label) internal pure returns (bytes memory, uint256) {
+        state = abi.encodePacked(state, uint8(2), uint32(label.length), label);
+        bytes32 d = keccak256(state);
+        uint256 hi = uint256(keccak256(abi.encodePacked(d, uint8(0))));
+        uint256 lo = uint256(keccak256(abi.encodePacked(d, uint8(1))));
+        return (state, addmod(mulmod(hi, R, Q), lo, Q));
+    }
+}
+`))
+
+// eip2537G1 returns p in the 128 byte encoding of EIP-2537
+func eip2537G1(p *bls.PointG1) []byte {
+	g := getG1()
+	defer putG1(g)
+	return g.EncodePoint(p)
+}
+
+// eip2537G2 returns p in the 256 byte encoding of EIP-2537
+func eip2537G2(p *bls.PointG2) []byte {
+	g := getG2()
+	defer putG2(g)
+	return g.EncodePoint(p)
+}
+
+// keccak256 returns the Keccak-256 digest of the concatenated data
+func keccak256(data ...[]byte) [32]byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		h.Write(d)
+	}
+	var res [32]byte
+	copy(res[:], h.Sum(nil))
+	return res
+}
+
+// pp2Tree returns the levels of the Merkle tree over pp2, the leaves first and the root last
+func pp2Tree() [][][32]byte {
+	if n&(n-1) != 0 {
+		panic("n has to be a power of two")
+	}
+	level := make([][32]byte, n)
+	for k := range level {
+		level[k] = keccak256(eip2537G2(pp2[k]))
+	}
+	levels := [][][32]byte{level}
+	for len(level) > 1 {
+		next := make([][32]byte, len(level)/2)
+		for k := range next {
+			next[k] = keccak256(level[2*k][:], level[2*k+1][:])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// solidityKey returns the key of the index the contract takes: pp2[n-1-index] and its Merkle path
+func solidityKey(levels [][][32]byte, index int) []byte {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	position := n - 1 - index
+	key := eip2537G2(pp2[position])
+	for _, level := range levels[:len(levels)-1] {
+		key = append(key, level[position^1][:]...)
+		position >>= 1
+	}
+	return key
+}
+
+/*
+	It takes the following arguments:
+		1. pp1 and pp2 (implicitly)
+		2. the writer of the Solidity source
+		3. the name of the contract
+	It writes the verifier contract for the installed parameters, see above.
+*/
+func WriteSolidityVerifier(w io.Writer, name string) error {
+	levels := pp2Tree()
+	root := levels[len(levels)-1][0]
+	g := getG2()
+	defer putG2(g)
+	return solidityVerifierTemplate.Execute(w, map[string]interface{}{
+		"Name":         name,
+		"N":            n,
+		"Depth":        bits.TrailingZeros(uint(n)),
+		"Root":         hex.EncodeToString(root[:]),
+		"PP1First":     hex.EncodeToString(eip2537G1(pp1Point(0))),
+		"PP2Last":      hex.EncodeToString(eip2537G2(pp2[n-1])),
+		"NegG2":        hex.EncodeToString(eip2537G2(g.Neg(g.New(), g2Generator))),
+		"Domain":       sameCommitmentDomain,
+		"DomainLength": len(sameCommitmentDomain),
+	})
+}
+
+// abiItem is a value in the ABI encoding of Solidity, dynamic values are placed after the heads
+type abiItem struct {
+	data    []byte
+	dynamic bool
+}
+
+// abiUint returns the encoding of a uint256
+func abiUint(x *big.Int) abiItem {
+	return abiItem{data: x.FillBytes(make([]byte, 32))}
+}
+
+// abiBytes returns the encoding of bytes
+func abiBytes(b []byte) abiItem {
+	data := abiUint(big.NewInt(int64(len(b)))).data
+	data = append(data, b...)
+	data = append(data, make([]byte, (32-len(b)%32)%32)...)
+	return abiItem{data: data, dynamic: true}
+}
+
+// abiArray returns the encoding of a dynamic array of the items
+func abiArray(items []abiItem) abiItem {
+	return abiItem{data: append(abiUint(big.NewInt(int64(len(items)))).data, abiTuple(items)...), dynamic: true}
+}
+
+// abiTuple returns the encoding of the items one after the other: the static ones in place, the
+// dynamic ones by their offsets and after all heads
+func abiTuple(items []abiItem) []byte {
+	size := 0
+	for _, item := range items {
+		if item.dynamic {
+			size += 32
+		} else {
+			size += len(item.data)
+		}
+	}
+	head := make([]byte, 0, size)
+	var tail []byte
+	for _, item := range items {
+		if item.dynamic {
+			head = append(head, abiUint(big.NewInt(int64(size+len(tail)))).data...)
+			tail = append(tail, item.data...)
+		} else {
+			head = append(head, item.data...)
+		}
+	}
+	return append(head, tail...)
+}
+
+// abiCall returns the calldata of the function with the signature and the arguments
+func abiCall(signature string, args ...abiItem) []byte {
+	selector := keccak256([]byte(signature))
+	return append(selector[:4:4], abiTuple(args)...)
+}
+
+// SolidityVerifyCalldata returns the calldata of verify in the contract of WriteSolidityVerifier
+func SolidityVerifyCalldata(com *bls.PointG1, index int, value *big.Int, proof *bls.PointG1) []byte {
+	key := solidityKey(pp2Tree(), index)
+	return abiCall("verify(bytes,uint256,uint256,bytes,bytes)",
+		abiBytes(eip2537G1(com)), abiUint(big.NewInt(int64(index))), abiUint(value), abiBytes(eip2537G1(proof)), abiBytes(key))
+}
+
+// SolidityVerifyAggregatedCalldata returns the calldata of verifyAggregated in the contract of
+// WriteSolidityVerifier for a proof of AggregateSameCommitment on the Keccak-256 transcript, the
+// indices may be in any order
+func SolidityVerifyAggregatedCalldata(com *bls.PointG1, indices []int, values []*big.Int, proof *bls.PointG1) []byte {
+	checkOpened(indices, values)
+	order := indexOrder(indices)
+	levels := pp2Tree()
+	sortedIndices := make([]abiItem, len(order))
+	sortedValues := make([]abiItem, len(order))
+	keys := make([]abiItem, len(order))
+	for k, pos := range order {
+		sortedIndices[k] = abiUint(big.NewInt(int64(indices[pos])))
+		sortedValues[k] = abiUint(values[pos])
+		keys[k] = abiBytes(solidityKey(levels, indices[pos]))
+	}
+	return abiCall("verifyAggregated(bytes,uint256[],uint256[],bytes,bytes[])",
+		abiBytes(eip2537G1(com)), abiArray(sortedIndices), abiArray(sortedValues), abiBytes(eip2537G1(proof)), abiArray(keys))
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+/*
+	The Store over database/sql, written for PostgreSQL, so a proving service keeps its vectors in a
+	managed database and several replicas share them. The caller opens the database with the driver of
+	its choice, e.g. sql.Open("pgx", url) with github.com/jackc/pgx/v5/stdlib, creates the tables once
+	with CreateSQLStoreSchema and gets a Store per vector from NewSQLStore, vectors being told apart by
+	their names. Each Apply runs in a transaction and updates the version of the vector only if it is
+	still the one the change is based on, the other replicas get ErrStoreConflict. Cached proofs only
+	ever move to later versions. The queries use $n placeholders, which is all that ties them to
+	PostgreSQL, the schema is sqlStoreSchema.
+*/
+
+// sqlStoreSchema creates the tables, bytes are stored as in the Store
+const sqlStoreSchema = `
+CREATE TABLE IF NOT EXISTS pp_vectors (
+	name       TEXT PRIMARY KEY,
+	version    BIGINT NOT NULL,
+	commitment BYTEA NOT NULL
+);
+CREATE TABLE IF NOT EXISTS pp_entries (
+	name  TEXT NOT NULL REFERENCES pp_vectors (name) ON DELETE CASCADE,
+	idx   INTEGER NOT NULL,
+	value BYTEA NOT NULL,
+	PRIMARY KEY (name, idx)
+);
+CREATE TABLE IF NOT EXISTS pp_proofs (
+	name    TEXT NOT NULL REFERENCES pp_vectors (name) ON DELETE CASCADE,
+	idx     INTEGER NOT NULL,
+	version BIGINT NOT NULL,
+	proof   BYTEA NOT NULL,
+	PRIMARY KEY (name, idx)
+);
+`
+
+// CreateSQLStoreSchema creates the tables of the SQL stores unless they exist
+func CreateSQLStoreSchema(db *sql.DB) error {
+	_, err := db.Exec(sqlStoreSchema)
+	return err
+}
+
+// sqlStore is the Store of the vector with the given name in a SQL database
+type sqlStore struct {
+	db   *sql.DB
+	name string
+}
+
+// NewSQLStore returns the Store of the vector with the given name in db. Closing the Store leaves db
+// open, it usually holds other vectors as well
+func NewSQLStore(db *sql.DB, name string) Store {
+	return &sqlStore{db: db, name: name}
+}
+
+func (s *sqlStore) Load() (*StoreState, error) {
+	// the vector and its entries have to come from the same snapshot
+	tx, err := s.db.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	state := &StoreState{Entries: make(map[int][]byte)}
+	err = tx.QueryRow(`SELECT version, commitment FROM pp_vectors WHERE name = $1`, s.name).Scan(&state.Version, &state.Commitment)
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(`SELECT idx, value FROM pp_entries WHERE name = $1`, s.name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var index int
+		var value []byte
+		if err := rows.Scan(&index, &value); err != nil {
+			return nil, err
+		}
+		state.Entries[index] = value
+	}
+	return state, rows.Err()
+}
+
+func (s *sqlStore) Apply(change *StoreChange) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var res sql.Result
+	if change.Version == 1 {
+		res, err = tx.Exec(`INSERT INTO pp_vectors (name, version, commitment) VALUES ($1, $2, $3) ON CONFLICT (name) DO NOTHING`,
+			s.name, change.Version, change.Commitment)
+	} else {
+		res, err = tx.Exec(`UPDATE pp_vectors SET version = $2, commitment = $3 WHERE name = $1 AND version = $4`,
+			s.name, change.Version, change.Commitment, change.Version-1)
+	}
+	if err != nil {
+		return err
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return err
+	} else if rows != 1 {
+		return ErrStoreConflict
+	}
+	if change.Entry == nil {
+		_, err = tx.Exec(`DELETE FROM pp_entries WHERE name = $1 AND idx = $2`, s.name, change.Index)
+	} else {
+		_, err = tx.Exec(`INSERT INTO pp_entries (name, idx, value) VALUES ($1, $2, $3)
+			ON CONFLICT (name, idx) DO UPDATE SET value = EXCLUDED.value`, s.name, change.Index, change.Entry)
+	}
+	if err != nil {
+		return err
+	}
+	if change.Proof != nil {
+		if err := sqlStorePutProof(tx, s.name, change.Index, change.Version, change.Proof); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *sqlStore) Proof(index int) (uint64, []byte, error) {
+	var version uint64
+	var proof []byte
+	err := s.db.QueryRow(`SELECT version, proof FROM pp_proofs WHERE name = $1 AND idx = $2`, s.name, index).Scan(&version, &proof)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil, nil
+	}
+	return version, proof, err
+}
+
+func (s *sqlStore) PutProof(index int, version uint64, proof []byte) error {
+	return sqlStorePutProof(s.db, s.name, index, version, proof)
+}
+
+// sqlExecer is a *sql.DB or a *sql.Tx
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// sqlStorePutProof stores the proof unless one of a later version is there already. Proofs of version 0,
+// the zero vector that was never changed and has no row in pp_vectors, are not cached
+func sqlStorePutProof(db sqlExecer, name string, index int, version uint64, proof []byte) error {
+	if version == 0 {
+		return nil
+	}
+	_, err := db.Exec(`INSERT INTO pp_proofs (name, idx, version, proof) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (name, idx) DO UPDATE SET version = EXCLUDED.version, proof = EXCLUDED.proof
+		WHERE pp_proofs.version < EXCLUDED.version`, name, index, version, proof)
+	return err
+}
+
+func (s *sqlStore) Close() error {
+	return nil
+}
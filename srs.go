@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// multiExpG1 returns \sum scalars[i] * points[i], the scalars have to be reduced modulo the group order
+func multiExpG1(g *bls.G1, points []*bls.PointG1, scalars []*big.Int) *bls.PointG1 {
+	// MultiExp consumes the scalar slice, so it gets a copy
+	s := make([]*big.Int, len(scalars))
+	copy(s, scalars)
+	res, err := g.MultiExp(g.New(), points, s)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// multiExpG2 returns \sum scalars[i] * points[i], the scalars have to be reduced modulo the group order
+func multiExpG2(g *bls.G2, points []*bls.PointG2, scalars []*big.Int) *bls.PointG2 {
+	s := make([]*big.Int, len(scalars))
+	copy(s, scalars)
+	res, err := g.MultiExp(g.New(), points, s)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// pairingsEqual checks e(a1, b1) = e(a2, b2) using a single final exponentiation
+func pairingsEqual(e *bls.Engine, a1 *bls.PointG1, b1 *bls.PointG2, a2 *bls.PointG1, b2 *bls.PointG2) bool {
+	// AddPairInv negates its G1 argument in place
+	neg := e.G1.New().Set(a2)
+	e.AddPair(a1, b1)
+	e.AddPairInv(neg, b2)
+	ok := e.Check()
+	e.Reset()
+	return ok
+}
+
+/*
+	It checks that pp1 and pp2 are powers of one and the same non-zero alpha:
+		1. pp1[n] is zero, pp1[0] and pp2[0] are not
+		2. e(pp1[k + 1], g2) = e(pp1[k], pp2[0]) for consecutive powers stored in pp1
+		3. e(pp1[n + 1], g2) = e(pp1[n - 1], pp2[1]) bridging the missing g1^{alpha^{n + 1}}
+		4. e(pp1[k], g2) = e(g1, pp2[k]) for 0 <= k < n
+	Relations 2 and 4 are folded into a single pairing equation each using random coefficients,
+	so a forged parameter set passes with negligible probability. Points are assumed to lie in
+	the correct subgroups, see checkSubgroups.
+*/
+func checkPowers(e *bls.Engine, pp1 [2 * n]*bls.PointG1, pp2 [n]*bls.PointG2) error {
+	if !e.G1.IsZero(pp1[n]) {
+		return fmt.Errorf("pp1[%d] must be the point at infinity", n)
+	}
+	if e.G1.IsZero(pp1[0]) || e.G2.IsZero(pp2[0]) {
+		return errors.New("degenerate parameters, alpha is zero")
+	}
+	// pairs (pp1[k], pp1[k + 1]) which do not touch the hole at index n
+	var lo, hi []*bls.PointG1
+	for k := 0; k < 2*n-1; k++ {
+		if k == n-1 || k == n {
+			continue
+		}
+		lo = append(lo, pp1[k])
+		hi = append(hi, pp1[k+1])
+	}
+	r := generateBigIntegerArray(len(lo), e.G1.Q())
+	if !pairingsEqual(e, multiExpG1(e.G1, hi, r), e.G2.One(), multiExpG1(e.G1, lo, r), pp2[0]) {
+		return errors.New("pp1 is not a sequence of consecutive powers")
+	}
+	if !pairingsEqual(e, pp1[n+1], e.G2.One(), pp1[n-1], pp2[1]) {
+		return fmt.Errorf("pp1[%d] is not consistent with pp1[%d]", n+1, n-1)
+	}
+	u := generateBigIntegerArray(n, e.G1.Q())
+	if !pairingsEqual(e, multiExpG1(e.G1, pp1[:n], u), e.G2.One(), e.G1.One(), multiExpG2(e.G2, pp2[:], u)) {
+		return errors.New("pp2 does not match pp1")
+	}
+	return nil
+}
+
+// checkSubgroups makes sure every point of the parameters lies in the prime order subgroup
+func checkSubgroups(e *bls.Engine, pp1 [2 * n]*bls.PointG1, pp2 [n]*bls.PointG2) error {
+	for i := 0; i < 2*n; i++ {
+		if !e.G1.InCorrectSubgroup(pp1[i]) {
+			return fmt.Errorf("pp1[%d] is not in the correct subgroup", i)
+		}
+	}
+	for i := 0; i < n; i++ {
+		if !e.G2.InCorrectSubgroup(pp2[i]) {
+			return fmt.Errorf("pp2[%d] is not in the correct subgroup", i)
+		}
+	}
+	return nil
+}
+
+// checkParams checks parameters read from a file lie in the correct subgroups and are well-formed
+// powers, including their extended G2 powers, before they get installed
+func checkParams(pp *PublicParams) error {
+	e := bls.NewPairingEngine()
+	// the pairing checks below only mean something on the prime order subgroups
+	if err := checkSubgroups(e, pp.PP1, pp.PP2); err != nil {
+		return err
+	}
+	if err := checkExtendedSubgroup(e, pp.PP2Ext); err != nil {
+		return err
+	}
+	if err := checkPowers(e, pp.PP1, pp.PP2); err != nil {
+		return err
+	}
+	return checkExtendedG2(e, pp.PP1, pp.PP2Ext)
+}
+
+// checkExtendedSubgroup makes sure every point of PP2Ext lies in the prime order subgroup, a nil ext passes
+func checkExtendedSubgroup(e *bls.Engine, ext []*bls.PointG2) error {
+	for i, p := range ext {
+		if !e.G2.InCorrectSubgroup(p) {
+			return fmt.Errorf("PP2Ext[%d] is not in the correct subgroup", i)
+		}
+	}
+	return nil
+}
+
+// checkExtendedG2 checks e(pp1[k], g2) = e(g1, ext[k - n]) for n < k < 2n and that ext[0] is zero,
+// which together with checkPowers shows ext holds the powers n + 1 < i <= 2n of alpha. A nil ext passes.
+func checkExtendedG2(e *bls.Engine, pp1 [2 * n]*bls.PointG1, ext []*bls.PointG2) error {
+	if ext == nil {
+		return nil
+	}
+	if len(ext) != n {
+		return errors.New("the extended G2 powers must hold n points")
+	}
+	if !e.G2.IsZero(ext[0]) {
+		return errors.New("pp2ext[0] must be the point at infinity")
+	}
+	u := generateBigIntegerArray(n-1, e.G1.Q())
+	if !pairingsEqual(e, multiExpG1(e.G1, pp1[n+1:], u), e.G2.One(), e.G1.One(), multiExpG2(e.G2, ext[1:], u)) {
+		return errors.New("the extended G2 powers do not match pp1")
+	}
+	return nil
+}
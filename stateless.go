@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Stateless accounts, the use case of the paper. Account a holds its balance at entry a mod n of shard
+	a / n, and validators keep only the commitments to the shards. A transaction carries the balance of
+	its sender with the proof of it, the block producer aggregates the openings of all senders across
+	the shards into one proof, and a validator checks the block with VerifyCrossCommitment before
+	applying the transfers to the commitments, one update of each per changed entry. Every account owner
+	keeps the proof of their balance current from the blocks (Wallet.ApplyBlock), so nobody but the
+	producer handles more than one proof per block.
+	Balances are field elements. A validator checks a sender can afford the transfer, but never sees
+	the receiver's balance, so the total supply has to stay below the group order for balances not to
+	wrap around.
+*/
+
+// Transaction moves Amount from account From to account To
+type Transaction struct {
+	From, To int
+	Amount   *big.Int
+	// Balance is the sender's balance before the block, Proof its proof
+	Balance *big.Int
+	Proof   *bls.PointG1
+}
+
+// Block holds the transactions, whose proofs it does not need, and the aggregated proof of the balances
+type Block struct {
+	Transactions []Transaction
+	Proof        *bls.PointG1
+}
+
+// accountPosition returns the shard and the index of the account
+func accountPosition(account int) (int, int) {
+	return account / n, account % n
+}
+
+// Wallet is what an account owner keeps: the balance and its proof
+type Wallet struct {
+	Account int
+	Balance *big.Int
+	Proof   *bls.PointG1
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the balances of the accounts, account a being balances[a]
+	It returns the commitments to the shards and the wallets of all accounts.
+*/
+func GenesisAccounts(balances []*big.Int) ([]*bls.PointG1, []*Wallet) {
+	shards := make([][]*big.Int, (len(balances)+n-1)/n)
+	for s := range shards {
+		shards[s] = make([]*big.Int, n)
+		for i := range shards[s] {
+			shards[s][i] = new(big.Int)
+		}
+	}
+	for a, b := range balances {
+		s, i := accountPosition(a)
+		shards[s][i] = new(big.Int).Set(b)
+	}
+	coms := CommitMany(shards)
+	wallets := make([]*Wallet, len(balances))
+	for s, shard := range shards {
+		proofs := ProveAll(shard)
+		for i := 0; i < n && s*n+i < len(balances); i++ {
+			wallets[s*n+i] = &Wallet{Account: s*n + i, Balance: new(big.Int).Set(shard[i]), Proof: proofs[i]}
+		}
+	}
+	return coms, wallets
+}
+
+// Pay returns the transaction of the amount to the account, it does not change the wallet
+func (w *Wallet) Pay(to int, amount *big.Int) Transaction {
+	return Transaction{From: w.Account, To: to, Amount: new(big.Int).Set(amount), Balance: new(big.Int).Set(w.Balance), Proof: new(bls.PointG1).Set(w.Proof)}
+}
+
+// blockOpenings returns the shards with senders in increasing order and for each of them the indices
+// and balances of the senders, it fails unless every transaction is well-formed and every sender distinct
+func blockOpenings(txs []Transaction, shards int) ([]int, [][]int, [][]*big.Int, error) {
+	bySender := make(map[int]int, len(txs))
+	for k, tx := range txs {
+		if !(0 <= tx.From && tx.From < shards*n && 0 <= tx.To && tx.To < shards*n) {
+			return nil, nil, nil, fmt.Errorf("transaction %d: account out of range", k)
+		}
+		if tx.Amount == nil || tx.Amount.Sign() <= 0 || tx.Balance == nil || tx.Balance.Cmp(tx.Amount) < 0 || tx.Balance.Cmp(frModulus) >= 0 {
+			return nil, nil, nil, fmt.Errorf("transaction %d: the sender cannot afford the amount", k)
+		}
+		if _, ok := bySender[tx.From]; ok {
+			return nil, nil, nil, fmt.Errorf("transaction %d: account %d sends twice", k, tx.From)
+		}
+		bySender[tx.From] = k
+	}
+	senders := make([]int, 0, len(bySender))
+	for a := range bySender {
+		senders = append(senders, a)
+	}
+	sort.Ints(senders)
+	var used []int
+	var indices [][]int
+	var values [][]*big.Int
+	for _, a := range senders {
+		s, i := accountPosition(a)
+		if len(used) == 0 || used[len(used)-1] != s {
+			used = append(used, s)
+			indices = append(indices, nil)
+			values = append(values, nil)
+		}
+		last := len(used) - 1
+		indices[last] = append(indices[last], i)
+		values[last] = append(values[last], txs[bySender[a]].Balance)
+	}
+	return used, indices, values, nil
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the commitments to the shards
+		4. the transactions
+	It returns the block of the transactions, or an error if one of them is malformed or its proof does
+	not hold, so that the producer never puts out a block validators reject.
+*/
+func ProduceBlock(coms []*bls.PointG1, txs []Transaction) (*Block, error) {
+	used, indices, values, err := blockOpenings(txs, len(coms))
+	if err != nil {
+		return nil, err
+	}
+	proofs := make(map[int]*bls.PointG1, len(txs))
+	for k, tx := range txs {
+		s, i := accountPosition(tx.From)
+		if !verifySingleProof(coms[s], tx.Balance, tx.Proof, i) {
+			return nil, fmt.Errorf("transaction %d: invalid proof of the sender's balance", k)
+		}
+		proofs[tx.From] = tx.Proof
+	}
+	groups := make([]CommitmentOpenings, len(used))
+	for g, s := range used {
+		groups[g].Commitment = coms[s]
+		for k, i := range indices[g] {
+			groups[g].Openings = append(groups[g].Openings, Opening{Index: i, Value: values[g][k], Proof: proofs[s*n+i]})
+		}
+	}
+	block := &Block{Transactions: make([]Transaction, len(txs)), Proof: AggregateCrossCommitment(groups)}
+	for k, tx := range txs {
+		block.Transactions[k] = Transaction{From: tx.From, To: tx.To, Amount: tx.Amount, Balance: tx.Balance}
+	}
+	return block, nil
+}
+
+// blockUpdates returns the changes of the entries of every shard the block makes
+func blockUpdates(b *Block) map[int][]Update {
+	updates := make(map[int][]Update)
+	for _, tx := range b.Transactions {
+		s, i := accountPosition(tx.From)
+		updates[s] = append(updates[s], Update{Index: i, Delta: new(big.Int).Neg(tx.Amount)})
+		s, i = accountPosition(tx.To)
+		updates[s] = append(updates[s], Update{Index: i, Delta: tx.Amount})
+	}
+	return updates
+}
+
+// Validator keeps the commitments to the shards, and nothing else of the state
+type Validator struct {
+	coms []*bls.PointG1
+}
+
+// NewValidator returns a validator starting from the commitments to the shards
+func NewValidator(coms []*bls.PointG1) *Validator {
+	v := &Validator{coms: make([]*bls.PointG1, len(coms))}
+	for s, com := range coms {
+		v.coms[s] = new(bls.PointG1).Set(com)
+	}
+	return v
+}
+
+// Commitments returns the commitments to the shards
+func (v *Validator) Commitments() []*bls.PointG1 {
+	res := make([]*bls.PointG1, len(v.coms))
+	for s, com := range v.coms {
+		res[s] = new(bls.PointG1).Set(com)
+	}
+	return res
+}
+
+// ApplyBlock checks the block and applies it to the commitments, it changes nothing when the block is rejected
+func (v *Validator) ApplyBlock(b *Block) error {
+	used, indices, values, err := blockOpenings(b.Transactions, len(v.coms))
+	if err != nil {
+		return err
+	}
+	if len(used) > 0 {
+		coms := make([]*bls.PointG1, len(used))
+		for g, s := range used {
+			coms[g] = v.coms[s]
+		}
+		if !VerifyCrossCommitment(coms, indices, values, b.Proof) {
+			return errors.New("invalid proof of the senders' balances")
+		}
+	}
+	for s, updates := range blockUpdates(b) {
+		ApplyUpdates((*Commitment)(v.coms[s]), updates)
+	}
+	return nil
+}
+
+// ApplyBlock brings the balance and its proof up to date with a block validators accepted
+func (w *Wallet) ApplyBlock(b *Block) {
+	shard, index := accountPosition(w.Account)
+	for s, updates := range blockUpdates(b) {
+		if s != shard {
+			continue
+		}
+		for _, u := range updates {
+			if u.Index == index {
+				w.Balance.Add(w.Balance, u.Delta)
+			}
+		}
+		(*Proof)(w.Proof).ApplyUpdates(index, updates)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// statelessGenesis returns two shards of accounts holding 1000 each
+func statelessGenesis(t *testing.T) ([]*bls.PointG1, []*Wallet, []*big.Int) {
+	benchSetup(t)
+	balances := make([]*big.Int, 2*n)
+	for a := range balances {
+		balances[a] = big.NewInt(1000)
+	}
+	coms, wallets := GenesisAccounts(balances)
+	return coms, wallets, balances
+}
+
+// TestStatelessBlock applies a block across both shards and checks validators and wallets follow it
+func TestStatelessBlock(t *testing.T) {
+	coms, wallets, balances := statelessGenesis(t)
+	txs := []Transaction{
+		wallets[1].Pay(n+5, big.NewInt(300)),
+		wallets[n+5].Pay(2, big.NewInt(1000)),
+		wallets[n+7].Pay(1, big.NewInt(50)),
+	}
+	block, err := ProduceBlock(coms, txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewValidator(coms)
+	if err := v.ApplyBlock(block); err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range txs {
+		balances[tx.From] = new(big.Int).Sub(balances[tx.From], tx.Amount)
+		balances[tx.To] = new(big.Int).Add(balances[tx.To], tx.Amount)
+	}
+	g := getG1()
+	defer putG1(g)
+	for s, com := range v.Commitments() {
+		if !g.Equal(com, commit(balances[s*n:(s+1)*n])) {
+			t.Fatalf("commitment to shard %d does not match the balances", s)
+		}
+	}
+
+	// senders, receivers, both, and bystanders in either shard
+	for _, a := range []int{1, n + 5, n + 7, 2, 0, 3, n, 2*n - 1} {
+		w := wallets[a]
+		w.ApplyBlock(block)
+		if w.Balance.Cmp(balances[a]) != 0 {
+			t.Fatalf("wallet %d holds %v, the account %v", a, w.Balance, balances[a])
+		}
+		s, i := accountPosition(a)
+		if !verifySingleProof(v.Commitments()[s], w.Balance, w.Proof, i) {
+			t.Fatalf("proof of wallet %d rejected after the block", a)
+		}
+	}
+}
+
+// TestStatelessBlockRejects checks producers and validators turn down wrong balances, overspending and
+// senders appearing twice, and that a rejected block changes nothing
+func TestStatelessBlockRejects(t *testing.T) {
+	coms, wallets, _ := statelessGenesis(t)
+	valid := []Transaction{wallets[1].Pay(n+5, big.NewInt(300)), wallets[n+7].Pay(1, big.NewInt(50))}
+	block, err := ProduceBlock(coms, valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrongBalance := wallets[1].Pay(n+5, big.NewInt(300))
+	wrongBalance.Balance = big.NewInt(2000)
+	overspend := wallets[1].Pay(n+5, big.NewInt(1001))
+	cases := map[string][]Transaction{
+		"wrong balance":    {wrongBalance, valid[1]},
+		"overspend":        {overspend, valid[1]},
+		"duplicate sender": {valid[0], valid[1], wallets[1].Pay(2, big.NewInt(1))},
+	}
+	for name, txs := range cases {
+		if _, err := ProduceBlock(coms, txs); err == nil {
+			t.Fatalf("%s: block produced", name)
+		}
+		// a producer skipping the checks hands out the valid proof with the forged transactions
+		forged := &Block{Transactions: txs, Proof: block.Proof}
+		v := NewValidator(coms)
+		if err := v.ApplyBlock(forged); err == nil {
+			t.Fatalf("%s: block accepted", name)
+		}
+		g := getG1()
+		for s, com := range v.Commitments() {
+			if !g.Equal(com, coms[s]) {
+				t.Fatalf("%s: rejected block changed shard %d", name, s)
+			}
+		}
+		putG1(g)
+	}
+	if err := NewValidator(coms).ApplyBlock(block); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+/*
+	Storage of a VectorStore. A Store keeps one vector as its version, the artifact of its commitment, its
+	nonzero entries and cached proofs stamped with the version they were computed for, all as bytes, so
+	an adapter only moves them in and out of a database: NewKVStore over an ethdb.KeyValueStore (LevelDB
+	or memory) and NewSQLStore over database/sql, see sqlstore.go. Every change of the vector reaches the
+	Store as one StoreChange, which it applies atomically. A Store shared by several processes, e.g. a
+	managed database behind replicas of a service, rejects a change made on top of an old version with
+	ErrStoreConflict, after which the VectorStore has to be reopened.
+	The ethdb keys are
+		"v"                    the version, 8 bytes big endian
+		"c"                    the commitment artifact
+		"m" || index           the entry, 32 bytes big endian
+		"p" || index           version || the proof artifact
+	with indices 4 bytes big endian.
+*/
+
+// ErrStoreConflict is returned by Store.Apply when the vector changed since the version the change is based on
+var ErrStoreConflict = errors.New("the vector was changed concurrently")
+
+// StoreState is the state of a vector kept by a Store
+type StoreState struct {
+	// Version is 0 and Commitment nil for the zero vector that was never changed
+	Version    uint64
+	Commitment []byte
+	// Entries holds the nonzero entries as 32 bytes big endian
+	Entries map[int][]byte
+}
+
+// StoreChange is a change of one entry, which moves the vector from version Version - 1 to Version
+type StoreChange struct {
+	Version    uint64
+	Commitment []byte
+	Index      int
+	// Entry is the new entry, nil for zero
+	Entry []byte
+	// Proof is the proof of Index, which stays valid and moves to Version, or nil if none was cached
+	Proof []byte
+}
+
+// Store keeps the state of one vector. VectorStore serializes its calls
+type Store interface {
+	// Load returns the state of the vector
+	Load() (*StoreState, error)
+	// Apply applies the change atomically
+	Apply(change *StoreChange) error
+	// Proof returns the cached proof of the index and its version, or a nil proof if there is none
+	Proof(index int) (uint64, []byte, error)
+	// PutProof caches the proof of the index for the version
+	PutProof(index int, version uint64, proof []byte) error
+	Close() error
+}
+
+var (
+	kvStoreVersionKey    = []byte("v")
+	kvStoreCommitmentKey = []byte("c")
+)
+
+const (
+	kvStoreEntryPrefix = 'm'
+	kvStoreProofPrefix = 'p'
+)
+
+// kvStoreKey returns the key of the index under the prefix
+func kvStoreKey(prefix byte, index int) []byte {
+	key := make([]byte, 5)
+	key[0] = prefix
+	binary.BigEndian.PutUint32(key[1:], uint32(index))
+	return key
+}
+
+// kvStoreVersion encodes a version
+func kvStoreVersion(version uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, version)
+	return b
+}
+
+// kvStore is the Store over an ethdb.KeyValueStore, used by a single process
+type kvStore struct {
+	db ethdb.KeyValueStore
+}
+
+// NewKVStore returns the Store keeping a vector in db, which it owns from then on
+func NewKVStore(db ethdb.KeyValueStore) Store {
+	return &kvStore{db: db}
+}
+
+func (s *kvStore) Load() (*StoreState, error) {
+	state := &StoreState{Entries: make(map[int][]byte)}
+	if ok, err := s.db.Has(kvStoreVersionKey); err != nil || !ok {
+		return state, err
+	}
+	version, err := s.db.Get(kvStoreVersionKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(version) != 8 {
+		return nil, errors.New("malformed version")
+	}
+	state.Version = binary.BigEndian.Uint64(version)
+	if state.Commitment, err = s.db.Get(kvStoreCommitmentKey); err != nil {
+		return nil, err
+	}
+	it := s.db.NewIterator([]byte{kvStoreEntryPrefix}, nil)
+	defer it.Release()
+	for it.Next() {
+		if len(it.Key()) != 5 {
+			return nil, fmt.Errorf("malformed key %x", it.Key())
+		}
+		state.Entries[int(binary.BigEndian.Uint32(it.Key()[1:]))] = append([]byte{}, it.Value()...)
+	}
+	return state, it.Error()
+}
+
+func (s *kvStore) Apply(change *StoreChange) error {
+	batch := s.db.NewBatch()
+	var err error
+	if change.Entry == nil {
+		err = batch.Delete(kvStoreKey(kvStoreEntryPrefix, change.Index))
+	} else {
+		err = batch.Put(kvStoreKey(kvStoreEntryPrefix, change.Index), change.Entry)
+	}
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(kvStoreCommitmentKey, change.Commitment); err != nil {
+		return err
+	}
+	if err := batch.Put(kvStoreVersionKey, kvStoreVersion(change.Version)); err != nil {
+		return err
+	}
+	if change.Proof != nil {
+		value := append(kvStoreVersion(change.Version), change.Proof...)
+		if err := batch.Put(kvStoreKey(kvStoreProofPrefix, change.Index), value); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+func (s *kvStore) Proof(index int) (uint64, []byte, error) {
+	key := kvStoreKey(kvStoreProofPrefix, index)
+	if ok, err := s.db.Has(key); err != nil || !ok {
+		return 0, nil, err
+	}
+	value, err := s.db.Get(key)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(value) < 8 {
+		return 0, nil, fmt.Errorf("malformed proof of index %d", index)
+	}
+	return binary.BigEndian.Uint64(value), value[8:], nil
+}
+
+func (s *kvStore) PutProof(index int, version uint64, proof []byte) error {
+	return s.db.Put(kvStoreKey(kvStoreProofPrefix, index), append(kvStoreVersion(version), proof...))
+}
+
+func (s *kvStore) Close() error {
+	return s.db.Close()
+}
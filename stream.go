@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. a reader holding the message m_1, ..., m_n as n scalars in the encoding of writeScalar, e.g. a file
+		4. the number of entries to hold in memory at once
+	It returns the same commitment as commit, but never holds more than chunkSize entries of the message:
+	since C = \sum m_i * pp1[i] is linear, every chunk is committed to with its own multi-scalar
+	multiplication against the matching slice of pp1 and the partial commitments are added up.
+	Larger chunks make for larger, more efficient multi-scalar multiplications.
+*/
+func CommitStream(r io.Reader, chunkSize int) (*bls.PointG1, error) {
+	if chunkSize <= 0 {
+		panic("chunk size must be positive")
+	}
+	if chunkSize > n {
+		chunkSize = n
+	}
+	br := bufio.NewReader(r)
+	buf := make([]byte, chunkSize*scalarSize)
+	scalars := make([]fr, chunkSize)
+	s := new(big.Int)
+	g := getG1()
+	defer putG1(g)
+	res := g.Zero()
+	for offset := 0; offset < n; offset += chunkSize {
+		size := chunkSize
+		if offset+size > n {
+			size = n - offset
+		}
+		if _, err := io.ReadFull(br, buf[:size*scalarSize]); err != nil {
+			return nil, fmt.Errorf("message entry %d: %w", offset, err)
+		}
+		for i := 0; i < size; i++ {
+			s.SetBytes(buf[i*scalarSize : (i+1)*scalarSize])
+			if s.Cmp(frModulus) != -1 {
+				return nil, fmt.Errorf("message entry %d: scalar does not lie in the field", offset+i)
+			}
+			scalars[i] = frFromBig(s)
+		}
+		g.Add(res, res, currentMSM().MultiExpG1(pp1Range(offset, offset+size), scalars[:size]))
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, errors.New("trailing bytes after the message")
+	}
+	return res, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Subvector openings. A single element proves the entries of a set S of indices at once: the
+	aggregation \sum t_i pi_i of their proofs with the scalars of AggregateSameCommitment. It is
+	computed without the individual proofs: pi_i = \sum_{j != i} m_j pp1[n - i + j] gives
+		\sum_{i in S} t_i pi_i = \sum_k pp1[k] * \sum_{i in S} t_i m_{k - n + i}
+	(the terms j = i land on pp1[n], the point at infinity), so the opening is one multi-scalar
+	multiplication over pp1 for any size of S, after |S| * n field multiplications.
+*/
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the message vector
+		4. the distinct indices to open
+	It returns the proof that the message has its entries at the indices, checked by VerifySubvector.
+	The scalars depend on the commitment, which it computes once more.
+*/
+func OpenSubvector(message []*big.Int, indices []int) *bls.PointG1 {
+	return profiled("open_subvector", func() *bls.PointG1 {
+		checkVector(message)
+		values := make([]*big.Int, len(indices))
+		for k, i := range indices {
+			if !(0 <= i && i < n) {
+				panic("out of range index")
+			}
+			values[k] = message[i]
+		}
+		t := frVector(sameCommitmentScalars(commit(message), indices, values))
+		m := frVector(message)
+		// scalars[k] = \sum_{i in S} t_i m_{k - n + i}
+		scalars := make([]fr, 2*n)
+		var product fr
+		for s, i := range indices {
+			for j := 0; j < n; j++ {
+				product.mul(m[j], t[s])
+				scalars[n-i+j].add(scalars[n-i+j], product)
+			}
+		}
+		return currentMSM().MultiExpG1(pp1Range(0, 2*n), scalars)
+	})
+}
+
+// VerifySubvector reports whether proof, from OpenSubvector, opens com to values at indices
+func VerifySubvector(com *bls.PointG1, indices []int, values []*big.Int, proof *bls.PointG1) bool {
+	return VerifySameCommitment(com, indices, values, proof)
+}
+
+/*
+	Openings of the whole vector, S = {0, ..., n - 1}. Then the scalars of the MSM,
+		s_k = \sum_i t_i m_{k - n + i} = \sum_j m_j t_{j + n - k} = (m * r)_{k - 1}
+	with r_x = t_{n - 1 - x}, are a convolution, which FFTs of length 2n give with O(n log n) field
+	multiplications instead of n^2. The proof ties the commitment to the whole published message under
+	the scalars of AggregateSameCommitment, so any verifier of same-commitment aggregations accepts it.
+	A verifier holding the message can as well recompute the commitment, with one MSM in G1 instead of
+	one in G2. n has to be a power of two.
+*/
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the message vector
+	It returns the proof that the commitment opens to the message at every position, checked by
+	VerifyVector. The scalars depend on the commitment, which it computes once more.
+*/
+func OpenVector(message []*big.Int) *bls.PointG1 {
+	return profiled("open_vector", func() *bls.PointG1 {
+		checkVector(message)
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		t := frVector(sameCommitmentScalars(commit(message), indices, message))
+		omega := rootOfUnity(2 * n)
+		a := make([]fr, 2*n)
+		copy(a, frVector(message))
+		r := make([]fr, 2*n)
+		for x := 0; x < n; x++ {
+			r[x] = t[n-1-x]
+		}
+		fftScalars(a, omega)
+		fftScalars(r, omega)
+		for k := range a {
+			a[k].mul(a[k], r[k])
+		}
+		ifftScalars(a, omega)
+		// s_0 = 0 and s_k = (m * r)_{k - 1}, the convolution has length 2n - 1
+		scalars := make([]fr, 2*n)
+		copy(scalars[1:], a[:2*n-1])
+		return currentMSM().MultiExpG1(pp1Range(0, 2*n), scalars)
+	})
+}
+
+// VerifyVector reports whether proof, from OpenVector, opens com to the message at every position
+func VerifyVector(com *bls.PointG1, message []*big.Int, proof *bls.PointG1) bool {
+	checkVector(message)
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return VerifySameCommitment(com, indices, message, proof)
+}
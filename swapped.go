@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	The swapped variant. The message is committed in G2 with the G2 powers,
+		C = \sum m_j * pp2[j] = g_2^{\sum m_j alpha^{j+1}}
+	and the proofs stay in G1, where they are the very points generateProofSingle computes, since
+		e(pp1[n - i - 1], C) = e(g_1, g_2)^{\sum_j m_j alpha^{n+1-i+j}} = e(pi_i, g_2) * g_T^{alpha^{n+1} * m_i}
+	A commitment takes 192 bytes instead of 96 and an MSM in G2 to compute, in exchange every base the
+	verifier combines is in G1: aggregated proofs are checked with an MSM in G1 instead of G2, which is
+	several times cheaper. The parameters are the same ones, the variant is recorded in their file with
+	paramsFlagSwapped and the functions here only work with parameters carrying it. G2 commitments are
+	serialized as kind 7 artifacts.
+*/
+
+// swappedGroups reports whether the installed parameters are those of the swapped variant
+var swappedGroups bool
+
+// requireSwapped panics unless the installed parameters are those of the swapped variant
+func requireSwapped() {
+	if !swappedGroups {
+		panic("the installed parameters are not those of the swapped variant")
+	}
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly), of the swapped variant
+		3. the message vector
+	It returns the commitment in G2. Proofs of its entries are those of ProveSet, in G1.
+*/
+func CommitG2(message []*big.Int) *bls.PointG2 {
+	return profiled("commit_g2", func() *bls.PointG2 {
+		requireSwapped()
+		checkVector(message)
+		return backend.MultiExpG2(pp2[:], frVector(message))
+	})
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly), of the swapped variant
+		3. the commitment of CommitG2
+		4. entry m_i
+		5. the proof of m_i, from ProveSet
+		6. index
+	It reports whether the proof opens the commitment to m_i at the index.
+*/
+func VerifyG2(com *bls.PointG2, entry *big.Int, proof *bls.PointG1, index int) bool {
+	return profiled("verify_g2", func() bool {
+		requireSwapped()
+		if !(0 <= index && index < n) {
+			panic("out of range index")
+		}
+		// e(g_1^{alpha^{n-i}}, C) * e(pi, g_2)^{-1} = g_T^{alpha^{n+1} * m_i}
+		g1s := []*bls.PointG1{pp1Point(n - index - 1), negG1(proof)}
+		g2s := []*bls.PointG2{com, g2Generator}
+		return verifyPairings(g1s, g2s, frFromBig(entry))
+	})
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly), of the swapped variant
+		3. the commitment of CommitG2
+		4. the opened indices
+		5. the values at these indices
+		6. the proof aggregated by AggregateSameCommitment from the proofs of ProveSet
+	It reports whether the proof opens the commitment to the values at the indices. The Fiat-Shamir
+	scalars absorb the commitment in its own encoding, so they differ from those of a G1 commitment.
+*/
+func VerifySameCommitmentG2(com *bls.PointG2, indices []int, values []*big.Int, proof *bls.PointG1) bool {
+	return profiled("verify_same_g2", func() bool {
+		requireSwapped()
+		t := frVector(sameCommitmentScalarsG2(com, indices, values))
+		if len(indices) == 0 {
+			return true
+		}
+		bases := make([]*bls.PointG1, len(indices))
+		var sum, product fr
+		for k, i := range indices {
+			bases[k] = pp1Point(n - i - 1)
+			product.mul(frFromBig(values[k]), t[k])
+			sum.add(sum, product)
+		}
+		// e(\sum t_i g_1^{alpha^{n-i}}, C) * e(pi, g_2)^{-1} = g_T^{alpha^{n+1} \sum m_i t_i}
+		g1s := []*bls.PointG1{backend.MultiExpG1(bases, t), negG1(proof)}
+		g2s := []*bls.PointG2{com, g2Generator}
+		return verifyPairings(g1s, g2s, sum)
+	})
+}
+
+// sameCommitmentScalarsG2 is sameCommitmentScalars for a commitment in G2
+func sameCommitmentScalarsG2(com *bls.PointG2, indices []int, values []*big.Int) []*big.Int {
+	checkOpened(indices, values)
+	g := getG2()
+	defer putG2(g)
+	t := NewTranscript(sameCommitmentDomain)
+	t.Append("C", g.ToBytes(com))
+	appendOpenings(t, indices, values)
+	return challengesInOrder(t, "t", indexOrder(indices))
+}
+
+// AggregateSameCommitmentG2 is AggregateSameCommitment for a commitment of CommitG2
+func AggregateSameCommitmentG2(com *bls.PointG2, openings []Opening) *bls.PointG1 {
+	requireSwapped()
+	indices := make([]int, len(openings))
+	values := make([]*big.Int, len(openings))
+	proofs := make([]*bls.PointG1, len(openings))
+	for k, o := range openings {
+		indices[k], values[k], proofs[k] = o.Index, o.Value, o.Proof
+	}
+	t := sameCommitmentScalarsG2(com, indices, values)
+	if len(openings) == 0 {
+		return bls.NewG1().Zero()
+	}
+	return backend.MultiExpG1(proofs, frVector(t))
+}
+
+// encodeG2Commitment serializes a commitment returned by CommitG2
+func encodeG2Commitment(com *bls.PointG2) []byte {
+	var buf bytes.Buffer
+	// writing into a bytes.Buffer cannot fail
+	_ = writeArtifactHeader(&buf, artifactG2Commitment)
+	_ = writeG2(&buf, com)
+	return buf.Bytes()
+}
+
+// decodeG2Commitment parses a commitment of CommitG2 produced under the installed parameters
+func decodeG2Commitment(data []byte) (*bls.PointG2, error) {
+	if len(data) != 1+fingerprintSize+g2Size {
+		return nil, fmt.Errorf("expected %d bytes, got %d", 1+fingerprintSize+g2Size, len(data))
+	}
+	r := bytes.NewReader(data)
+	if err := readArtifactHeader(r, artifactG2Commitment); err != nil {
+		return nil, err
+	}
+	p, err := readG2(r)
+	if err != nil {
+		return nil, err
+	}
+	g := getG2()
+	defer putG2(g)
+	if !g.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in the correct subgroup")
+	}
+	return p, nil
+}
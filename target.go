@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Every verification compares a product of pairings with g_T^{alpha^{n+1} * x} for some scalar x.
+	Instead of the pairing e(g1^{alpha * x}, g2^{alpha^n}) the target is raised to x directly. A plain GT
+	exponentiation costs about as much as the pairing it replaces, so g_T^{alpha^{n+1}} is stored with
+	its powers d * 2^{4k}, which turns the exponentiation into 64 multiplications.
+*/
+
+// window size of gtTable in bits, the table holds 64 * 15 elements of GT, about 550 KB
+const gtTableWindow = 4
+
+// gtTable holds the powers of a fixed element of GT needed for fixed-base exponentiation
+type gtTable struct {
+	// rows[k][d - 1] = base^{d * 2^{gtTableWindow * k}} for 1 <= d < 2^gtTableWindow
+	rows [][]*bls.E
+}
+
+// newGTTable precomputes the powers of base
+func newGTTable(gt *bls.GT, base *bls.E) *gtTable {
+	windows := (gt.Q().BitLen() + gtTableWindow - 1) / gtTableWindow
+	t := &gtTable{rows: make([][]*bls.E, windows)}
+	b := gt.New().Set(base)
+	for k := range t.rows {
+		row := make([]*bls.E, 1<<gtTableWindow-1)
+		row[0] = gt.New().Set(b)
+		for d := 1; d < len(row); d++ {
+			row[d] = gt.New()
+			gt.Mul(row[d], row[d-1], b)
+		}
+		t.rows[k] = row
+		for j := 0; j < gtTableWindow; j++ {
+			gt.Square(b, b)
+		}
+	}
+	return t
+}
+
+// exp returns base^s, s has to be reduced modulo the group order
+func (t *gtTable) exp(gt *bls.GT, s *big.Int) *bls.E {
+	res := gt.New()
+	words := s.Bits()
+	for k, row := range t.rows {
+		if d := scalarWindow(words, k*gtTableWindow, gtTableWindow); d != 0 {
+			gt.Mul(res, res, row[d-1])
+		}
+	}
+	return res
+}
+
+// gtTarget is the table for g_T^{alpha^{n+1}} = e(pp1[0], pp2[n - 1]) of the installed parameters
+var gtTarget *gtTable
+
+// target computes the table for g_T^{alpha^{n+1}} of the parameters
+func (pp *PublicParams) target() *gtTable {
+	e := bls.NewPairingEngine()
+	base := e.AddPair(e.G1.New().Set(pp.g1Power(0)), e.G2.New().Set(pp.PP2[n-1])).Result()
+	return newGTTable(e.GT(), base)
+}
+
+// checkTarget checks that the product of the pairings added to e is g_T^{alpha^{n+1} * x}, it resets e
+func checkTarget(e *bls.Engine, x fr) bool {
+	lhs := e.Result()
+	return lhs.Equal(gtTarget.exp(e.GT(), x.big()))
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+	"golang.org/x/crypto/sha3"
+	"lukechampine.com/blake3"
+)
+
+/*
+	Fiat-Shamir transcripts. Every scalar the scheme derives by hashing comes from a Transcript, which
+	absorbs labelled messages and squeezes scalars. A transcript starts from a domain naming the protocol
+	step and the hash it runs on, so the derivations of different steps, hashes or applications (which
+	pass their own domain) never collide. The hash state absorbs
+		"PointProofs-transcript-v1" || len(hash name) || hash name || len(domain) || domain
+	and then, in the order of the calls,
+		Append(label, data):      0x01 || len(label) || label || len(data) || data
+		ChallengeScalar(label):   0x02 || len(label) || label
+	with the lengths as big endian uint32. A challenge is H(d || 0x00) || H(d || 0x01) for the digest d of
+	the state so far, reduced modulo the group order: 512 bits keep the bias out of sight.
+*/
+
+// TranscriptHash is a hash function transcripts can run on
+type TranscriptHash struct {
+	// Name enters the domain separation, two hashes must not share it
+	Name string
+	New  func() hash.Hash
+}
+
+var (
+	TranscriptSHA256 = TranscriptHash{Name: "SHA-256", New: sha256.New}
+	TranscriptBLAKE3 = TranscriptHash{Name: "BLAKE3", New: func() hash.Hash { return blake3.New(32, nil) }}
+	// TranscriptKeccak256 is the Keccak-256 of Ethereum (not SHA3-256), see below
+	TranscriptKeccak256 = TranscriptHash{Name: "Keccak-256", New: sha3.NewLegacyKeccak256}
+)
+
+/*
+	On Keccak-256 a contract recomputes the challenges with the KECCAK256 opcode, keeping the absorbed
+	bytes in memory as the state. In Solidity, for a domain and labels known at compile time:
+		bytes memory state = abi.encodePacked("PointProofs-transcript-v1", uint32(10), "Keccak-256",
+			uint32(bytes(domain).length), domain);
+		// Append(label, data)
+		state = abi.encodePacked(state, uint8(1), uint32(bytes(label).length), label, uint32(data.length), data);
+		// ChallengeScalar(label)
+		state = abi.encodePacked(state, uint8(2), uint32(bytes(label).length), label);
+		bytes32 d = keccak256(state);
+		uint256 hi = uint256(keccak256(abi.encodePacked(d, uint8(0))));
+		uint256 lo = uint256(keccak256(abi.encodePacked(d, uint8(1))));
+		uint256 c = addmod(mulmod(hi, R, Q), lo, Q);
+	with Q the group order 0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001 and
+	R = 2^256 mod Q = 0x1824b159acc5056f998c4fefecbc4ff55884b7fa0003480200000001fffffffe. Points are
+	absorbed in the 96 byte encoding of writeG1, not the padded 128 byte one of the EIP-2537 precompiles.
+*/
+
+// transcriptHash is the hash of the transcripts of the scheme, SHA-256 unless set by SetTranscriptHash
+var transcriptHash = TranscriptSHA256
+
+// SetTranscriptHash makes every Fiat-Shamir derivation of the scheme use h. Provers and verifiers have to
+// agree on it, and it must not be called while scalars are being derived
+func SetTranscriptHash(h TranscriptHash) {
+	if h.Name == "" || h.New == nil {
+		panic("incomplete transcript hash")
+	}
+	transcriptHash = h
+}
+
+// domain separation tags of the protocol steps deriving scalars
+const (
+	transcriptPrefix      = "PointProofs-transcript-v1"
+	sameCommitmentDomain  = "same-commitment-aggregation"
+	crossCommitmentDomain = "cross-commitment-aggregation"
+	dkgDomain             = "dkg-proof-of-knowledge"
+	linearRelationDomain  = "hiding-linear-relation"
+	nestedDomain          = "nested-aggregation"
+	migrationDomain       = "srs-migration"
+	weightedDomain        = "weighted-aggregation"
+	rangeDomain           = "range-opening"
+	dasDomain             = "das-encoding"
+)
+
+const (
+	transcriptAppend    = 1
+	transcriptChallenge = 2
+)
+
+// Transcript derives Fiat-Shamir challenges from the messages appended to it, it is not safe for concurrent use
+type Transcript struct {
+	hash TranscriptHash
+	h    hash.Hash
+}
+
+// NewTranscript returns a transcript for the given domain on the hash set by SetTranscriptHash
+func NewTranscript(domain string) *Transcript {
+	return NewTranscriptWithHash(transcriptHash, domain)
+}
+
+// NewTranscriptWithHash returns a transcript for the given domain on the given hash
+func NewTranscriptWithHash(hash TranscriptHash, domain string) *Transcript {
+	t := &Transcript{hash: hash, h: hash.New()}
+	t.h.Write([]byte(transcriptPrefix))
+	t.writeBytes([]byte(hash.Name))
+	t.writeBytes([]byte(domain))
+	return t
+}
+
+// writeBytes writes len(b) || b into the hash
+func (t *Transcript) writeBytes(b []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(b)))
+	t.h.Write(length[:])
+	t.h.Write(b)
+}
+
+// Append absorbs data under the given label
+func (t *Transcript) Append(label string, data []byte) {
+	t.h.Write([]byte{transcriptAppend})
+	t.writeBytes([]byte(label))
+	t.writeBytes(data)
+}
+
+// AppendUint32 absorbs x as 4 big endian bytes
+func (t *Transcript) AppendUint32(label string, x uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], x)
+	t.Append(label, b[:])
+}
+
+// AppendScalar absorbs s as 32 big endian bytes, s has to lie in the field
+func (t *Transcript) AppendScalar(label string, s *big.Int) {
+	if s.Sign() < 0 || s.Cmp(frModulus) != -1 {
+		panic("scalar does not lie in the field")
+	}
+	b := make([]byte, scalarSize)
+	t.Append(label, s.FillBytes(b))
+}
+
+// AppendG1 absorbs p in the uncompressed encoding of writeG1
+func (t *Transcript) AppendG1(label string, p *bls.PointG1) {
+	g := getG1()
+	defer putG1(g)
+	t.Append(label, g.ToBytes(p))
+}
+
+// ChallengeScalar returns a scalar determined by everything absorbed so far and the label, and absorbs
+// the request so the next challenge differs
+func (t *Transcript) ChallengeScalar(label string) *big.Int {
+	return t.challengeScalarMod(label, frModulus)
+}
+
+// challengeScalarMod is ChallengeScalar for the group order q of another curve, see curvescheme.go
+func (t *Transcript) challengeScalarMod(label string, q *big.Int) *big.Int {
+	t.h.Write([]byte{transcriptChallenge})
+	t.writeBytes([]byte(label))
+	d := t.h.Sum(nil)
+	wide := make([]byte, 0, 2*len(d))
+	for _, b := range []byte{0, 1} {
+		h := t.hash.New()
+		h.Write(d)
+		h.Write([]byte{b})
+		wide = h.Sum(wide)
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(wide), q)
+}
+
+// ChallengeScalars returns count challenges for the label
+func (t *Transcript) ChallengeScalars(label string, count int) []*big.Int {
+	res := make([]*big.Int, count)
+	for k := range res {
+		res[k] = t.ChallengeScalar(label)
+	}
+	return res
+}
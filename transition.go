@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Verifiable state transitions. C_new differs from C_old exactly at the positions i_k, where m_{i_k}
+	changes from a_k to b_k, when
+		C_new = C_old + \sum (b_k - a_k) * pp1[i_k]		and		C_old opens to a_k at i_k
+	The first equation is public, anyone checks it with one MSM, and it leaves every other entry as it
+	was. The second needs a proof, the subvector opening of C_old at the changed positions, so the
+	transition is proven with one G1 point and checked without either vector.
+*/
+
+// Transition is the change of the entry at Index from Old to New
+type Transition struct {
+	Index    int
+	Old, New *big.Int
+}
+
+// transitionClaims returns the indices, the old values and the updates of the transitions
+func transitionClaims(transitions []Transition) ([]int, []*big.Int, []Update) {
+	indices := make([]int, len(transitions))
+	olds := make([]*big.Int, len(transitions))
+	updates := make([]Update, len(transitions))
+	for k, t := range transitions {
+		indices[k], olds[k] = t.Index, t.Old
+		updates[k] = Update{Index: t.Index, Delta: new(big.Int).Sub(t.New, t.Old)}
+	}
+	return indices, olds, updates
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the message before the transition
+		4. the transitions, at distinct indices and with the old values of the message
+	It returns the proof that the commitment to the message changes by the transitions, and only them.
+*/
+func ProveTransition(oldMessage []*big.Int, transitions []Transition) *bls.PointG1 {
+	checkVector(oldMessage)
+	for _, t := range transitions {
+		if oldMessage[t.Index].Cmp(t.Old) != 0 {
+			panic("the old value does not match the message")
+		}
+	}
+	if len(transitions) == 0 {
+		return bls.NewG1().Zero()
+	}
+	indices, _, _ := transitionClaims(transitions)
+	return OpenSubvector(oldMessage, indices)
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1, pp2 (implicitly)
+		3. the commitments before and after
+		4. the claimed transitions
+		5. the proof of ProveTransition
+	It reports whether newCom commits to the message of oldCom changed by exactly the transitions.
+*/
+func VerifyTransition(oldCom, newCom *bls.PointG1, transitions []Transition, proof *bls.PointG1) bool {
+	indices, olds, updates := transitionClaims(transitions)
+	for _, t := range transitions {
+		if t.New.Sign() < 0 || t.New.Cmp(frModulus) != -1 {
+			panic("the message does not lie in the group")
+		}
+	}
+	// checkOpened, through VerifySubvector, rejects repeated indices before they could add up
+	if len(transitions) > 0 && !VerifySubvector(oldCom, indices, olds, proof) {
+		return false
+	}
+	expected := (*Commitment)(new(bls.PointG1).Set(oldCom))
+	ApplyUpdates(expected, updates)
+	// Equal writes temporaries of its G1, the shared engine's would race between verifiers
+	g := getG1()
+	defer putG1(g)
+	return g.Equal(expected.Point(), newCom)
+}
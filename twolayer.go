@@ -0,0 +1,173 @@
+package main
+
+import (
+	"math/big"
+	"sort"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Two-layer commitments. A vector of length n^2 is split into n chunks of n entries, chunk j holding
+	entries j * n, ..., j * n + n - 1. Every chunk is committed, and the top commitment commits to the
+	hashes of the chunk commitments, so it is the root of a VerkleTree of depth 2 holding the vector.
+	An opening of any set of entries lists the commitments of the chunks it touches and aggregates the
+	openings of the top commitment at those chunks and of every chunk at its entries into one proof with
+	AggregateCrossCommitment.
+*/
+
+// TwoLayer is the prover state of a two-layer commitment
+type TwoLayer struct {
+	chunks [][]*big.Int
+	coms   []*bls.PointG1
+	hashes []*big.Int
+	top    *bls.PointG1
+}
+
+// TwoLayerProof proves entries of a two-layer commitment
+type TwoLayerProof struct {
+	// Commitments of the chunks holding the opened entries, in increasing order of the chunks
+	Commitments []*bls.PointG1
+	// Proof aggregates the openings of the top commitment and of the chunks
+	Proof *bls.PointG1
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the vector of n^2 field elements
+	It commits to every chunk with CommitMany and to the hashes of their commitments.
+*/
+func CommitTwoLayer(vector []*big.Int) *TwoLayer {
+	if len(vector) != n*n {
+		panic("wrong array size")
+	}
+	tl := &TwoLayer{chunks: make([][]*big.Int, n), hashes: make([]*big.Int, n)}
+	for j := range tl.chunks {
+		tl.chunks[j] = vector[j*n : (j+1)*n : (j+1)*n]
+	}
+	tl.coms = CommitMany(tl.chunks)
+	for j, com := range tl.coms {
+		tl.hashes[j] = verkleNodeHash(com)
+	}
+	tl.top = commit(tl.hashes)
+	return tl
+}
+
+// Root returns the top commitment
+func (tl *TwoLayer) Root() *bls.PointG1 {
+	return new(bls.PointG1).Set(tl.top)
+}
+
+// groupByChunk returns the chunks touched by indices in increasing order and the positions within
+// indices of the entries of each of them
+func groupByChunk(indices []int) ([]int, [][]int) {
+	positions := map[int][]int{}
+	for k, i := range indices {
+		if !(0 <= i && i < n*n) {
+			panic("out of range index")
+		}
+		positions[i/n] = append(positions[i/n], k)
+	}
+	chunks := make([]int, 0, len(positions))
+	for j := range positions {
+		chunks = append(chunks, j)
+	}
+	sort.Ints(chunks)
+	res := make([][]int, len(chunks))
+	for c, j := range chunks {
+		res[c] = positions[j]
+	}
+	return chunks, res
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the distinct indices to open, 0 <= index < n^2
+	It returns the values at the indices and the proof of them. It computes one proof with ProveSet per
+	touched chunk and one for the top commitment.
+*/
+func (tl *TwoLayer) Open(indices []int) ([]*big.Int, *TwoLayerProof) {
+	chunks, positions := groupByChunk(indices)
+	values := make([]*big.Int, len(indices))
+	groups := make([]CommitmentOpenings, 0, len(chunks)+1)
+	res := &TwoLayerProof{}
+	for c, j := range chunks {
+		offsets := make([]int, len(positions[c]))
+		for k, p := range positions[c] {
+			offsets[k] = indices[p] % n
+			values[p] = new(big.Int).Set(tl.chunks[j][offsets[k]])
+		}
+		group := CommitmentOpenings{Commitment: tl.coms[j]}
+		for k, proof := range ProveSet(tl.chunks[j], offsets) {
+			group.Openings = append(group.Openings, Opening{Index: offsets[k], Value: tl.chunks[j][offsets[k]], Proof: proof})
+		}
+		groups = append(groups, group)
+		res.Commitments = append(res.Commitments, new(bls.PointG1).Set(tl.coms[j]))
+	}
+	top := CommitmentOpenings{Commitment: tl.top}
+	for k, proof := range ProveSet(tl.hashes, chunks) {
+		top.Openings = append(top.Openings, Opening{Index: chunks[k], Value: tl.hashes[chunks[k]], Proof: proof})
+	}
+	res.Proof = AggregateCrossCommitment(append([]CommitmentOpenings{top}, groups...))
+	return values, res
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the top commitment
+		4. the opened indices, in the order they were opened
+		5. the values at these indices
+		6. the proof of TwoLayer.Open
+	It reports whether the proof opens the two-layer commitment to the values at the indices.
+*/
+func VerifyTwoLayer(root *bls.PointG1, indices []int, values []*big.Int, proof *TwoLayerProof) bool {
+	if len(indices) != len(values) {
+		panic("arrays with incorrect length")
+	}
+	chunks, positions := groupByChunk(indices)
+	if len(proof.Commitments) != len(chunks) {
+		return false
+	}
+	coms := append([]*bls.PointG1{root}, proof.Commitments...)
+	allIndices := [][]int{chunks}
+	allValues := [][]*big.Int{make([]*big.Int, len(chunks))}
+	for c := range chunks {
+		allValues[0][c] = verkleNodeHash(proof.Commitments[c])
+		offsets := make([]int, len(positions[c]))
+		chunkValues := make([]*big.Int, len(positions[c]))
+		for k, p := range positions[c] {
+			offsets[k] = indices[p] % n
+			chunkValues[k] = values[p]
+		}
+		allIndices = append(allIndices, offsets)
+		allValues = append(allValues, chunkValues)
+	}
+	return VerifyCrossCommitment(coms, allIndices, allValues, proof.Proof)
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the index of the entry, 0 <= index < n^2
+		4. its new value
+	It updates the entry, the commitment of its chunk and the top commitment in place with two scalar
+	multiplications. The vector handed to CommitTwoLayer changes along, its chunks are shared.
+*/
+func (tl *TwoLayer) Set(index int, value *big.Int) {
+	if !(0 <= index && index < n*n) {
+		panic("out of range index")
+	}
+	j, i := index/n, index%n
+	(*Commitment)(tl.coms[j]).Update(i, tl.chunks[j][i], value)
+	tl.chunks[j][i] = new(big.Int).Set(value)
+	hash := verkleNodeHash(tl.coms[j])
+	(*Commitment)(tl.top).Update(j, tl.hashes[j], hash)
+	tl.hashes[j] = hash
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestTwoLayer(t *testing.T) {
+	benchSetup(t)
+	// a sparse vector keeps the n chunk commitments cheap
+	vector := make([]*big.Int, n*n)
+	for i := range vector {
+		vector[i] = new(big.Int)
+	}
+	for _, i := range []int{3, n + 3, 5*n + 100, n*n - 1} {
+		vector[i] = big.NewInt(int64(i + 1))
+	}
+	tl := CommitTwoLayer(vector)
+	indices := []int{5*n + 100, 3, n + 3, 5*n + 101, n*n - 1}
+	values, proof := tl.Open(indices)
+	for k, i := range indices {
+		if values[k].Cmp(vector[i]) != 0 {
+			t.Fatalf("entry %d opened to %v", i, values[k])
+		}
+	}
+	root := tl.Root()
+	if !VerifyTwoLayer(root, indices, values, proof) {
+		t.Fatal("opening rejected")
+	}
+	wrong := append([]*big.Int(nil), values...)
+	wrong[3] = big.NewInt(1)
+	if VerifyTwoLayer(root, indices, wrong, proof) {
+		t.Fatal("opening accepted for a wrong value")
+	}
+	if VerifyTwoLayer(root, indices[:2], values[:2], proof) {
+		t.Fatal("opening accepted for a subset of the entries")
+	}
+
+	tl.Set(n+3, big.NewInt(77))
+	if VerifyTwoLayer(tl.Root(), indices, values, proof) {
+		t.Fatal("opening accepted against the root after a change")
+	}
+	values, proof = tl.Open(indices)
+	if values[2].Cmp(big.NewInt(77)) != 0 || !VerifyTwoLayer(tl.Root(), indices, values, proof) {
+		t.Fatal("opening after a change rejected")
+	}
+	// Set keeps the commitments where committing the changed vector afresh puts them
+	g := getG1()
+	defer putG1(g)
+	if !g.Equal(tl.Root(), CommitTwoLayer(vector).Root()) {
+		t.Fatal("root after Set differs from the commitment to the changed vector")
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Updates. A commitment C = \sum m_j * pp1[j] is linear in the message, so when m_i changes to m_i'
+	the new commitment is C + (m_i' - m_i) * pp1[i]: one scalar multiplication instead of recommitting
+	all n entries.
+*/
+
+// Commitment is a commitment returned by commit, (*Commitment)(com) gives access to its methods
+type Commitment bls.PointG1
+
+// Point returns the commitment as the G1 point commit returned, both share the same memory
+func (c *Commitment) Point() *bls.PointG1 {
+	return (*bls.PointG1)(c)
+}
+
+// checkUpdate panics unless index is in range and both values lie in the field
+func checkUpdate(index int, oldVal, newVal *big.Int) {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	for _, v := range []*big.Int{oldVal, newVal} {
+		if v.Sign() < 0 || v.Cmp(frModulus) != -1 {
+			panic("the message does not lie in the group")
+		}
+	}
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the index of the entry that changed
+		4. its old value m_i
+		5. its new value m_i'
+	It turns the commitment into the commitment to the updated message in place. The old value is not
+	checked, with a wrong one the result commits to something else entirely.
+*/
+func (c *Commitment) Update(index int, oldVal, newVal *big.Int) {
+	checkUpdate(index, oldVal, newVal)
+	var delta fr
+	delta.sub(frFromBig(newVal), frFromBig(oldVal))
+	if delta.isZero() {
+		return
+	}
+	p := c.Point()
+	g := getG1()
+	defer putG1(g)
+	g.Add(p, p, pp1Mul(g, g.New(), index, delta.big()))
+}
+
+/*
+	The proof of index i is pi_i = \sum_{j != i} m_j * pp1[n - i + j], so a change of m_j by delta for
+	j != i moves it by delta * pp1[n - i + j]: anyone holding pi_i can keep it fresh from the public
+	parameters and the published changes, without knowing the rest of the message.
+*/
+
+// Proof is a proof returned by generateProofSingle, (*Proof)(proof) gives access to its methods
+type Proof bls.PointG1
+
+// Point returns the proof as the G1 point the provers return, both share the same memory
+func (p *Proof) Point() *bls.PointG1 {
+	return (*bls.PointG1)(p)
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the index i the proof opens
+		4. the index j of the entry that changed
+		5. delta = m_j' - m_j, it may be negative
+	It turns the proof into the proof of m_i for the updated message in place. A change of m_i itself
+	leaves the proof as it is, it does not depend on m_i.
+*/
+func (p *Proof) Update(i, j int, delta *big.Int) {
+	if !(0 <= i && i < n && 0 <= j && j < n) {
+		panic("out of range index")
+	}
+	d := frFromBig(delta)
+	if i == j || d.isZero() {
+		return
+	}
+	point := p.Point()
+	g := getG1()
+	defer putG1(g)
+	g.Add(point, point, pp1Mul(g, g.New(), n-i+j, d.big()))
+}
+
+// Update is the change of a single entry, m_Index' = m_Index + Delta, Delta may be negative
+type Update struct {
+	Index int
+	Delta *big.Int
+}
+
+// updateSum returns \sum Delta * pp1[offset + Index] over the updates, skipping the index skip
+func updateSum(updates []Update, offset, skip int) *bls.PointG1 {
+	points := make([]*bls.PointG1, 0, len(updates))
+	scalars := make([]fr, 0, len(updates))
+	for _, u := range updates {
+		if !(0 <= u.Index && u.Index < n) {
+			panic("out of range index")
+		}
+		if u.Index != skip {
+			points = append(points, pp1Point(offset+u.Index))
+			scalars = append(scalars, frFromBig(u.Delta))
+		}
+	}
+	if len(points) == 0 {
+		return engine.G1.Zero()
+	}
+	return currentMSM().MultiExpG1(points, scalars)
+}
+
+/*
+	It takes the following arguments:
+		1. pp1 (implicitly)
+		2. the commitment to update in place
+		3. the changes, an index may appear more than once
+	It folds all changes into a single multi-scalar multiplication, C' = C + \sum delta_k * pp1[j_k],
+	instead of one scalar multiplication per change as with Commitment.Update.
+*/
+func ApplyUpdates(com *Commitment, updates []Update) {
+	if len(updates) == 0 {
+		return
+	}
+	p := com.Point()
+	g := getG1()
+	defer putG1(g)
+	g.Add(p, p, updateSum(updates, 0, -1))
+}
+
+// ApplyUpdates is Update for many changes at once, folded into a single multi-scalar multiplication
+// the way the function ApplyUpdates does for commitments. Changes of m_i itself are ignored
+func (p *Proof) ApplyUpdates(i int, updates []Update) {
+	if !(0 <= i && i < n) {
+		panic("out of range index")
+	}
+	if len(updates) == 0 {
+		return
+	}
+	point := p.Point()
+	g := getG1()
+	defer putG1(g)
+	g.Add(point, point, updateSum(updates, n-i, i))
+}
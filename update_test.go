@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+// changedMessage returns a copy of message with the entries at the indices replaced by the values
+func changedMessage(message []*big.Int, indices []int, values []*big.Int) []*big.Int {
+	res := append([]*big.Int(nil), message...)
+	for k, i := range indices {
+		res[i] = values[k]
+	}
+	return res
+}
+
+func TestCommitmentUpdate(t *testing.T) {
+	f := benchSetup(t)
+	g := getG1()
+	defer putG1(g)
+	com := commit(f.message)
+	c := (*Commitment)(g.New().Set(com))
+	c.Update(7, f.message[7], big.NewInt(42))
+	updated := changedMessage(f.message, []int{7}, []*big.Int{big.NewInt(42)})
+	if !g.Equal(c.Point(), commit(updated)) {
+		t.Fatal("updated commitment differs from the commitment to the updated message")
+	}
+	if verifySingleProof(c.Point(), f.message[7], generateProofSingle(updated, 7), 7) {
+		t.Fatal("old value accepted after the update")
+	}
+	// with a wrong old value the result commits to something else
+	wrong := (*Commitment)(g.New().Set(com))
+	wrong.Update(7, big.NewInt(1), big.NewInt(42))
+	if g.Equal(wrong.Point(), commit(updated)) {
+		t.Fatal("update from a wrong old value gave the updated commitment")
+	}
+}
+
+func TestProofUpdate(t *testing.T) {
+	f := benchSetup(t)
+	g := getG1()
+	defer putG1(g)
+	delta := big.NewInt(-5)
+	updated := changedMessage(f.message, []int{300}, []*big.Int{new(big.Int).Mod(new(big.Int).Add(f.message[300], delta), frModulus)})
+	com := commit(updated)
+	p := (*Proof)(g.New().Set(generateProofSingle(f.message, 2)))
+	p.Update(2, 300, delta)
+	if !g.Equal(p.Point(), generateProofSingle(updated, 2)) {
+		t.Fatal("updated proof differs from the proof of the updated message")
+	}
+	if !verifySingleProof(com, updated[2], p.Point(), 2) {
+		t.Fatal("updated proof rejected")
+	}
+	stale := generateProofSingle(f.message, 2)
+	if verifySingleProof(com, updated[2], stale, 2) {
+		t.Fatal("stale proof accepted")
+	}
+	// a change of the opened entry itself leaves the proof alone
+	same := (*Proof)(g.New().Set(stale))
+	same.Update(2, 2, delta)
+	if !g.Equal(same.Point(), stale) {
+		t.Fatal("change of the opened entry moved the proof")
+	}
+}
+
+func TestApplyUpdates(t *testing.T) {
+	f := benchSetup(t)
+	g := getG1()
+	defer putG1(g)
+	// index 9 changes twice, index 4 is the opened one
+	updates := []Update{{Index: 9, Delta: big.NewInt(3)}, {Index: 4, Delta: big.NewInt(11)}, {Index: 9, Delta: big.NewInt(-1)}, {Index: n - 1, Delta: big.NewInt(8)}}
+	updated := append([]*big.Int(nil), f.message...)
+	for _, u := range updates {
+		updated[u.Index] = new(big.Int).Mod(new(big.Int).Add(updated[u.Index], u.Delta), frModulus)
+	}
+	com := commit(f.message)
+	c := (*Commitment)(g.New().Set(com))
+	ApplyUpdates(c, updates)
+	if !g.Equal(c.Point(), commit(updated)) {
+		t.Fatal("updated commitment differs from the commitment to the updated message")
+	}
+	p := (*Proof)(g.New().Set(generateProofSingle(f.message, 4)))
+	p.ApplyUpdates(4, updates)
+	if !g.Equal(p.Point(), generateProofSingle(updated, 4)) {
+		t.Fatal("updated proof differs from the proof of the updated message")
+	}
+	if !verifySingleProof(c.Point(), updated[4], p.Point(), 4) || verifySingleProof(c.Point(), f.message[4], p.Point(), 4) {
+		t.Fatal("updated proof does not open the new value alone")
+	}
+	partial := (*Commitment)(g.New().Set(com))
+	ApplyUpdates(partial, updates[:3])
+	if g.Equal(partial.Point(), c.Point()) {
+		t.Fatal("a missing update went unnoticed")
+	}
+}
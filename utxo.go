@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	UTXO sets. The unspent outputs sit in the n^2 slots of a two-layer commitment (see twolayer.go), a
+	free slot holding zero and a taken one the entry of its output,
+		EntryEncoder.Encode(slot mod n, slot / n as uint32 || output)
+	An output is inserted into the lowest free slot and spent by setting its slot back to zero, each
+	change costing two scalar multiplications. A transaction proves all its inputs unspent with one
+	TwoLayerProof, a G1 point and the commitments of the chunks touched, whatever the number of inputs.
+	MerkleUTXOSet keeps the same slots in a sparse SHA-256 Merkle tree of depth 2 log2(n), the baseline
+	of BenchmarkUTXO: a spend proof there takes 2 log2(n) hashes per input. Its leaves are all zeros for
+	a free slot and the leaf of scheme.go over the slot and the entry above otherwise.
+*/
+
+// utxoSlots is the number of slots of a UTXO set
+const utxoSlots = n * n
+
+// utxoEntry returns the entry of the output in the slot
+func utxoEntry(e *EntryEncoder, slot int, output []byte) *big.Int {
+	data := make([]byte, 4, 4+len(output))
+	binary.BigEndian.PutUint32(data, uint32(slot/n))
+	return e.Encode(slot%n, append(data, output...))
+}
+
+// utxoSlotAllocator hands out the lowest free slot
+type utxoSlotAllocator struct {
+	// next is the lowest slot never taken, freed lists the slots below it spent since
+	next  int
+	freed []int
+}
+
+// take returns the lowest free slot
+func (a *utxoSlotAllocator) take() (int, error) {
+	if len(a.freed) > 0 {
+		low := 0
+		for k, s := range a.freed {
+			if s < a.freed[low] {
+				low = k
+			}
+		}
+		slot := a.freed[low]
+		a.freed[low] = a.freed[len(a.freed)-1]
+		a.freed = a.freed[:len(a.freed)-1]
+		return slot, nil
+	}
+	if a.next == utxoSlots {
+		return 0, errors.New("the UTXO set is full")
+	}
+	a.next++
+	return a.next - 1, nil
+}
+
+// release frees the slot
+func (a *utxoSlotAllocator) release(slot int) {
+	a.freed = append(a.freed, slot)
+}
+
+// UTXOSet is a committed set of unspent outputs, it is not safe for concurrent use
+type UTXOSet struct {
+	encoder *EntryEncoder
+	tl      *TwoLayer
+	outputs map[int][]byte
+	slots   utxoSlotAllocator
+}
+
+// NewUTXOSet returns the empty set of the application, see NewEntryEncoder
+func NewUTXOSet(application string) *UTXOSet {
+	zero := new(big.Int)
+	tl := &TwoLayer{chunks: make([][]*big.Int, n), coms: make([]*bls.PointG1, n), hashes: make([]*big.Int, n)}
+	empty := verkleNodeHash(bls.NewG1().Zero())
+	for j := range tl.chunks {
+		tl.chunks[j] = make([]*big.Int, n)
+		for i := range tl.chunks[j] {
+			tl.chunks[j][i] = zero
+		}
+		tl.coms[j] = bls.NewG1().Zero()
+		tl.hashes[j] = empty
+	}
+	tl.top = commit(tl.hashes)
+	return &UTXOSet{encoder: NewEntryEncoder(application), tl: tl, outputs: make(map[int][]byte)}
+}
+
+// Root returns the commitment to the set, it changes with every Insert and Spend
+func (u *UTXOSet) Root() *bls.PointG1 {
+	return u.tl.Root()
+}
+
+// Output returns the output in the slot and whether it is unspent
+func (u *UTXOSet) Output(slot int) ([]byte, bool) {
+	output, ok := u.outputs[slot]
+	return output, ok
+}
+
+// Insert adds the output to the lowest free slot and returns the slot
+func (u *UTXOSet) Insert(output []byte) (int, error) {
+	slot, err := u.slots.take()
+	if err != nil {
+		return 0, err
+	}
+	output = append([]byte{}, output...)
+	u.tl.Set(slot, utxoEntry(u.encoder, slot, output))
+	u.outputs[slot] = output
+	return slot, nil
+}
+
+// Spend removes the output in the slot, freeing the slot
+func (u *UTXOSet) Spend(slot int) error {
+	if _, ok := u.outputs[slot]; !ok {
+		return fmt.Errorf("slot %d holds no unspent output", slot)
+	}
+	u.tl.Set(slot, new(big.Int))
+	delete(u.outputs, slot)
+	u.slots.release(slot)
+	return nil
+}
+
+// ProveUnspent returns the outputs in the distinct slots and the proof they are unspent, it panics
+// if a slot holds no unspent output
+func (u *UTXOSet) ProveUnspent(slots []int) ([][]byte, *TwoLayerProof) {
+	outputs := make([][]byte, len(slots))
+	for k, s := range slots {
+		output, ok := u.outputs[s]
+		if !ok {
+			panic(fmt.Sprintf("slot %d holds no unspent output", s))
+		}
+		outputs[k] = output
+	}
+	_, proof := u.tl.Open(slots)
+	return outputs, proof
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the root of the set
+		4. the application of the set
+		5. the slots, in the order they were proven
+		6. the outputs in the slots
+		7. the proof of UTXOSet.ProveUnspent
+	It reports whether the proof shows the outputs unspent in the slots.
+*/
+func VerifyUnspent(root *bls.PointG1, application string, slots []int, outputs [][]byte, proof *TwoLayerProof) bool {
+	if len(slots) != len(outputs) {
+		panic("arrays with incorrect length")
+	}
+	e := NewEntryEncoder(application)
+	values := make([]*big.Int, len(slots))
+	for k, s := range slots {
+		if !(0 <= s && s < utxoSlots) {
+			panic("out of range index")
+		}
+		values[k] = utxoEntry(e, s, outputs[k])
+	}
+	return VerifyTwoLayer(root, slots, values, proof)
+}
+
+// MerkleUTXOSet is the UTXO set above in a sparse Merkle tree, it is not safe for concurrent use
+type MerkleUTXOSet struct {
+	encoder *EntryEncoder
+	// nodes[d] holds the nodes at height d that differ from empty[d], the leaves at height 0
+	nodes   []map[int][sha256.Size]byte
+	empty   [][sha256.Size]byte
+	outputs map[int][]byte
+	slots   utxoSlotAllocator
+}
+
+// NewMerkleUTXOSet returns the empty set of the application
+func NewMerkleUTXOSet(application string) *MerkleUTXOSet {
+	if n&(n-1) != 0 {
+		panic("n has to be a power of two")
+	}
+	depth := 2 * bits.TrailingZeros(uint(n))
+	m := &MerkleUTXOSet{encoder: NewEntryEncoder(application), nodes: make([]map[int][sha256.Size]byte, depth+1), empty: make([][sha256.Size]byte, depth+1), outputs: make(map[int][]byte)}
+	for d := range m.nodes {
+		m.nodes[d] = make(map[int][sha256.Size]byte)
+		if d > 0 {
+			m.empty[d] = merkleNode(m.empty[d-1], m.empty[d-1])
+		}
+	}
+	return m
+}
+
+// node returns the node at the height and position
+func (m *MerkleUTXOSet) node(d, k int) [sha256.Size]byte {
+	if h, ok := m.nodes[d][k]; ok {
+		return h
+	}
+	return m.empty[d]
+}
+
+// set sets the leaf of the slot and the nodes above it
+func (m *MerkleUTXOSet) set(slot int, leaf [sha256.Size]byte) {
+	for d, k := 0, slot; d < len(m.nodes); d, k = d+1, k>>1 {
+		if d > 0 {
+			leaf = merkleNode(m.node(d-1, k<<1), m.node(d-1, k<<1|1))
+		}
+		if leaf == m.empty[d] {
+			delete(m.nodes[d], k)
+		} else {
+			m.nodes[d][k] = leaf
+		}
+	}
+}
+
+// Root returns the root of the tree
+func (m *MerkleUTXOSet) Root() [sha256.Size]byte {
+	return m.node(len(m.nodes)-1, 0)
+}
+
+// Insert adds the output to the lowest free slot and returns the slot
+func (m *MerkleUTXOSet) Insert(output []byte) (int, error) {
+	slot, err := m.slots.take()
+	if err != nil {
+		return 0, err
+	}
+	output = append([]byte{}, output...)
+	m.set(slot, merkleLeaf(slot, utxoEntry(m.encoder, slot, output)))
+	m.outputs[slot] = output
+	return slot, nil
+}
+
+// Spend removes the output in the slot, freeing the slot
+func (m *MerkleUTXOSet) Spend(slot int) error {
+	if _, ok := m.outputs[slot]; !ok {
+		return fmt.Errorf("slot %d holds no unspent output", slot)
+	}
+	m.set(slot, [sha256.Size]byte{})
+	delete(m.outputs, slot)
+	m.slots.release(slot)
+	return nil
+}
+
+// ProveUnspent returns the outputs in the slots and the sibling paths of their leaves
+func (m *MerkleUTXOSet) ProveUnspent(slots []int) ([][]byte, [][]byte) {
+	outputs := make([][]byte, len(slots))
+	proofs := make([][]byte, len(slots))
+	for k, s := range slots {
+		output, ok := m.outputs[s]
+		if !ok {
+			panic(fmt.Sprintf("slot %d holds no unspent output", s))
+		}
+		outputs[k] = output
+		var proof bytes.Buffer
+		for d, i := 0, s; d < len(m.nodes)-1; d, i = d+1, i>>1 {
+			sibling := m.node(d, i^1)
+			proof.Write(sibling[:])
+		}
+		proofs[k] = proof.Bytes()
+	}
+	return outputs, proofs
+}
+
+// VerifyMerkleUnspent reports whether the paths show the outputs unspent in the slots of the tree
+func VerifyMerkleUnspent(root [sha256.Size]byte, application string, slots []int, outputs, proofs [][]byte) bool {
+	if len(slots) != len(outputs) || len(slots) != len(proofs) {
+		panic("arrays with incorrect length")
+	}
+	depth := 2 * bits.TrailingZeros(uint(n))
+	e := NewEntryEncoder(application)
+	for k, s := range slots {
+		if !(0 <= s && s < utxoSlots) {
+			panic("out of range index")
+		}
+		if len(proofs[k]) != depth*sha256.Size {
+			return false
+		}
+		node := merkleLeaf(s, utxoEntry(e, s, outputs[k]))
+		for d, i := 0, s; d < depth; d, i = d+1, i>>1 {
+			var sibling [sha256.Size]byte
+			copy(sibling[:], proofs[k][d*sha256.Size:])
+			if i&1 == 0 {
+				node = merkleNode(node, sibling)
+			} else {
+				node = merkleNode(sibling, node)
+			}
+		}
+		if node != root {
+			return false
+		}
+	}
+	return true
+}
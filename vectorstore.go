@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	A VectorStore keeps a vector, its commitment and the proofs served so far in a Store (see store.go),
+	a LevelDB database on disk through OpenVectorStore (not in the WebAssembly build, see
+	vectorstore_leveldb.go), any ethdb.KeyValueStore through NewKVStore or a SQL database through
+	NewSQLStore. Reopening a store loads the vector and the commitment instead of committing again, and
+	proofs stay cached across restarts. The commitment is stored as its artifact, which binds the store
+	to the parameters it was built with. As for Prover a change of m_j keeps only the proof of j valid,
+	so a cached proof counts if its version is the current one, and a change moves the proof of j to the
+	next version. Each change reaches the Store as one StoreChange, so the database holds a consistent
+	state after a crash at any point.
+*/
+
+// VectorStore is a vector persisted with its commitment and proofs, it is safe for concurrent use
+type VectorStore struct {
+	mu      sync.Mutex
+	store   Store
+	message []*big.Int
+	com     *bls.PointG1
+	version uint64
+}
+
+// NewVectorStore loads the vector kept in store, which the VectorStore owns from then on
+func NewVectorStore(store Store) (*VectorStore, error) {
+	state, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	s := &VectorStore{store: store, message: make([]*big.Int, n), version: state.Version}
+	for i := range s.message {
+		s.message[i] = new(big.Int)
+	}
+	if state.Commitment == nil {
+		s.com = engine.G1.Zero()
+	} else if s.com, err = decodeCommitment(state.Commitment); err != nil {
+		return nil, fmt.Errorf("commitment: %w", err)
+	}
+	for index, value := range state.Entries {
+		if !(0 <= index && index < n) || len(value) != scalarSize {
+			return nil, fmt.Errorf("malformed entry %d", index)
+		}
+		s.message[index].SetBytes(value)
+		if s.message[index].Cmp(frModulus) >= 0 {
+			return nil, fmt.Errorf("entry %d does not lie in the field", index)
+		}
+	}
+	return s, nil
+}
+
+// Close closes the store
+func (s *VectorStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.Close()
+}
+
+// Version returns the version of the vector, it grows by one with every change
+func (s *VectorStore) Version() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.version
+}
+
+// Get returns a copy of m_index
+func (s *VectorStore) Get(index int) *big.Int {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return new(big.Int).Set(s.message[index])
+}
+
+// Commitment returns a copy of the commitment to the vector
+func (s *VectorStore) Commitment() *bls.PointG1 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return new(bls.PointG1).Set(s.com)
+}
+
+// Set changes m_index to value, updating the commitment in place
+func (s *VectorStore) Set(index int, value *big.Int) error {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	if value.Sign() < 0 || value.Cmp(frModulus) != -1 {
+		panic("the message does not lie in the group")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if value.Cmp(s.message[index]) == 0 {
+		return nil
+	}
+	com := new(bls.PointG1).Set(s.com)
+	(*Commitment)(com).Update(index, s.message[index], value)
+	change := &StoreChange{Version: s.version + 1, Commitment: encodeCommitment(com), Index: index}
+	if value.Sign() != 0 {
+		change.Entry = value.FillBytes(make([]byte, scalarSize))
+	}
+	// the proof of the index does not cover m_index, it stays valid
+	proof, err := s.cachedProof(index)
+	if err != nil {
+		return err
+	}
+	if proof != nil {
+		change.Proof = encodeProof(proof)
+	}
+	if err := s.store.Apply(change); err != nil {
+		return err
+	}
+	s.message[index] = new(big.Int).Set(value)
+	s.com = com
+	s.version++
+	return nil
+}
+
+// Delete sets m_index to zero
+func (s *VectorStore) Delete(index int) error {
+	return s.Set(index, new(big.Int))
+}
+
+// Prove returns the proof of m_index, from the store if it was computed since the last change
+func (s *VectorStore) Prove(index int) (*bls.PointG1, error) {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	proof, err := s.cachedProof(index)
+	if err != nil {
+		return nil, err
+	}
+	observeProofCache("vector_store", proof != nil)
+	if proof != nil {
+		return proof, nil
+	}
+	proof = ProveSet(s.message, []int{index})[0]
+	if err := s.store.PutProof(index, s.version, encodeProof(proof)); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// cachedProof returns the proof of m_index stored for the current version, or nil if there is none
+func (s *VectorStore) cachedProof(index int) (*bls.PointG1, error) {
+	version, data, err := s.store.Proof(index)
+	if err != nil || data == nil || version != s.version {
+		return nil, err
+	}
+	proof, err := decodeProof(data)
+	if err != nil {
+		return nil, fmt.Errorf("proof of index %d: %w", index, err)
+	}
+	return proof, nil
+}
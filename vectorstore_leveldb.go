@@ -0,0 +1,19 @@
+//go:build !js
+
+package main
+
+import "github.com/ethereum/go-ethereum/ethdb/leveldb"
+
+// OpenVectorStore opens the store in the LevelDB database at path, creating an empty one if there is none
+func OpenVectorStore(path string) (*VectorStore, error) {
+	db, err := leveldb.New(path, 16, 16, "", false)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewVectorStore(NewKVStore(db))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
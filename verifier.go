@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math/big"
+	"sync"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	A Verifier checks single proofs like verifySingleProof, for services verifying many of them. It owns
+	its pairing engine and the points the pairings are computed on, so nothing of the verification is
+	allocated per call beyond what the backend does internally, and the points of the caller are never
+	normalized in place. Verifiers do not share state, one per goroutine verifies in parallel.
+*/
+
+// Verifier checks proofs against the installed parameters, it is safe for concurrent use
+type Verifier struct {
+	mu     sync.Mutex
+	engine *bls.Engine
+	// copies of the commitment and the negated proof of the current call
+	com, proof bls.PointG1
+	g1s        [2]*bls.PointG1
+	g2s        [2]*bls.PointG2
+}
+
+// NewVerifier returns a Verifier with its own pairing engine
+func NewVerifier() *Verifier {
+	v := &Verifier{engine: bls.NewPairingEngine()}
+	v.g1s = [2]*bls.PointG1{&v.com, &v.proof}
+	return v
+}
+
+// Verify reports whether proof opens com to entry at index, see verifySingleProof
+func (v *Verifier) Verify(com *bls.PointG1, entry *big.Int, proof *bls.PointG1, index int) bool {
+	if !(0 <= index && index < n) {
+		panic("out of range index")
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return profiled("verify", func() bool {
+		v.com.Set(com)
+		v.engine.G1.Neg(&v.proof, proof)
+		v.g2s = [2]*bls.PointG2{pp2[n-index-1], g2Generator}
+		// the copies are normalized here, so verifyPairingsWith has nothing left to copy
+		batchAffineG1(v.g1s[:])
+		return verifyPairingsWith(v.engine, v.g1s[:], v.g2s[:], frFromBig(entry))
+	})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Verifier keys. Checking proofs takes pp2, pp1[0] for the target g_T^{alpha^{n+1}} = e(pp1[0], pp2[n-1])
+	and the fingerprint the artifacts are bound to, about half the size of the parameters, so a client
+	that only verifies, e.g. in a browser, loads a VerifierKey instead. Once installed the verifiers and
+	the artifact decoders work as with the full parameters, while committing and proving panic for lack
+	of the other powers in G1. Verifier key files are
+		the file header of curve.go with magic "PPVK" || fingerprint || pp1[0] || pp2[0] || ... || pp2[n - 1]
+	with the points uncompressed. Keys of the swapped variant are not supported.
+*/
+
+const verifierKeyMagic = "PPVK"
+
+// VerifierKey is the part of the public parameters verifiers need
+type VerifierKey struct {
+	Fingerprint [fingerprintSize]byte
+	// G1 is PP1[0] = g1^alpha
+	G1  *bls.PointG1
+	PP2 [n]*bls.PointG2
+}
+
+// VerifierKey returns the verifier key of the parameters
+func (pp *PublicParams) VerifierKey() *VerifierKey {
+	if pp.Swapped {
+		panic("verifier keys of the swapped variant are not supported")
+	}
+	return &VerifierKey{Fingerprint: pp.fingerprint(), G1: pp.g1Power(0), PP2: pp.PP2}
+}
+
+// install makes the key the one used implicitly by the verifiers, replacing the parameters installed before
+func (vk *VerifierKey) install() {
+	pp := &PublicParams{PP2: vk.PP2}
+	pp.PP1[0] = vk.G1
+	pp.installAs(vk.Fingerprint)
+}
+
+// write serializes the key in the file format described above
+func (vk *VerifierKey) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := writeFileHeader(bw, verifierKeyMagic, 0); err != nil {
+		return err
+	}
+	if _, err := bw.Write(vk.Fingerprint[:]); err != nil {
+		return err
+	}
+	if err := writeG1(bw, vk.G1); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := writeG2(bw, vk.PP2[i]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readVerifierKey parses a key written by VerifierKey.write, it checks the points lie on the curve
+func readVerifierKey(r io.Reader) (*VerifierKey, error) {
+	br := bufio.NewReader(r)
+	if _, err := readFileHeader(br, verifierKeyMagic); err != nil {
+		return nil, err
+	}
+	vk := &VerifierKey{}
+	if _, err := io.ReadFull(br, vk.Fingerprint[:]); err != nil {
+		return nil, fmt.Errorf("fingerprint: %w", err)
+	}
+	var err error
+	if vk.G1, err = readG1(br); err != nil {
+		return nil, fmt.Errorf("pp1[0]: %w", err)
+	}
+	for i := 0; i < n; i++ {
+		if vk.PP2[i], err = readG2(br); err != nil {
+			return nil, fmt.Errorf("pp2[%d]: %w", i, err)
+		}
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, errors.New("trailing bytes after the verifier key")
+	}
+	return vk, nil
+}
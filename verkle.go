@@ -0,0 +1,188 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Verkle trees. A tree of depth D holds values under keys 0 <= key < n^D. Its nodes are commitments:
+	a node at the last level commits to n values, every other node to the hashes of the commitments of
+	its n children, 0 standing in for a missing child. The digits of the key in base n, most significant
+	first, pick the entry of every level. The proof of a key lists the commitments along its path below
+	the root and a single cross-commitment aggregation of the openings of every level, so it takes
+	D G1 points however large the key space is. A change of a value updates the commitments of its path
+	in place, one scalar multiplication per level.
+*/
+
+// verkleNodeDST separates the hashes of node commitments from other uses of hash_to_field
+const verkleNodeDST = "POINTPROOFS-V01-VERKLE-NODE_XMD:SHA-256"
+
+// verkleNode is a node of a VerkleTree, children is nil at the last level
+type verkleNode struct {
+	entries  []*big.Int
+	children []*verkleNode
+	com      *Commitment
+}
+
+func newVerkleNode(leaf bool) *verkleNode {
+	node := &verkleNode{entries: make([]*big.Int, n), com: (*Commitment)(bls.NewG1().Zero())}
+	for i := range node.entries {
+		node.entries[i] = new(big.Int)
+	}
+	if !leaf {
+		node.children = make([]*verkleNode, n)
+	}
+	return node
+}
+
+// verkleNodeHash maps the commitment of a node to the entry of its parent
+func verkleNodeHash(com *bls.PointG1) *big.Int {
+	b := toGnarkG1s([]*bls.PointG1{com})[0].Bytes()
+	return hashToField(b[:], []byte(verkleNodeDST), 1)[0]
+}
+
+// VerkleTree is a tree of commitments over a key space of size n^depth, it is not safe for concurrent use
+type VerkleTree struct {
+	depth int
+	root  *verkleNode
+}
+
+// NewVerkleTree returns the empty tree of the given depth
+func NewVerkleTree(depth int) *VerkleTree {
+	if depth < 1 {
+		panic("the depth must be positive")
+	}
+	return &VerkleTree{depth: depth, root: newVerkleNode(depth == 1)}
+}
+
+// path returns the entry indices of key at every level, most significant digit first
+func (t *VerkleTree) path(key *big.Int) []int {
+	size := new(big.Int).Exp(big.NewInt(n), big.NewInt(int64(t.depth)), nil)
+	if key.Sign() < 0 || key.Cmp(size) != -1 {
+		panic("the key does not lie in the key space")
+	}
+	digits := make([]int, t.depth)
+	k, digit := new(big.Int).Set(key), new(big.Int)
+	for l := t.depth - 1; l >= 0; l-- {
+		k.DivMod(k, big.NewInt(n), digit)
+		digits[l] = int(digit.Int64())
+	}
+	return digits
+}
+
+// Root returns the commitment of the root node, it changes with every Set
+func (t *VerkleTree) Root() *bls.PointG1 {
+	return new(bls.PointG1).Set(t.root.com.Point())
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the key
+		4. the new value, a field element
+	It stores the value under the key, creating the nodes of its path, and updates their commitments
+	from the last level up to the root.
+*/
+func (t *VerkleTree) Set(key, value *big.Int) {
+	digits := t.path(key)
+	nodes := make([]*verkleNode, t.depth)
+	nodes[0] = t.root
+	for l := 1; l < t.depth; l++ {
+		parent := nodes[l-1]
+		if parent.children[digits[l-1]] == nil {
+			parent.children[digits[l-1]] = newVerkleNode(l == t.depth-1)
+		}
+		nodes[l] = parent.children[digits[l-1]]
+	}
+	newVal := value
+	for l := t.depth - 1; l >= 0; l-- {
+		node, i := nodes[l], digits[l]
+		node.com.Update(i, node.entries[i], newVal)
+		node.entries[i] = new(big.Int).Set(newVal)
+		newVal = verkleNodeHash(node.com.Point())
+	}
+}
+
+// Get returns the value stored under the key, 0 if there is none
+func (t *VerkleTree) Get(key *big.Int) *big.Int {
+	node := t.root
+	for l, i := range t.path(key) {
+		if l == t.depth-1 {
+			return new(big.Int).Set(node.entries[i])
+		}
+		if node = node.children[i]; node == nil {
+			return new(big.Int)
+		}
+	}
+	panic("unreachable")
+}
+
+// VerklePathProof proves the value under a key of a VerkleTree
+type VerklePathProof struct {
+	// Commitments of the nodes along the path below the root, depth - 1 of them
+	Commitments []*bls.PointG1
+	// Proof aggregates the openings of all levels
+	Proof *bls.PointG1
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp1 (implicitly)
+		3. the key, whose path has to exist, i.e. Set was called for it or a key sharing the nodes
+	It returns the value under the key and the proof of it against Root. Every level costs one proof
+	of ProveSet, the aggregation is that of AggregateCrossCommitment.
+*/
+func (t *VerkleTree) Prove(key *big.Int) (*big.Int, *VerklePathProof) {
+	digits := t.path(key)
+	groups := make([]CommitmentOpenings, t.depth)
+	res := &VerklePathProof{}
+	node := t.root
+	for l, i := range digits {
+		if node == nil {
+			panic("the key does not lie in the tree")
+		}
+		com := node.com.Point()
+		if l > 0 {
+			res.Commitments = append(res.Commitments, new(bls.PointG1).Set(com))
+		}
+		proof := ProveSet(node.entries, []int{i})[0]
+		groups[l] = CommitmentOpenings{Commitment: com, Openings: []Opening{{Index: i, Value: node.entries[i], Proof: proof}}}
+		if node.children != nil {
+			node = node.children[i]
+		}
+	}
+	res.Proof = AggregateCrossCommitment(groups)
+	return new(big.Int).Set(groups[t.depth-1].Openings[0].Value), res
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the root commitment and the depth of the tree
+		4. the key and the value
+		5. the proof of VerkleTree.Prove
+	It reports whether the proof shows that the tree with the root holds the value under the key.
+*/
+func VerifyVerklePath(root *bls.PointG1, depth int, key, value *big.Int, proof *VerklePathProof) bool {
+	if len(proof.Commitments) != depth-1 {
+		return false
+	}
+	digits := (&VerkleTree{depth: depth}).path(key)
+	coms := append([]*bls.PointG1{root}, proof.Commitments...)
+	indices := make([][]int, depth)
+	values := make([][]*big.Int, depth)
+	for l := range coms {
+		indices[l] = []int{digits[l]}
+		if l < depth-1 {
+			values[l] = []*big.Int{verkleNodeHash(coms[l+1])}
+		} else {
+			values[l] = []*big.Int{value}
+		}
+	}
+	return VerifyCrossCommitment(coms, indices, values, proof.Proof)
+}
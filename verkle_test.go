@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestVerkleTree(t *testing.T) {
+	benchSetup(t)
+	const depth = 3
+	tree := NewVerkleTree(depth)
+	// two keys sharing the first two levels and one in another subtree
+	keys := []*big.Int{big.NewInt(5), big.NewInt(6), new(big.Int).Add(new(big.Int).Mul(big.NewInt(n*n), big.NewInt(3)), big.NewInt(9))}
+	for k, key := range keys {
+		tree.Set(key, big.NewInt(int64(100+k)))
+	}
+	tree.Set(keys[1], big.NewInt(7))
+	root := tree.Root()
+	for k, key := range keys {
+		want := big.NewInt(int64(100 + k))
+		if k == 1 {
+			want = big.NewInt(7)
+		}
+		if tree.Get(key).Cmp(want) != 0 {
+			t.Fatalf("key %v holds %v", key, tree.Get(key))
+		}
+		value, proof := tree.Prove(key)
+		if value.Cmp(want) != 0 {
+			t.Fatalf("proof of key %v names value %v", key, value)
+		}
+		if !VerifyVerklePath(root, depth, key, value, proof) {
+			t.Fatalf("path of key %v rejected", key)
+		}
+		if VerifyVerklePath(root, depth, key, new(big.Int).Add(value, big.NewInt(1)), proof) {
+			t.Fatalf("path of key %v accepted for a wrong value", key)
+		}
+	}
+	if tree.Get(big.NewInt(4)).Sign() != 0 {
+		t.Fatal("unset key holds a value")
+	}
+
+	value, proof := tree.Prove(keys[0])
+	if VerifyVerklePath(root, depth, keys[1], value, proof) {
+		t.Fatal("path accepted for another key")
+	}
+	tree.Set(keys[2], big.NewInt(1))
+	if VerifyVerklePath(tree.Root(), depth, keys[0], value, proof) {
+		t.Fatal("path accepted against the root after a change")
+	}
+	if VerifyVerklePath(root, depth, keys[0], value, &VerklePathProof{Commitments: proof.Commitments[:1], Proof: proof.Proof}) {
+		t.Fatal("path with a missing level accepted")
+	}
+}
@@ -0,0 +1,209 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"syscall/js"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	WebAssembly build, for browsers and Node:
+		GOOS=js GOARCH=wasm go build -o wasm/pointproofs.wasm .
+	Instead of the command line and the demo, main exposes the functions below on the global object
+	PointProofsWasm and waits for calls. wasm/pointproofs.js wraps them into a module that starts the
+	binary with the wasm_exec.js of the Go release it was built with and throws their errors, and
+	wasm/pointproofs.d.ts declares its types. Entries are decimal or 0x prefixed hexadecimal strings,
+	commitments and proofs the bytes of their artifacts (see artifacts.go), so they move between a Go
+	server and the module unchanged. Loading the parameters enables every function, loading a verifier
+	key (see verifierkey.go) only the verifications, which is all a client checking the proofs of a
+	server needs.
+		loadParams(bytes), loadVerifierKey(bytes)
+		commit(entries) -> commitment
+		open(entries, index) -> proof
+		verify(commitment, index, value, proof) -> bool
+		verifyAggregated(commitment, indices, values, proof) -> bool
+	Every function returns {result: ...} or {error: message}.
+*/
+
+const (
+	wasmProver   = "prover"
+	wasmVerifier = "verifier"
+)
+
+// wasmMode is what was loaded last, wasmProver, wasmVerifier or nothing
+var wasmMode string
+
+func init() {
+	platformMain = wasmMain
+}
+
+// wasmMain exposes the functions and never returns, so the module stays alive for the calls
+func wasmMain() {
+	js.Global().Set("PointProofsWasm", js.ValueOf(map[string]interface{}{
+		"loadParams":       wasmFunc(wasmLoadParams),
+		"loadVerifierKey":  wasmFunc(wasmLoadVerifierKey),
+		"commit":           wasmFunc(wasmCommit),
+		"open":             wasmFunc(wasmOpen),
+		"verify":           wasmFunc(wasmVerify),
+		"verifyAggregated": wasmFunc(wasmVerifyAggregated),
+	}))
+	select {}
+}
+
+// wasmFunc turns a handler into a JavaScript function. The parsers panic on malformed arguments,
+// which is the caller's fault and returned as the error like every other
+func wasmFunc(handle func(args []js.Value) interface{}) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) (res interface{}) {
+		defer func() {
+			if r := recover(); r != nil {
+				res = map[string]interface{}{"error": fmt.Sprint(r)}
+			}
+		}()
+		return map[string]interface{}{"result": handle(args)}
+	})
+}
+
+// wasmArgs panics unless there are count arguments
+func wasmArgs(args []js.Value, count int) {
+	if len(args) != count {
+		panic(fmt.Sprintf("expected %d arguments, got %d", count, len(args)))
+	}
+}
+
+// wasmRequire panics unless a mode enabling the call was loaded
+func wasmRequire(prover bool) {
+	switch {
+	case wasmMode == "":
+		panic("load the parameters or a verifier key first")
+	case prover && wasmMode != wasmProver:
+		panic("only verification is possible with a verifier key")
+	}
+}
+
+// wasmBytes returns the bytes of a Uint8Array
+func wasmBytes(v js.Value) []byte {
+	if !v.InstanceOf(js.Global().Get("Uint8Array")) {
+		panic("expected a Uint8Array")
+	}
+	b := make([]byte, v.Get("length").Int())
+	js.CopyBytesToGo(b, v)
+	return b
+}
+
+// wasmBytesValue returns b as a Uint8Array
+func wasmBytesValue(b []byte) js.Value {
+	v := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(v, b)
+	return v
+}
+
+// wasmStrings returns the strings of an array
+func wasmStrings(v js.Value) []string {
+	if !v.InstanceOf(js.Global().Get("Array")) {
+		panic("expected an array")
+	}
+	res := make([]string, v.Length())
+	for k := range res {
+		if v.Index(k).Type() != js.TypeString {
+			panic(fmt.Sprintf("entry %d is not a string", k))
+		}
+		res[k] = v.Index(k).String()
+	}
+	return res
+}
+
+// wasmInts returns the integers of an array
+func wasmInts(v js.Value) []int {
+	if !v.InstanceOf(js.Global().Get("Array")) {
+		panic("expected an array")
+	}
+	res := make([]int, v.Length())
+	for k := range res {
+		res[k] = wasmInt(v.Index(k))
+	}
+	return res
+}
+
+// wasmInt returns an integer
+func wasmInt(v js.Value) int {
+	if v.Type() != js.TypeNumber || float64(v.Int()) != v.Float() {
+		panic("expected an integer")
+	}
+	return v.Int()
+}
+
+// wasmPoint parses the bytes of an artifact with decode
+func wasmPoint(v js.Value, decode func([]byte) (*bls.PointG1, error)) *bls.PointG1 {
+	p, err := decode(wasmBytes(v))
+	if err != nil {
+		panic(err.Error())
+	}
+	return p
+}
+
+func wasmLoadParams(args []js.Value) interface{} {
+	wasmArgs(args, 1)
+	pp, err := readPublicParams(bytes.NewReader(wasmBytes(args[0])))
+	if err != nil {
+		panic(err.Error())
+	}
+	pp.install()
+	wasmMode = wasmProver
+	return nil
+}
+
+func wasmLoadVerifierKey(args []js.Value) interface{} {
+	wasmArgs(args, 1)
+	vk, err := readVerifierKey(bytes.NewReader(wasmBytes(args[0])))
+	if err != nil {
+		panic(err.Error())
+	}
+	vk.install()
+	wasmMode = wasmVerifier
+	return nil
+}
+
+func wasmCommit(args []js.Value) interface{} {
+	wasmArgs(args, 1)
+	wasmRequire(true)
+	return wasmBytesValue(encodeCommitment(commit(apiVector(wasmStrings(args[0])))))
+}
+
+func wasmOpen(args []js.Value) interface{} {
+	wasmArgs(args, 2)
+	wasmRequire(true)
+	vector := apiVector(wasmStrings(args[0]))
+	i := apiIndex(wasmInt(args[1]))
+	return wasmBytesValue(encodeProof(ProveSet(vector, []int{i})[0]))
+}
+
+func wasmVerify(args []js.Value) interface{} {
+	wasmArgs(args, 4)
+	wasmRequire(false)
+	com := wasmPoint(args[0], decodeCommitment)
+	i := apiIndex(wasmInt(args[1]))
+	value := apiEntry(args[2].String())
+	return verifySingleProof(com, value, wasmPoint(args[3], decodeProof), i)
+}
+
+func wasmVerifyAggregated(args []js.Value) interface{} {
+	wasmArgs(args, 4)
+	wasmRequire(false)
+	com := wasmPoint(args[0], decodeCommitment)
+	indices := wasmInts(args[1])
+	apiDistinct(indices)
+	entries := wasmStrings(args[2])
+	if len(entries) != len(indices) {
+		panic("indices and values differ in length")
+	}
+	values := make([]*big.Int, len(entries))
+	for k, e := range entries {
+		values[k] = apiEntry(e)
+	}
+	return VerifySameCommitment(com, indices, values, wasmPoint(args[3], decodeAggregatedProof))
+}
@@ -0,0 +1,24 @@
+// Types of pointproofs.js.
+
+/** An entry of a vector: a bigint, a safe integer or a decimal or 0x prefixed hexadecimal string. */
+export type Entry = bigint | number | string;
+
+export declare class PointProofs {
+  /** Installs the parameters, enabling every method. */
+  loadParams(params: Uint8Array): void;
+  /** Installs a verifier key, enabling verify and verifyAggregated only. */
+  loadVerifierKey(key: Uint8Array): void;
+  /** Returns the commitment artifact of the n entries. */
+  commit(entries: Entry[]): Uint8Array;
+  /** Returns the proof artifact of the entry at the index. */
+  open(entries: Entry[], index: number): Uint8Array;
+  /** Reports whether the proof opens the commitment to the value at the index. */
+  verify(commitment: Uint8Array, index: number, value: Entry, proof: Uint8Array): boolean;
+  /** Reports whether the aggregated proof opens the commitment to the values at the indices. */
+  verifyAggregated(commitment: Uint8Array, indices: number[], values: Entry[], proof: Uint8Array): boolean;
+}
+
+/** Starts the module from its bytes, a Response, a promise of one or a URL. */
+export declare function load(
+  source: BufferSource | Response | PromiseLike<Response> | string | URL,
+): Promise<PointProofs>;
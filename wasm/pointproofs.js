@@ -0,0 +1,96 @@
+// JavaScript binding of the WebAssembly build of PointProofs, see wasm.go for building it.
+//
+// The Go runtime needs the wasm_exec.js of the Go release the module was built with
+// ($(go env GOROOT)/lib/wasm, misc/wasm before Go 1.24), loaded before load() is called:
+// a <script> tag in browsers, import "./wasm_exec.js" in Node.
+//
+//	const pp = await load(fs.readFileSync("pointproofs.wasm"));
+//	pp.loadVerifierKey(fs.readFileSync("params.vk"));
+//	pp.verify(commitment, 17, "12345", proof); // true or false
+//
+// Entries are bigints, safe integers or decimal or 0x prefixed hexadecimal strings, commitments and
+// proofs the bytes of the artifacts Go writes. Every method throws on malformed input.
+
+// instantiate compiles the module from bytes, a Response, a promise of one or a URL
+async function instantiate(source, imports) {
+  if (typeof source === "string" || source instanceof URL) {
+    source = fetch(source);
+  }
+  if (source instanceof ArrayBuffer || ArrayBuffer.isView(source)) {
+    return WebAssembly.instantiate(source, imports);
+  }
+  return WebAssembly.instantiateStreaming(source, imports);
+}
+
+// entry converts an entry to the string the module parses
+function entry(x) {
+  if (typeof x === "bigint" || typeof x === "string") {
+    return x.toString();
+  }
+  if (Number.isSafeInteger(x)) {
+    return x.toString();
+  }
+  throw new TypeError(`invalid entry ${x}`);
+}
+
+// unwrap returns the result of a call into the module or throws its error
+function unwrap(res) {
+  if (res.error !== undefined) {
+    throw new Error(res.error);
+  }
+  return res.result;
+}
+
+export class PointProofs {
+  #raw;
+
+  constructor(raw) {
+    this.#raw = raw;
+  }
+
+  // loadParams installs the parameters, enabling every method
+  loadParams(params) {
+    unwrap(this.#raw.loadParams(params));
+  }
+
+  // loadVerifierKey installs a verifier key, enabling verify and verifyAggregated only
+  loadVerifierKey(key) {
+    unwrap(this.#raw.loadVerifierKey(key));
+  }
+
+  // commit returns the commitment to the n entries
+  commit(entries) {
+    return unwrap(this.#raw.commit(entries.map(entry)));
+  }
+
+  // open returns the proof of the entry at the index
+  open(entries, index) {
+    return unwrap(this.#raw.open(entries.map(entry), index));
+  }
+
+  // verify reports whether the proof opens the commitment to the value at the index
+  verify(commitment, index, value, proof) {
+    return unwrap(this.#raw.verify(commitment, index, entry(value), proof));
+  }
+
+  // verifyAggregated reports whether the proof aggregated by AggregateSameCommitment opens the
+  // commitment to the values at the indices
+  verifyAggregated(commitment, indices, values, proof) {
+    return unwrap(this.#raw.verifyAggregated(commitment, indices, values.map(entry), proof));
+  }
+}
+
+// load starts the module and returns its binding, a module serves one binding at a time
+export async function load(source) {
+  if (typeof globalThis.Go !== "function") {
+    throw new Error("load the wasm_exec.js of the Go release first");
+  }
+  const go = new globalThis.Go();
+  const { instance } = await instantiate(source, go.importObject);
+  // the program never exits, it blocks once it has exposed its functions
+  go.run(instance);
+  if (globalThis.PointProofsWasm === undefined) {
+    throw new Error("the module did not start");
+  }
+  return new PointProofs(globalThis.PointProofsWasm);
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math/big"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	Weighted aggregation, for protocols that need their own coefficients w_i in the aggregated proof.
+	Handing them to aggregateProof directly is unsafe: a prover who picks the w_i can make the errors of
+	wrong openings cancel out. Here the proof is aggregated with
+		s_i = w_i * t_i
+	where the t_i are the challenges "t" of a transcript of domain weightedDomain that absorbed
+		"C": C, "|S|": |S|, then "i": i, "m_i": m_i, "w_i": w_i for i in S in increasing order of i
+	so every w_i is fixed before the t_i are known and the verifier, who derives the t_i from the weights
+	it was given, rejects a proof aggregated with any other weights. The weights have to lie in the field
+	and be non-zero, a zero weight would drop its opening from the proof.
+*/
+
+// checkWeights panics unless there is a weight per opened index and every weight lies in [1, q)
+func checkWeights(indices []int, weights []*big.Int) {
+	if len(weights) != len(indices) {
+		panic("arrays with incorrect length")
+	}
+	for _, w := range weights {
+		if w == nil || w.Sign() <= 0 || w.Cmp(frModulus) != -1 {
+			panic("weight is not a non-zero field element")
+		}
+	}
+}
+
+// weightedScalars derives the scalars s_i of the openings of com with the weights, res[k] belonging to
+// indices[k]. The openings have to be valid input of sameCommitmentScalars
+func weightedScalars(com *bls.PointG1, indices []int, values []*big.Int, weights []*big.Int) []*big.Int {
+	checkOpened(indices, values)
+	checkWeights(indices, weights)
+	t := NewTranscript(weightedDomain)
+	t.AppendG1("C", com)
+	t.AppendUint32("|S|", uint32(len(indices)))
+	order := indexOrder(indices)
+	for _, k := range order {
+		t.AppendUint32("i", uint32(indices[k]))
+		t.AppendScalar("m_i", values[k])
+		t.AppendScalar("w_i", weights[k])
+	}
+	scalars := challengesInOrder(t, "t", order)
+	var s fr
+	for k := range scalars {
+		s.mul(frFromBig(scalars[k]), frFromBig(weights[k]))
+		scalars[k] = s.big()
+	}
+	return scalars
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. the commitment
+		3. the openings of it, with distinct indices
+		4. the weights of the openings, non-zero field elements
+	It returns the aggregation of the proofs with the weights bound to the derived scalars, which
+	VerifyWeighted checks with the same weights.
+*/
+func AggregateWeighted(com *bls.PointG1, openings []Opening, weights []*big.Int) *bls.PointG1 {
+	indices := make([]int, len(openings))
+	values := make([]*big.Int, len(openings))
+	proofs := make([]*bls.PointG1, len(openings))
+	for k, o := range openings {
+		indices[k], values[k], proofs[k] = o.Index, o.Value, o.Proof
+	}
+	return aggregateProof(proofs, weightedScalars(com, indices, values, weights), len(openings))
+}
+
+/*
+	It takes the following arguments:
+		1. bls.Engine (implicitly)
+		2. pp2 (implicitly)
+		3. the commitment
+		4. the opened indices, distinct and in any order
+		5. the values at these indices
+		6. the weights the proof was aggregated with
+		7. the aggregated proof of AggregateWeighted
+	It reports whether the proof opens the commitment to the values at the indices under these weights.
+*/
+func VerifyWeighted(com *bls.PointG1, indices []int, values []*big.Int, weights []*big.Int, proof *bls.PointG1) bool {
+	scalars := weightedScalars(com, indices, values, weights)
+	return verifySameCommitmentAggregation(com, proof, values, scalars, indices, len(indices))
+}
@@ -0,0 +1,142 @@
+//go:build gnark
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/std/algebra/emulated/sw_bls12381"
+
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+/*
+	SNARK wrapping, compiled in with the gnark build tag like circuit.go. A batch of openings at fixed
+	indices is verified by a circuit over BN254, one OpeningGadget per opening, and a Groth16 proof of it
+	replaces the batch: three G1/G2 points checked with a single call to the pairing precompile of
+	Ethereum, whatever the batch size. The commitments and entries stay public inputs. Every opening
+	costs the circuit three emulated pairings, millions of constraints, so compiling and setting up a
+	Wrapper is a one-off job for a machine with plenty of memory, and proving takes a while too.
+	Groth16 needs a trusted setup per circuit, the one of Setup is for testing only.
+*/
+
+// WrapCircuit verifies openings at the indices of its gadgets
+type WrapCircuit struct {
+	Commitments []sw_bls12381.G1Affine `gnark:",public"`
+	Values      []sw_bls12381.Scalar   `gnark:",public"`
+	Proofs      []sw_bls12381.G1Affine
+	Gadgets     []*OpeningGadget `gnark:"-"`
+}
+
+// Define implements frontend.Circuit
+func (c *WrapCircuit) Define(api frontend.API) error {
+	for k, g := range c.Gadgets {
+		if err := g.AssertOpening(api, &c.Commitments[k], &c.Values[k], &c.Proofs[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newWrapCircuit returns the circuit of the indices with room for the variables
+func newWrapCircuit(indices []int) *WrapCircuit {
+	c := &WrapCircuit{
+		Commitments: make([]sw_bls12381.G1Affine, len(indices)),
+		Values:      make([]sw_bls12381.Scalar, len(indices)),
+		Proofs:      make([]sw_bls12381.G1Affine, len(indices)),
+	}
+	for _, i := range indices {
+		c.Gadgets = append(c.Gadgets, NewOpeningGadget(i))
+	}
+	return c
+}
+
+// Wrapper compresses batches of openings at fixed indices into Groth16 proofs
+type Wrapper struct {
+	indices []int
+	cs      constraint.ConstraintSystem
+	pk      groth16.ProvingKey
+	vk      groth16.VerifyingKey
+}
+
+// NewWrapper compiles the circuit for openings at the indices, index k of a batch being opened at
+// indices[k], and runs a Groth16 setup for it
+func NewWrapper(indices []int) (*Wrapper, error) {
+	if len(indices) == 0 {
+		return nil, errors.New("no indices")
+	}
+	cs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, newWrapCircuit(indices))
+	if err != nil {
+		return nil, err
+	}
+	pk, vk, err := groth16.Setup(cs)
+	if err != nil {
+		return nil, err
+	}
+	return &Wrapper{indices: append([]int{}, indices...), cs: cs, pk: pk, vk: vk}, nil
+}
+
+// Constraints returns the size of the circuit
+func (w *Wrapper) Constraints() int {
+	return w.cs.GetNbConstraints()
+}
+
+// assignment returns the witness of the batch, which has to match the indices of the wrapper
+func (w *Wrapper) assignment(coms []*bls.PointG1, values []fr, proofs []*bls.PointG1) (*WrapCircuit, error) {
+	if len(coms) != len(w.indices) || len(values) != len(w.indices) || len(proofs) != len(w.indices) {
+		return nil, fmt.Errorf("the wrapper takes batches of %d openings", len(w.indices))
+	}
+	c := newWrapCircuit(nil)
+	for k := range w.indices {
+		a := NewOpeningAssignment(coms[k], values[k], proofs[k])
+		c.Commitments = append(c.Commitments, a.Commitment)
+		c.Values = append(c.Values, a.Value)
+		c.Proofs = append(c.Proofs, a.Proof)
+	}
+	return c, nil
+}
+
+/*
+	It takes the following arguments:
+		1. the commitments of the batch
+		2. the entries, m_k at index indices[k] of commitment k
+		3. the proofs of the entries
+	It returns the Groth16 proof that all openings verify, it fails if one does not.
+*/
+func (w *Wrapper) Prove(coms []*bls.PointG1, values []fr, proofs []*bls.PointG1) (groth16.Proof, error) {
+	a, err := w.assignment(coms, values, proofs)
+	if err != nil {
+		return nil, err
+	}
+	witness, err := frontend.NewWitness(a, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, err
+	}
+	return groth16.Prove(w.cs, w.pk, witness)
+}
+
+// Verify checks the Groth16 proof of Prove for the commitments and entries of the batch
+func (w *Wrapper) Verify(proof groth16.Proof, coms []*bls.PointG1, values []fr) error {
+	// the proofs are no public input, any points fill their place
+	a, err := w.assignment(coms, values, coms)
+	if err != nil {
+		return err
+	}
+	witness, err := frontend.NewWitness(a, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		return err
+	}
+	return groth16.Verify(proof, w.vk, witness)
+}
+
+// ExportSolidity writes the Solidity contract verifying the Groth16 proofs of the wrapper
+func (w *Wrapper) ExportSolidity(out io.Writer) error {
+	return w.vk.ExportSolidity(out)
+}
@@ -0,0 +1,44 @@
+//go:build gnark
+
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/test"
+	bls "github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// TestWrapCircuit runs the circuit of a Wrapper on the test engine of gnark, which checks the
+// constraints without the Groth16 setup
+func TestWrapCircuit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("emulated pairings take a while")
+	}
+	f := benchSetup(t)
+	indices := []int{f.indices[1], f.indices[4]}
+	w := &Wrapper{indices: indices}
+	coms := []*bls.PointG1{f.com, f.com}
+	values := frVector([]*big.Int{f.values[1], f.values[4]})
+	proofs := []*bls.PointG1{f.proofs[1], f.proofs[4]}
+	valid, err := w.assignment(coms, values, proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := test.IsSolved(newWrapCircuit(indices), valid, ecc.BN254.ScalarField()); err != nil {
+		t.Fatalf("valid openings rejected: %v", err)
+	}
+	values[1] = frFromBig(new(big.Int).Add(f.values[4], big.NewInt(1)))
+	wrong, err := w.assignment(coms, values, proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if test.IsSolved(newWrapCircuit(indices), wrong, ecc.BN254.ScalarField()) == nil {
+		t.Fatal("opening of a wrong value accepted")
+	}
+	if _, err := w.assignment(coms[:1], values[:1], proofs[:1]); err == nil {
+		t.Fatal("batch of the wrong size accepted")
+	}
+}